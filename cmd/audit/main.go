@@ -0,0 +1,126 @@
+// Command audit walks the range directories written for a chain and checks them against the node: it reports the
+// heights each entity is missing at, transactions and logs files whose row counts differ from the block's, and traces
+// files left empty where the block has traces. Only the files are read; nothing is written to the store.
+//
+// The node, storage and driver are configured through the same environment variables as the ETL (BLOCKCHAIN,
+// NODE_HOST, STORAGE_BACKEND, BUCKET_DIRECTORY_RANGE, ...), e.g.
+//
+//	BLOCKCHAIN=ethereum NODE_HOST=https://... audit -from 17000000 -to 17010000 -reingest reingest.txt > report.json
+//
+// The report is printed as JSON, and the heights to ingest again are written one per line to the -reingest file.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/drivers/arbitrum"
+	"github.com/coherentopensource/evm-etl/drivers/base"
+	"github.com/coherentopensource/evm-etl/drivers/binance"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/coherentopensource/evm-etl/drivers/generic"
+	"github.com/coherentopensource/evm-etl/drivers/linea"
+	"github.com/coherentopensource/evm-etl/drivers/optimism"
+	"github.com/coherentopensource/evm-etl/drivers/polygon"
+	"github.com/coherentopensource/evm-etl/drivers/scroll"
+	"github.com/coherentopensource/evm-etl/drivers/zksync"
+	"github.com/coherentopensource/evm-etl/shared/audit"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/go-service-framework/constants"
+	"github.com/coherentopensource/go-service-framework/manager"
+	"github.com/coherentopensource/go-service-framework/util"
+	"os"
+	"strings"
+)
+
+// driver is the part of a chain's driver the command uses
+type driver interface {
+	Expect(ctx context.Context, height uint64) (*audit.Expectation, error)
+	WrittenEntities() []string
+}
+
+func main() {
+	from := flag.Uint64("from", 0, "first block height to audit")
+	to := flag.Uint64("to", 0, "last block height to audit (inclusive)")
+	concurrency := flag.Int("concurrency", 8, "number of blocks fetched from the node at once")
+	reingest := flag.String("reingest", "", "file to write the heights to ingest again to, one per line")
+	descriptorPath := flag.String("descriptor", "", "chain descriptor of a chain indexed by the generic EVM driver")
+	flag.Parse()
+
+	mgr := manager.New()
+	logger := mgr.Logger()
+	ctx := mgr.Context()
+
+	if *to < *from {
+		logger.Fatalf("invalid range: %d-%d", *from, *to)
+	}
+
+	nodeCfg := node.MustParseConfig(logger)
+	store := storage.MustNewStore(ctx, logger)
+	d := newDriver(nodeCfg.Blockchain, *descriptorPath, node.MustNewClient(nodeCfg, logger), store, logger)
+
+	cfg := audit.Config{
+		DirectoryRange: evm.MustParseConfig(logger, "audit").DirectoryRange,
+		Concurrency:    *concurrency,
+	}
+	report, err := audit.New(store, d, cfg, logger).Audit(ctx, *from, *to)
+	if err != nil {
+		logger.Fatalf("could not audit: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.Fatalf("%v", err)
+	}
+	if *reingest != "" {
+		var lines strings.Builder
+		for _, height := range report.Reingest {
+			fmt.Fprintf(&lines, "%d\n", height)
+		}
+		if err := os.WriteFile(*reingest, []byte(lines.String()), 0o644); err != nil {
+			logger.Fatalf("could not write %s: %v", *reingest, err)
+		}
+	}
+	logger.Infof("found %d issues across %d blocks to ingest again", len(report.Issues), len(report.Reingest))
+}
+
+// newDriver constructs the configured chain's driver, or the generic EVM driver for a chain descriptor
+func newDriver(blockchain constants.Blockchain, descriptorPath string, nodeClient node.Client, store storage.Store, logger util.Logger) driver {
+	if descriptorPath != "" {
+		cfg := &generic.Config{Config: *evm.MustParseConfig(logger, "generic EVM"), DescriptorPath: descriptorPath}
+		return generic.New(cfg, nodeClient, store, logger)
+	}
+
+	switch blockchain {
+	case arbitrum.ArbitrumOne, arbitrum.ArbitrumNova:
+		return arbitrum.New(arbitrum.MustParseConfig(logger), nodeClient, store, logger)
+	case constants.Base:
+		return base.New(base.MustParseConfig(logger), nodeClient, store, logger)
+	case constants.Binance_Smart_Chain:
+		return binance.New(binance.MustParseConfig(logger), nodeClient, store, logger)
+	case constants.Ethereum:
+		cfg := ethereum.MustParseConfig(logger)
+		if beaconCfg := beacon.MustParseConfig(logger); beaconCfg.Host != "" {
+			return ethereum.NewWithBeacon(cfg, nodeClient, beacon.NewClient(beaconCfg, beacon.NewHTTPTransport(beaconCfg)), store, logger)
+		}
+		return ethereum.New(cfg, nodeClient, store, logger)
+	case constants.Optimism:
+		return optimism.New(optimism.MustParseConfig(logger), nodeClient, store, logger)
+	case constants.Polygon:
+		return polygon.New(polygon.MustParseConfig(logger), nodeClient, store, logger)
+	case linea.Linea:
+		return linea.New(linea.MustParseConfig(logger), nodeClient, store, logger)
+	case scroll.Scroll:
+		return scroll.New(scroll.MustParseConfig(logger), nodeClient, store, logger)
+	case zksync.ZkSyncEra:
+		return zksync.New(zksync.MustParseConfig(logger), nodeClient, store, logger)
+	default:
+		logger.Fatalf("unsupported chain %q; use -descriptor for chains indexed by the generic EVM driver", blockchain)
+		return nil
+	}
+}
@@ -0,0 +1,136 @@
+// Command backfill writes again, from the node, the heights the checkpoints show were not fully written, e.g. after a
+// crash left a block with its blocks file but no logs. Only the entities a height lacks are written.
+//
+// The node, storage and driver are configured through the same environment variables as the ETL (BLOCKCHAIN,
+// NODE_HOST, STORAGE_BACKEND, CHECKPOINT_BACKEND, ...), e.g.
+//
+//	BLOCKCHAIN=ethereum NODE_HOST=https://... CHECKPOINT_BACKEND=manifest backfill -from 17000000 -to 17010000
+//
+// With -list, the gaps are printed as JSON lines rather than backfilled.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/drivers/arbitrum"
+	"github.com/coherentopensource/evm-etl/drivers/base"
+	"github.com/coherentopensource/evm-etl/drivers/binance"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/coherentopensource/evm-etl/drivers/generic"
+	"github.com/coherentopensource/evm-etl/drivers/linea"
+	"github.com/coherentopensource/evm-etl/drivers/optimism"
+	"github.com/coherentopensource/evm-etl/drivers/polygon"
+	"github.com/coherentopensource/evm-etl/drivers/scroll"
+	"github.com/coherentopensource/evm-etl/drivers/zksync"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/coherentopensource/evm-etl/shared/checkpoint"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/go-service-framework/constants"
+	"github.com/coherentopensource/go-service-framework/manager"
+	"github.com/coherentopensource/go-service-framework/util"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+)
+
+// driver is the part of a chain's driver the command uses
+type driver interface {
+	Gaps(ctx context.Context, from uint64, to uint64) ([]checkpoint.Gap, error)
+	Backfill(ctx context.Context, gap checkpoint.Gap) error
+	Flush(ctx context.Context) error
+}
+
+func main() {
+	from := flag.Uint64("from", 0, "first block height to check")
+	to := flag.Uint64("to", 0, "last block height to check (inclusive)")
+	list := flag.Bool("list", false, "print the gaps as JSON lines instead of backfilling them")
+	descriptorPath := flag.String("descriptor", "", "chain descriptor of a chain indexed by the generic EVM driver")
+	flag.Parse()
+
+	mgr := manager.New()
+	logger := mgr.Logger()
+	ctx := mgr.Context()
+
+	if *to < *from {
+		logger.Fatalf("invalid range: %d-%d", *from, *to)
+	}
+
+	nodeCfg := node.MustParseConfig(logger)
+	d := newDriver(nodeCfg.Blockchain, *descriptorPath, node.MustNewClient(nodeCfg, logger), storage.MustNewStore(ctx, logger), logger)
+
+	if err := run(ctx, d, *from, *to, *list, os.Stdout, logger); err != nil {
+		logger.Fatalf("%v", err)
+	}
+}
+
+// run backfills the gaps in heights from..to, or with list prints them to out as JSON lines instead
+func run(ctx context.Context, d driver, from uint64, to uint64, list bool, out io.Writer, logger util.Logger) error {
+	gaps, err := d.Gaps(ctx, from, to)
+	if err != nil {
+		return errors.Wrap(err, "could not list gaps")
+	}
+	if list {
+		encoder := json.NewEncoder(out)
+		for _, gap := range gaps {
+			if err := encoder.Encode(gap); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, gap := range gaps {
+		logger.Infof("backfilling %v for blocks %d to %d", gap.Missing, gap.From, gap.To)
+		if err = d.Backfill(ctx, gap); err != nil {
+			break
+		}
+	}
+	//	Whatever was backfilled before a failure is still flushed, so that it need not be fetched again
+	if flushErr := d.Flush(ctx); flushErr != nil {
+		return errors.Wrap(flushErr, "could not flush")
+	}
+	if err != nil {
+		return err
+	}
+	logger.Infof("backfilled %d gaps", len(gaps))
+	return nil
+}
+
+// newDriver constructs the configured chain's driver, or the generic EVM driver for a chain descriptor
+func newDriver(blockchain constants.Blockchain, descriptorPath string, nodeClient node.Client, store storage.Store, logger util.Logger) driver {
+	if descriptorPath != "" {
+		cfg := &generic.Config{Config: *evm.MustParseConfig(logger, "generic EVM"), DescriptorPath: descriptorPath}
+		return generic.New(cfg, nodeClient, store, logger)
+	}
+
+	switch blockchain {
+	case arbitrum.ArbitrumOne, arbitrum.ArbitrumNova:
+		return arbitrum.New(arbitrum.MustParseConfig(logger), nodeClient, store, logger)
+	case constants.Base:
+		return base.New(base.MustParseConfig(logger), nodeClient, store, logger)
+	case constants.Binance_Smart_Chain:
+		return binance.New(binance.MustParseConfig(logger), nodeClient, store, logger)
+	case constants.Ethereum:
+		cfg := ethereum.MustParseConfig(logger)
+		if beaconCfg := beacon.MustParseConfig(logger); beaconCfg.Host != "" {
+			return ethereum.NewWithBeacon(cfg, nodeClient, beacon.NewClient(beaconCfg, beacon.NewHTTPTransport(beaconCfg)), store, logger)
+		}
+		return ethereum.New(cfg, nodeClient, store, logger)
+	case constants.Optimism:
+		return optimism.New(optimism.MustParseConfig(logger), nodeClient, store, logger)
+	case constants.Polygon:
+		return polygon.New(polygon.MustParseConfig(logger), nodeClient, store, logger)
+	case linea.Linea:
+		return linea.New(linea.MustParseConfig(logger), nodeClient, store, logger)
+	case scroll.Scroll:
+		return scroll.New(scroll.MustParseConfig(logger), nodeClient, store, logger)
+	case zksync.ZkSyncEra:
+		return zksync.New(zksync.MustParseConfig(logger), nodeClient, store, logger)
+	default:
+		logger.Fatalf("unsupported chain %q; use -descriptor for chains indexed by the generic EVM driver", blockchain)
+		return nil
+	}
+}
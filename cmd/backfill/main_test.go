@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/checkpoint"
+	"github.com/pkg/errors"
+	"reflect"
+	"testing"
+)
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+// fakeDriver reports fixed gaps and records what it is asked to backfill, failing at failAt if set
+type fakeDriver struct {
+	gaps       []checkpoint.Gap
+	failAt     uint64
+	backfilled []uint64
+	flushed    bool
+}
+
+func (f *fakeDriver) Gaps(ctx context.Context, from uint64, to uint64) ([]checkpoint.Gap, error) {
+	return f.gaps, nil
+}
+
+func (f *fakeDriver) Backfill(ctx context.Context, gap checkpoint.Gap) error {
+	if gap.From == f.failAt {
+		return errors.Errorf("cannot fetch block %d", gap.From)
+	}
+	f.backfilled = append(f.backfilled, gap.From)
+	return nil
+}
+
+func (f *fakeDriver) Flush(ctx context.Context) error {
+	f.flushed = true
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	gaps := []checkpoint.Gap{
+		{From: 3, To: 4, Missing: []string{"logs"}},
+		{From: 7, To: 7, Missing: []string{"blocks", "logs"}},
+		{From: 9, To: 9, Missing: []string{"logs"}},
+	}
+
+	tests := []struct {
+		name           string
+		list           bool
+		failAt         uint64
+		wantErr        bool
+		wantBackfilled []uint64
+		wantFlushed    bool
+		wantOut        string
+	}{
+		{
+			name:           "backfill",
+			wantBackfilled: []uint64{3, 7, 9},
+			wantFlushed:    true,
+		},
+		{
+			//	What was backfilled before the failure is flushed all the same
+			name:           "failure",
+			failAt:         7,
+			wantErr:        true,
+			wantBackfilled: []uint64{3},
+			wantFlushed:    true,
+		},
+		{
+			name: "list",
+			list: true,
+			wantOut: `{"from":3,"to":4,"missing":["logs"]}
+{"from":7,"to":7,"missing":["blocks","logs"]}
+{"from":9,"to":9,"missing":["logs"]}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &fakeDriver{gaps: gaps, failAt: tt.failAt}
+			var out bytes.Buffer
+			err := run(context.Background(), d, 0, 10, tt.list, &out, testLogger{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(d.backfilled, tt.wantBackfilled) {
+				t.Errorf("backfilled = %v, want %v", d.backfilled, tt.wantBackfilled)
+			}
+			if d.flushed != tt.wantFlushed {
+				t.Errorf("flushed = %v, want %v", d.flushed, tt.wantFlushed)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("out = %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
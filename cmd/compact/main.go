@@ -0,0 +1,123 @@
+// Command compact merges the per-block (or per-batch) parquet files of completed range directories into a few large,
+// height-sorted files per range.
+//
+// Storage is configured through the same environment variables as the ETL (STORAGE_BACKEND, GCS_BUCKET_NAME, ...).
+// Ranges ending at or above -before are left alone, so the command can run next to a live writer, e.g.
+//
+//	STORAGE_BACKEND=gcs GCS_BUCKET_NAME=... compact -chain ethereum -from 0 -before 17000000 -archive archive
+//
+// Trace files written before trace_address must first be rewritten by migrate-trace-ids; a range still holding one is
+// refused.
+package main
+
+import (
+	"flag"
+	"github.com/coherentopensource/evm-etl/drivers/arbitrum"
+	"github.com/coherentopensource/evm-etl/drivers/base"
+	"github.com/coherentopensource/evm-etl/drivers/binance"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/generic"
+	"github.com/coherentopensource/evm-etl/drivers/linea"
+	"github.com/coherentopensource/evm-etl/drivers/optimism"
+	"github.com/coherentopensource/evm-etl/drivers/polygon"
+	"github.com/coherentopensource/evm-etl/drivers/scroll"
+	"github.com/coherentopensource/evm-etl/drivers/zksync"
+	"github.com/coherentopensource/evm-etl/shared/compaction"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/constants"
+	"github.com/coherentopensource/go-service-framework/manager"
+	"sort"
+	"strings"
+	"time"
+)
+
+// entities lists, per schema version and chain, the entity directories that can be compacted and the models they were
+// written with
+var entities = map[int]map[constants.Blockchain]map[string]interface{}{
+	util.SchemaVersionHex: {
+		arbitrum.ArbitrumOne:          arbitrum.Entities,
+		arbitrum.ArbitrumNova:         arbitrum.Entities,
+		constants.Base:                base.Entities,
+		constants.Binance_Smart_Chain: binance.Entities,
+		constants.Ethereum:            ethereum.Entities,
+		constants.Optimism:            optimism.Entities,
+		constants.Polygon:             polygon.Entities,
+		linea.Linea:                   linea.Entities,
+		scroll.Scroll:                 scroll.Entities,
+		zksync.ZkSyncEra:              zksync.Entities,
+	},
+	util.SchemaVersionTyped: {
+		arbitrum.ArbitrumOne:          arbitrum.EntitiesV2,
+		arbitrum.ArbitrumNova:         arbitrum.EntitiesV2,
+		constants.Base:                base.EntitiesV2,
+		constants.Binance_Smart_Chain: binance.EntitiesV2,
+		constants.Ethereum:            ethereum.EntitiesV2,
+		constants.Optimism:            optimism.EntitiesV2,
+		constants.Polygon:             polygon.EntitiesV2,
+		linea.Linea:                   linea.EntitiesV2,
+		scroll.Scroll:                 scroll.EntitiesV2,
+		zksync.ZkSyncEra:              zksync.EntitiesV2,
+	},
+}
+
+func main() {
+	chain := flag.String("chain", "", "blockchain whose output is being compacted, e.g. ethereum")
+	only := flag.String("entities", "", "comma-separated entities to compact; defaults to all of the chain's entities")
+	from := flag.Uint64("from", 0, "first block height to compact")
+	before := flag.Uint64("before", 0, "height a live writer may still be working on; only ranges ending below it are compacted")
+	directoryRange := flag.Uint64("range", 10000, "range directory size the files were written with")
+	targetRows := flag.Int64("target-rows", 0, "split each range into files of about this many rows; 0 writes one file per range")
+	minAge := flag.Duration("min-age", time.Hour, "skip ranges holding files modified more recently than this")
+	schemaVersion := flag.Int("schema-version", util.SchemaVersionHex, "parquet schema version the files were written with")
+	archive := flag.String("archive", "", "directory to copy originals beneath before deleting them; empty deletes them outright")
+	descriptorPath := flag.String("descriptor", "", "chain descriptor of a chain indexed by the generic EVM driver, in place of -chain")
+	flag.Parse()
+
+	mgr := manager.New()
+	logger := mgr.Logger()
+	ctx := mgr.Context()
+
+	models, ok := entities[*schemaVersion][constants.Blockchain(*chain)]
+	if *descriptorPath != "" {
+		descriptor, err := generic.LoadDescriptor(*descriptorPath)
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+		*chain = descriptor.Name
+		models = generic.Models(descriptor, *schemaVersion)
+		ok = *schemaVersion == util.SchemaVersionHex || *schemaVersion == util.SchemaVersionTyped
+	}
+	if !ok {
+		logger.Fatalf("unsupported chain or schema version: %q, %d", *chain, *schemaVersion)
+	}
+	if *before == 0 {
+		logger.Fatalf("-before is required")
+	}
+
+	names := strings.Split(*only, ",")
+	if *only == "" {
+		names = nil
+		for name := range models {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	compactor := compaction.New(storage.MustNewStore(ctx, logger), compaction.Config{
+		DirectoryRange: *directoryRange,
+		Before:         *before,
+		TargetRows:     *targetRows,
+		MinAge:         *minAge,
+		ArchivePrefix:  *archive,
+	}, logger)
+	for _, name := range names {
+		model, ok := models[name]
+		if !ok {
+			logger.Fatalf("unknown entity for %s: %q", *chain, name)
+		}
+		if err := compactor.Compact(ctx, name, model, *from); err != nil {
+			logger.Fatalf("%v", err)
+		}
+	}
+}
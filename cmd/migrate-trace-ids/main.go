@@ -0,0 +1,116 @@
+// Command migrate-trace-ids rewrites existing trace files so that trace_hash and parent_hash hold the position-derived
+// IDs of trace.ID rather than hashes of the call's content. Files written before trace_address existed also gain
+// trace_address, depth and subtraces, rebuilt from their old hash linkage.
+//
+// Storage is configured through the same environment variables as the ETL (STORAGE_BACKEND, GCS_BUCKET_NAME, ...),
+// e.g.
+//
+//	STORAGE_BACKEND=gcs GCS_BUCKET_NAME=... migrate-trace-ids -chain polygon -from 0 -to 42000000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/arbitrum"
+	"github.com/coherentopensource/evm-etl/drivers/base"
+	"github.com/coherentopensource/evm-etl/drivers/binance"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/generic"
+	"github.com/coherentopensource/evm-etl/drivers/linea"
+	"github.com/coherentopensource/evm-etl/drivers/optimism"
+	"github.com/coherentopensource/evm-etl/drivers/polygon"
+	"github.com/coherentopensource/evm-etl/drivers/scroll"
+	"github.com/coherentopensource/evm-etl/drivers/zksync"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/constants"
+	"github.com/coherentopensource/go-service-framework/manager"
+)
+
+const (
+	traceEntity = "traces"
+)
+
+// models lists, per schema version and chain, the model trace files were written with
+var models = map[int]map[constants.Blockchain]interface{}{
+	util.SchemaVersionHex: {
+		arbitrum.ArbitrumOne:          arbitrum.Entities[traceEntity],
+		arbitrum.ArbitrumNova:         arbitrum.Entities[traceEntity],
+		constants.Base:                base.Entities[traceEntity],
+		constants.Binance_Smart_Chain: binance.Entities[traceEntity],
+		constants.Ethereum:            ethereum.Entities[traceEntity],
+		constants.Optimism:            optimism.Entities[traceEntity],
+		constants.Polygon:             polygon.Entities[traceEntity],
+		linea.Linea:                   linea.Entities[traceEntity],
+		scroll.Scroll:                 scroll.Entities[traceEntity],
+		zksync.ZkSyncEra:              zksync.Entities[traceEntity],
+	},
+	util.SchemaVersionTyped: {
+		arbitrum.ArbitrumOne:          arbitrum.EntitiesV2[traceEntity],
+		arbitrum.ArbitrumNova:         arbitrum.EntitiesV2[traceEntity],
+		constants.Base:                base.EntitiesV2[traceEntity],
+		constants.Binance_Smart_Chain: binance.EntitiesV2[traceEntity],
+		constants.Ethereum:            ethereum.EntitiesV2[traceEntity],
+		constants.Optimism:            optimism.EntitiesV2[traceEntity],
+		constants.Polygon:             polygon.EntitiesV2[traceEntity],
+		linea.Linea:                   linea.EntitiesV2[traceEntity],
+		scroll.Scroll:                 scroll.EntitiesV2[traceEntity],
+		zksync.ZkSyncEra:              zksync.EntitiesV2[traceEntity],
+	},
+}
+
+func main() {
+	chain := flag.String("chain", "", "blockchain whose traces are being migrated, e.g. ethereum")
+	from := flag.Uint64("from", 0, "first block height to migrate")
+	to := flag.Uint64("to", 0, "last block height to migrate (inclusive)")
+	directoryRange := flag.Uint64("range", 10000, "range directory size the files were written with")
+	schemaVersion := flag.Int("schema-version", util.SchemaVersionHex, "parquet schema version the files were written with")
+	descriptorPath := flag.String("descriptor", "", "chain descriptor of a chain indexed by the generic EVM driver, in place of -chain")
+	flag.Parse()
+
+	mgr := manager.New()
+	logger := mgr.Logger()
+	ctx := mgr.Context()
+
+	model, ok := models[*schemaVersion][constants.Blockchain(*chain)]
+	if *descriptorPath != "" {
+		descriptor, err := generic.LoadDescriptor(*descriptorPath)
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+		*chain = descriptor.Name
+		model, ok = generic.Models(descriptor, *schemaVersion)[traceEntity]
+		ok = ok && (*schemaVersion == util.SchemaVersionHex || *schemaVersion == util.SchemaVersionTyped)
+	}
+	if !ok {
+		logger.Fatalf("unsupported chain or schema version: %q, %d", *chain, *schemaVersion)
+	}
+	if *to < *from {
+		logger.Fatalf("invalid range: %d-%d", *from, *to)
+	}
+
+	store := storage.MustNewStore(ctx, logger)
+	migrator := trace.NewMigrator(store, model)
+	for bottom := (*from / *directoryRange) * *directoryRange; bottom <= *to; bottom += *directoryRange {
+		files, err := store.List(ctx, fmt.Sprintf("%s/%s/", traceEntity, util.RangeName(bottom, *directoryRange)))
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+
+		for _, file := range files {
+			start, end, err := util.ParseFileHeights(file.Name)
+			if err != nil || end < *from || start > *to {
+				continue
+			}
+
+			rewritten, err := migrator.MigrateFile(ctx, file.Name)
+			if err != nil {
+				logger.Fatalf("%v", err)
+			}
+			if rewritten {
+				logger.Infof("migrated %s", file.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Command record-fixtures captures JSON-RPC responses for a range of blocks from a live node, for replay through
+// fixture.Client in offline tests.
+//
+// The node is configured through the same environment variables as the ETL (BLOCKCHAIN, NODE_HOST, ...), e.g.
+//
+//	BLOCKCHAIN=ethereum NODE_HOST=https://... record-fixtures -out testdata/fixtures -from 17000000 -to 17000010
+//
+// For Ethereum, setting BEACON_HOST also records the beacon API responses behind every post-Merge block.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/drivers/arbitrum"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/generic"
+	"github.com/coherentopensource/evm-etl/drivers/linea"
+	"github.com/coherentopensource/evm-etl/drivers/scroll"
+	"github.com/coherentopensource/evm-etl/drivers/zksync"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/constants"
+	"github.com/coherentopensource/go-service-framework/manager"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"path/filepath"
+)
+
+func main() {
+	out := flag.String("out", "testdata/fixtures", "root directory for fixtures; files are written to <out>/<blockchain>")
+	from := flag.Uint64("from", 0, "first block height to record")
+	to := flag.Uint64("to", 0, "last block height to record (inclusive)")
+	skipTraces := flag.Bool("skip-traces", false, "do not record debug_traceBlockByNumber responses")
+	descriptorPath := flag.String("descriptor", "", "chain descriptor of a chain indexed by the generic EVM driver, which decides how receipts and traces are recorded")
+	flag.Parse()
+
+	mgr := manager.New()
+	logger := mgr.Logger()
+	ctx := mgr.Context()
+
+	if *to < *from {
+		logger.Fatalf("invalid range: %d-%d", *from, *to)
+	}
+
+	cfg := node.MustParseConfig(logger)
+	rpcClient, err := rpc.DialContext(ctx, cfg.NodeHost)
+	if err != nil {
+		logger.Fatalf("could not connect to node: %v", err)
+	}
+	dir := filepath.Join(*out, string(cfg.Blockchain))
+	recorder := fixture.NewRecorder(node.MustNewClient(cfg, logger), dir).WithCaller(rpcClient)
+	opts := fixture.RecordOptions{
+		// the OP-stack, Arbitrum, Linea and Scroll drivers fetch receipts per transaction rather than per block
+		PerTxReceipts: cfg.Blockchain == constants.Base || cfg.Blockchain == constants.Optimism ||
+			cfg.Blockchain == arbitrum.ArbitrumOne || cfg.Blockchain == arbitrum.ArbitrumNova ||
+			cfg.Blockchain == linea.Linea || cfg.Blockchain == scroll.Scroll,
+		SkipTraces: *skipTraces,
+	}
+	if cfg.Blockchain == zksync.ZkSyncEra {
+		opts.BlockCalls = zksync.BlockCalls
+	}
+	if *descriptorPath != "" {
+		descriptor, err := generic.LoadDescriptor(*descriptorPath)
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+		opts.PerTxReceipts = descriptor.ReceiptMethod == generic.ReceiptMethodTransaction
+		opts.SkipTraces = opts.SkipTraces || descriptor.TraceMethod == generic.TraceMethodNone
+	}
+
+	var beaconClient *beacon.Client
+	if beaconCfg := beacon.MustParseConfig(logger); beaconCfg.Host != "" && cfg.Blockchain == constants.Ethereum {
+		beaconClient = beacon.NewClient(beaconCfg, fixture.NewBeaconRecorder(beacon.NewHTTPTransport(beaconCfg), dir))
+	}
+
+	if _, err := recorder.GetLatestBlockNumber(ctx); err != nil {
+		logger.Fatalf("could not record chaintip: %v", err)
+	}
+	for height := *from; height <= *to; height++ {
+		if err := recorder.RecordBlock(ctx, height, opts); err != nil {
+			logger.Fatalf("%v", err)
+		}
+		if beaconClient != nil {
+			if err := recordBeaconBlock(ctx, recorder, beaconClient, height); err != nil {
+				logger.Fatalf("%v", err)
+			}
+		}
+		logger.Infof("recorded block %d", height)
+	}
+}
+
+// recordBeaconBlock records the beacon API responses the Ethereum driver reads to join a block to its beacon block
+func recordBeaconBlock(ctx context.Context, recorder *fixture.Recorder, beaconClient *beacon.Client, height uint64) error {
+	res, err := recorder.GetBlockByNumber(ctx, height)
+	if err != nil {
+		return err
+	}
+	var block ethereum.Block
+	if err := json.Unmarshal(res.Result, &block); err != nil {
+		return errors.Wrapf(err, "cannot decode block %d", height)
+	}
+	if !block.PostMerge() {
+		return nil
+	}
+
+	timestamp, err := util.HexToInt64(block.Timestamp)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timestamp of block %d", height)
+	}
+	_, err = beaconClient.GetPayloadBlock(ctx, uint64(timestamp), block.Hash, block.ParentBeaconBlockRoot)
+	return err
+}
@@ -0,0 +1,43 @@
+package arbitrum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransactionToParquetAccessList(t *testing.T) {
+	tests := []struct {
+		name       string
+		accessList []*Access
+		want       []string
+	}{
+		{
+			//	ArbOS transaction types have no access list
+			name: "none",
+		},
+		{
+			//	Entries are written as the chains that marshal their protos write them
+			name: "entries",
+			accessList: []*Access{
+				{Address: "0xcccccccccccccccccccccccccccccccccccccccc", StorageKeys: []string{"0x01", "0x02"}},
+				{Address: "0xdddddddddddddddddddddddddddddddddddddddd"},
+			},
+			want: []string{
+				`{"address":"0xcccccccccccccccccccccccccccccccccccccccc","storage_keys":["0x01","0x02"]}`,
+				`{"address":"0xdddddddddddddddddddddddddddddddddddddddd"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := TransactionToParquet(&Transaction{Type: "0x2", AccessList: tt.accessList}, &TransactionReceipt{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(out.AccessList, tt.want) {
+				t.Errorf("access_list = %v, want %v", out.AccessList, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package arbitrum
+
+import (
+	"github.com/caarlos0/env/v7"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/coherentopensource/go-service-framework/util"
+)
+
+// Config stores configurable properties of the driver
+type Config struct {
+	evm.Config
+	// Network is either NetworkOne or NetworkNova
+	Network string `env:"ARBITRUM_NETWORK" envDefault:"one"`
+}
+
+// MustParseConfig uses env.Parse to initialize config with environment variables
+func MustParseConfig(logger util.Logger) *Config {
+	var cfg Config
+	if err := env.Parse(&cfg); err != nil {
+		logger.Fatalf("could not parse Arbitrum driver config: %v", err)
+	}
+
+	return &cfg
+}
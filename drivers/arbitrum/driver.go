@@ -0,0 +1,89 @@
+// Package arbitrum indexes the Arbitrum One and Nova rollups from a Nitro node. On top of the Ethereum columns, blocks
+// carry the L1 block number they were built against and the outbox's send count and root, transactions carry the share
+// of gas spent on L1 calldata, and the transaction types ArbOS adds for L1 deposits, retryable tickets and its own
+// internal bookkeeping (see TxTypeDeposit and the rest) keep their extra fields. There are no chain-interactor protos
+// for Arbitrum, so Nitro's JSON-RPC responses are decoded into plain structs named after the other chains' protos.
+package arbitrum
+
+import (
+	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/arbitrum"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/constants"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
+)
+
+// Networks an Arbitrum driver may be configured for
+const (
+	NetworkOne  = "one"
+	NetworkNova = "nova"
+)
+
+// Blockchain names of the Arbitrum networks, which the service framework has no constants for
+const (
+	ArbitrumOne  constants.Blockchain = "arbitrum"
+	ArbitrumNova constants.Blockchain = "arbitrum_nova"
+)
+
+// Driver is the container for all ETL business logic
+type Driver = evm.Driver[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace]
+
+// Data is a block together with its receipts and call traces, as handed to the writers
+type Data = evm.Data[*Block, *TransactionReceipt, *CallTrace]
+
+// Entities maps each entity directory to the parquet model its files are written with, for tools that read the output
+// back generically; both networks share the same models
+var Entities = newChain(ArbitrumOne).Models(util.SchemaVersionHex)
+
+// EntitiesV2 is Entities for the typed parquet schema
+var EntitiesV2 = newChain(ArbitrumOne).Models(util.SchemaVersionTyped)
+
+// New constructs a new Driver for the configured network
+func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	var blockchain constants.Blockchain
+	switch cfg.Network {
+	case NetworkOne:
+		blockchain = ArbitrumOne
+	case NetworkNova:
+		blockchain = ArbitrumNova
+	default:
+		logger.Fatalf("unsupported Arbitrum network %q; expected %s or %s", cfg.Network, NetworkOne, NetworkNova)
+	}
+
+	return evm.New(newChain(blockchain), &cfg.Config, nodeClient, innerStore, logger)
+}
+
+// newChain plugs the Arbitrum node types and codec into the shared EVM driver
+func newChain(blockchain constants.Blockchain) *evm.Chain[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace] {
+	return &evm.Chain[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace]{
+		Blockchain: blockchain,
+		//	The sequencer's ordering is only reversed by its own failure; L1 finality is too far behind to follow
+		Finality: evm.FinalityLatest,
+		//	Nitro nodes lack eth_getBlockReceipts, and every block opens with the ArbOS internal transaction
+		ReceiptsPerTransaction: true,
+		RequireTransactions:    true,
+
+		BlockToParquet: func(block *Block) interface{} {
+			return BlockToParquet(block)
+		},
+		TransactionToParquet: func(tx *Transaction, receipt *TransactionReceipt) (interface{}, error) {
+			return TransactionToParquet(tx, receipt)
+		},
+		LogToParquet: func(log *Log) interface{} {
+			return LogToParquet(log)
+		},
+		TraceToParquet: func(frame trace.Frame[*CallTrace], tx *Transaction) interface{} {
+			return TraceToParquet(frame, tx)
+		},
+
+		Entities: []evm.Entity[*Data]{
+			{Name: evm.EntityBlocks, Model: new(model.ParquetBlock), ModelV2: new(model.ParquetBlockV2), ToV2: evm.Typed(ParquetBlockToV2)},
+			{Name: evm.EntityTransactions, Model: new(model.ParquetTransaction), ModelV2: new(model.ParquetTransactionV2), ToV2: evm.Typed(ParquetTransactionToV2)},
+			{Name: evm.EntityLogs, Model: new(model.ParquetLog), ModelV2: new(model.ParquetLogV2), ToV2: evm.Typed(ParquetLogToV2)},
+			{Name: evm.EntityTraces, Model: new(model.ParquetTrace), ModelV2: new(model.ParquetTraceV2), ToV2: evm.Typed(ParquetTraceToV2)},
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package arbitrum
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/arbitrum"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"path/filepath"
+	"testing"
+)
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+// writeHeight runs a height through the driver as the poller would: fetch, accumulate, then every writer
+func writeHeight(ctx context.Context, d *Driver, height uint64) error {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		res, err := runner(ctx)
+		if err != nil {
+			return err
+		}
+		set[stage] = res
+	}
+	data, err := d.Accumulate(set)(ctx)
+	if err != nil {
+		return err
+	}
+	for _, writer := range d.Writers() {
+		if _, err := writer(data)(ctx); err != nil {
+			return err
+		}
+	}
+	return d.Flush(ctx)
+}
+
+// readEntity reads back every row written for an entity
+func readEntity(ctx context.Context, store *storage.MemoryConnector, entity string, mapToStruct interface{}) ([]interface{}, error) {
+	var rows []interface{}
+	for _, file := range store.FilesWithPrefix(entity + "/") {
+		fileRows, err := storage.ReadAll(ctx, store, file, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fileRows...)
+	}
+	return rows, nil
+}
+
+// l1Fields are the transaction columns Arbitrum adds, for the types that set them
+type l1Fields struct {
+	Type                string
+	GasUsedForL1        string
+	L1BlockNumber       string
+	RequestId           string
+	TicketId            string
+	RefundTo            string
+	SubmissionFeeRefund string
+	DepositValue        string
+	RetryTo             string
+	MaxSubmissionFee    string
+}
+
+func TestDriverWritesFixtures(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryConnector(10000)
+	cfg := Config{Config: evm.Config{MaxRetries: 1, DirectoryRange: 10000, WriteMode: storage.WriteModeBlock, SchemaVersion: util.SchemaVersionHex}, Network: NetworkOne}
+	d := New(&cfg, fixture.NewClient(filepath.Join("testdata", "retryable")), store, testLogger{})
+
+	if err := writeHeight(ctx, d, 200000000); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := readEntity(ctx, store, evm.EntityBlocks, new(model.ParquetBlock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 1 {
+		t.Fatalf("got %d blocks, want 1", len(blocks))
+	}
+	block := blocks[0].(*model.ParquetBlock)
+	if block.L1BlockNumber != "0x12a05f2" || block.SendCount != "0x2f0d" || block.SendRoot != "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" {
+		t.Errorf("block has l1_block_number %q, send_count %q and send_root %q", block.L1BlockNumber, block.SendCount, block.SendRoot)
+	}
+
+	rows, err := readEntity(ctx, store, evm.EntityTransactions, new(model.ParquetTransaction))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := make(map[string]*model.ParquetTransaction)
+	for _, row := range rows {
+		tx := row.(*model.ParquetTransaction)
+		txs[tx.Hash] = tx
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want l1Fields
+	}{
+		{
+			//	ArbOS opens every block with its own transaction, which spends nothing on L1
+			name: "internal",
+			hash: "0x6666666666666666666666666666666666666666666666666666666666666666",
+			want: l1Fields{Type: TxTypeInternal, GasUsedForL1: "0x0", L1BlockNumber: "0x12a05f2"},
+		},
+		{
+			name: "submit retryable",
+			hash: "0x7777777777777777777777777777777777777777777777777777777777777777",
+			want: l1Fields{
+				Type:             TxTypeSubmitRetryable,
+				GasUsedForL1:     "0x0",
+				L1BlockNumber:    "0x12a05f2",
+				RequestId:        "0x0000000000000000000000000000000000000000000000000000000000000009",
+				RefundTo:         "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+				DepositValue:     "0x2386f26fc10000",
+				RetryTo:          "0xcccccccccccccccccccccccccccccccccccccccc",
+				MaxSubmissionFee: "0x1c6bf52634000",
+			},
+		},
+		{
+			//	The redeem of the ticket the submission created
+			name: "retry",
+			hash: "0x8888888888888888888888888888888888888888888888888888888888888888",
+			want: l1Fields{
+				Type:                TxTypeRetry,
+				GasUsedForL1:        "0x0",
+				L1BlockNumber:       "0x12a05f2",
+				TicketId:            "0x7777777777777777777777777777777777777777777777777777777777777777",
+				RefundTo:            "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+				SubmissionFeeRefund: "0x1c6bf52634000",
+			},
+		},
+		{
+			name: "dynamic fee",
+			hash: "0x9999999999999999999999999999999999999999999999999999999999999999",
+			want: l1Fields{Type: "0x2", GasUsedForL1: "0x1f4", L1BlockNumber: "0x12a05f2"},
+		},
+	}
+
+	if len(txs) != len(tests) {
+		t.Fatalf("got %d transactions, want %d", len(txs), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, ok := txs[tt.hash]
+			if !ok {
+				t.Fatalf("transaction %s was not written", tt.hash)
+			}
+			got := l1Fields{
+				Type:                tx.Type,
+				GasUsedForL1:        tx.GasUsedForL1,
+				L1BlockNumber:       tx.L1BlockNumber,
+				RequestId:           tx.RequestId,
+				TicketId:            tx.TicketId,
+				RefundTo:            tx.RefundTo,
+				SubmissionFeeRefund: tx.SubmissionFeeRefund,
+				DepositValue:        tx.DepositValue,
+				RetryTo:             tx.RetryTo,
+				MaxSubmissionFee:    tx.MaxSubmissionFee,
+			}
+			if got != tt.want {
+				t.Errorf("transaction has %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	traces, err := readEntity(ctx, store, evm.EntityTraces, new(model.ParquetTrace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != len(tests) {
+		t.Errorf("got %d traces, want one per transaction", len(traces))
+	}
+}
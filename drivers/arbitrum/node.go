@@ -0,0 +1,134 @@
+package arbitrum
+
+import "github.com/coherentopensource/evm-etl/shared/rpc"
+
+// Arbitrum transaction types, on top of the standard legacy (0x0), access list (0x1) and dynamic fee (0x2) types
+const (
+	// TxTypeDeposit mints ETH deposited on L1 to an L2 address
+	TxTypeDeposit = "0x64"
+	// TxTypeUnsigned is an L1 contract's call into L2, without a signature
+	TxTypeUnsigned = "0x65"
+	// TxTypeContract is an L1 contract's call into L2, aliased to the contract's address
+	TxTypeContract = "0x66"
+	// TxTypeRetry is an attempt to redeem a retryable ticket, either automatically or manually
+	TxTypeRetry = "0x68"
+	// TxTypeSubmitRetryable creates a retryable ticket from an L1 message
+	TxTypeSubmitRetryable = "0x69"
+	// TxTypeInternal is inserted by ArbOS itself, e.g. to open every block with the current L1 block number
+	TxTypeInternal = "0x6a"
+	// TxTypeLegacy is a transaction carried over from the pre-Nitro chain
+	TxTypeLegacy = "0x78"
+)
+
+// Block is an Arbitrum block, with the L1 block number it was built against and the state of the outbox
+type Block struct {
+	Number           string         `json:"number"`
+	Hash             string         `json:"hash"`
+	ParentHash       string         `json:"parentHash"`
+	Nonce            string         `json:"nonce"`
+	Sha3Uncles       string         `json:"sha3Uncles"`
+	LogsBloom        string         `json:"logsBloom"`
+	TransactionsRoot string         `json:"transactionsRoot"`
+	StateRoot        string         `json:"stateRoot"`
+	ReceiptsRoot     string         `json:"receiptsRoot"`
+	Miner            string         `json:"miner"`
+	Difficulty       string         `json:"difficulty"`
+	TotalDifficulty  string         `json:"totalDifficulty"`
+	ExtraData        string         `json:"extraData"`
+	Size             string         `json:"size"`
+	GasLimit         string         `json:"gasLimit"`
+	GasUsed          string         `json:"gasUsed"`
+	Timestamp        string         `json:"timestamp"`
+	Transactions     []*Transaction `json:"transactions"`
+	Uncles           []string       `json:"uncles"`
+	BaseFeePerGas    string         `json:"baseFeePerGas"`
+	MixHash          string         `json:"mixHash"`
+	L1BlockNumber    string         `json:"l1BlockNumber"`
+	SendCount        string         `json:"sendCount"`
+	SendRoot         string         `json:"sendRoot"`
+}
+
+func (x *Block) GetNumber() string               { return x.Number }
+func (x *Block) GetHash() string                 { return x.Hash }
+func (x *Block) GetParentHash() string           { return x.ParentHash }
+func (x *Block) GetTransactions() []*Transaction { return x.Transactions }
+
+// Transaction is an Arbitrum transaction; the fields after Type are only set for the transaction types noted against
+// them
+type Transaction struct {
+	BlockHash            string    `json:"blockHash"`
+	BlockNumber          string    `json:"blockNumber"`
+	From                 string    `json:"from"`
+	Gas                  string    `json:"gas"`
+	GasPrice             string    `json:"gasPrice"`
+	Hash                 string    `json:"hash"`
+	Input                string    `json:"input"`
+	Nonce                string    `json:"nonce"`
+	To                   string    `json:"to"`
+	TransactionIndex     string    `json:"transactionIndex"`
+	Value                string    `json:"value"`
+	V                    string    `json:"v"`
+	R                    string    `json:"r"`
+	S                    string    `json:"s"`
+	AccessList           []*Access `json:"accessList"`
+	ChainId              string    `json:"chainId"`
+	MaxFeePerGas         string    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string    `json:"maxPriorityFeePerGas"`
+	Type                 string    `json:"type"`
+	// RequestId is the L1 message that caused a deposit, contract or submit retryable transaction, or a retry
+	RequestId string `json:"requestId"`
+	// TicketId, MaxRefund and SubmissionFeeRefund are set for retries
+	TicketId            string `json:"ticketId"`
+	MaxRefund           string `json:"maxRefund"`
+	SubmissionFeeRefund string `json:"submissionFeeRefund"`
+	// RefundTo is set for submit retryable transactions and retries
+	RefundTo string `json:"refundTo"`
+	// L1BaseFee through MaxSubmissionFee are set for submit retryable transactions
+	L1BaseFee        string `json:"l1BaseFee"`
+	DepositValue     string `json:"depositValue"`
+	RetryTo          string `json:"retryTo"`
+	RetryValue       string `json:"retryValue"`
+	RetryData        string `json:"retryData"`
+	Beneficiary      string `json:"beneficiary"`
+	MaxSubmissionFee string `json:"maxSubmissionFee"`
+}
+
+func (x *Transaction) GetHash() string      { return x.Hash }
+func (x *Transaction) GetBlockHash() string { return x.BlockHash }
+func (x *Transaction) GetFrom() string      { return x.From }
+func (x *Transaction) GetTo() string        { return x.To }
+func (x *Transaction) GetValue() string     { return x.Value }
+
+// Access is an entry of a transaction's access list
+type Access = rpc.Access
+
+// TransactionReceipt is an Arbitrum transaction receipt, with the share of the gas used that paid for posting the
+// transaction to L1
+type TransactionReceipt struct {
+	TransactionHash   string `json:"transactionHash"`
+	TransactionIndex  string `json:"transactionIndex"`
+	BlockHash         string `json:"blockHash"`
+	BlockNumber       string `json:"blockNumber"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	CumulativeGasUsed string `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+	GasUsed           string `json:"gasUsed"`
+	GasUsedForL1      string `json:"gasUsedForL1"`
+	L1BlockNumber     string `json:"l1BlockNumber"`
+	ContractAddress   string `json:"contractAddress"`
+	Logs              []*Log `json:"logs"`
+	LogsBloom         string `json:"logsBloom"`
+	Type              string `json:"type"`
+	Status            string `json:"status"`
+}
+
+func (x *TransactionReceipt) GetContractAddress() string { return x.ContractAddress }
+func (x *TransactionReceipt) GetStatus() string          { return x.Status }
+func (x *TransactionReceipt) GetLogs() []*Log            { return x.Logs }
+
+// Log is an Arbitrum log
+type Log = rpc.Log
+
+// CallTrace is a call frame as returned by Nitro's callTracer
+type CallTrace = rpc.CallTrace
@@ -1,12 +1,12 @@
 package binance
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
-	"fmt"
 	protos "github.com/coherentopensource/chain-interactor/protos/go/protos/chains/binance"
 	model "github.com/coherentopensource/evm-etl/model/binance"
+	"github.com/coherentopensource/evm-etl/shared/roots"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
 	"github.com/pkg/errors"
 )
 
@@ -59,7 +59,7 @@ func ProtoTransactionToParquet(inTx *protos.Transaction, inReceipt *protos.Trans
 		V:                    inTx.V,
 		R:                    inTx.R,
 		S:                    inTx.S,
-		MaxFeePerGas:         inTx.MaxPriorityFeePerGas,
+		MaxFeePerGas:         inTx.MaxFeePerGas,
 		MaxPriorityFeePerGas: inTx.MaxPriorityFeePerGas,
 		CumulativeGasUsed:    inReceipt.CumulativeGasUsed,
 		EffectiveGasPrice:    inReceipt.EffectiveGasPrice,
@@ -98,15 +98,19 @@ func ProtoLogToParquet(in *protos.Log) *model.ParquetLog {
 	return &out
 }
 
-// ProtoTraceToParquet converts a trace proto to parquet, given a trace, a transaction, and other supplemental data
-func ProtoTraceToParquet(inTrace *protos.CallTrace, inTransaction *protos.Transaction, hash string, parentHash string, index int64) *model.ParquetTrace {
+// ProtoTraceToParquet converts a flattened trace frame to parquet, given the transaction it belongs to
+func ProtoTraceToParquet(frame trace.Frame[*protos.CallTrace], inTransaction *protos.Transaction) *model.ParquetTrace {
+	inTrace := frame.Call
 	return &model.ParquetTrace{
 		BlockNumber:     inTransaction.BlockNumber,
 		BlockHash:       inTransaction.BlockHash,
 		TransactionHash: inTransaction.Hash,
-		Hash:            hash,
-		ParentHash:      parentHash,
-		Index:           index,
+		Hash:            frame.Hash,
+		ParentHash:      frame.ParentHash,
+		Index:           frame.Index,
+		TraceAddress:    frame.TraceAddress,
+		Depth:           frame.Depth,
+		Subtraces:       frame.Subtraces,
 		Type:            inTrace.Type,
 		From:            inTrace.From,
 		To:              inTrace.To,
@@ -120,10 +124,166 @@ func ProtoTraceToParquet(inTrace *protos.CallTrace, inTransaction *protos.Transa
 	}
 }
 
-// hashCallTrace hashes a trace proto
-func hashCallTrace(callTrace *protos.CallTrace) string {
-	hasher := sha256.New()
-	hasher.Write([]byte(fmt.Sprintf("%v", callTrace)))
+// ProtoBlockRoots returns the transactions and receipts roots a block proto's header commits to
+func ProtoBlockRoots(in *protos.Block) (string, string) {
+	return in.GetTransactionsRoot(), in.GetReceiptsRoot()
+}
+
+// ProtoTransactionToRoots copies out the fields of a transaction proto that the transactions root hashes
+func ProtoTransactionToRoots(in *protos.Transaction) *roots.Transaction {
+	out := roots.Transaction{
+		Type:                 in.GetType(),
+		ChainId:              in.GetChainId(),
+		Nonce:                in.GetNonce(),
+		GasPrice:             in.GetGasPrice(),
+		MaxPriorityFeePerGas: in.GetMaxPriorityFeePerGas(),
+		MaxFeePerGas:         in.GetMaxFeePerGas(),
+		Gas:                  in.GetGas(),
+		To:                   in.GetTo(),
+		Value:                in.GetValue(),
+		Input:                in.GetInput(),
+		V:                    in.GetV(),
+		R:                    in.GetR(),
+		S:                    in.GetS(),
+	}
+	for _, access := range in.GetAccessList() {
+		out.AccessList = append(out.AccessList, roots.Access{Address: access.GetAddress(), StorageKeys: access.GetStorageKeys()})
+	}
+
+	return &out
+}
+
+// ProtoReceiptToRoots copies out the fields of a receipt proto that the receipts root hashes
+func ProtoReceiptToRoots(in *protos.TransactionReceipt) *roots.Receipt {
+	out := roots.Receipt{
+		Type:              in.GetType(),
+		Root:              in.GetRoot(),
+		Status:            in.GetStatus(),
+		CumulativeGasUsed: in.GetCumulativeGasUsed(),
+		LogsBloom:         in.GetLogsBloom(),
+	}
+	for _, log := range in.GetLogs() {
+		out.Logs = append(out.Logs, roots.Log{Address: log.GetAddress(), Topics: log.GetTopics(), Data: log.GetData()})
+	}
+
+	return &out
+}
+
+// ParquetBlockToV2 converts a block from the hex schema to the typed schema
+func ParquetBlockToV2(in *model.ParquetBlock) (*model.ParquetBlockV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetBlockV2{
+		Number:           c.Int64("block_number", in.Number),
+		Hash:             in.Hash,
+		ParentHash:       in.ParentHash,
+		Nonce:            in.Nonce,
+		SHA3Uncles:       in.SHA3Uncles,
+		LogsBloom:        in.LogsBloom,
+		TransactionsRoot: in.TransactionsRoot,
+		StateRoot:        in.StateRoot,
+		ReceiptsRoot:     in.ReceiptsRoot,
+		Miner:            in.Miner,
+		Difficulty:       c.OptionalDecimal38("difficulty", in.Difficulty),
+		TotalDifficulty:  c.OptionalDecimal38("total_difficulty", in.TotalDifficulty),
+		ExtraData:        in.ExtraData,
+		Size:             c.Int64("size", in.Size),
+		GasLimit:         c.Int64("gas_limit", in.GasLimit),
+		GasUsed:          c.Int64("gas_used", in.GasUsed),
+		Timestamp:        c.TimestampMillis("timestamp", in.Timestamp),
+		Uncles:           in.Uncles,
+		BaseFeePerGas:    c.OptionalDecimal38("base_fee_per_gas", in.BaseFeePerGas),
+		MixHash:          in.MixHash,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert block to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetTransactionToV2 converts a transaction from the hex schema to the typed schema
+func ParquetTransactionToV2(in *model.ParquetTransaction) (*model.ParquetTransactionV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTransactionV2{
+		BlockNumber:          c.Int64("block_number", in.BlockNumber),
+		BlockHash:            in.BlockHash,
+		Hash:                 in.Hash,
+		From:                 in.From,
+		To:                   in.To,
+		Value:                c.Decimal38("value", in.Value),
+		Gas:                  c.Int64("gas", in.Gas),
+		GasPrice:             c.OptionalDecimal38("gas_price", in.GasPrice),
+		Input:                in.Input,
+		Type:                 c.OptionalInt64("type", in.Type),
+		Nonce:                c.Int64("nonce", in.Nonce),
+		TransactionIndex:     c.Int64("transaction_index", in.TransactionIndex),
+		V:                    in.V,
+		R:                    in.R,
+		S:                    in.S,
+		CumulativeGasUsed:    c.Int64("cumulative_gas_used", in.CumulativeGasUsed),
+		EffectiveGasPrice:    c.OptionalDecimal38("effective_gas_price", in.EffectiveGasPrice),
+		MaxFeePerGas:         c.OptionalDecimal38("max_fee_per_gas", in.MaxFeePerGas),
+		MaxPriorityFeePerGas: c.OptionalDecimal38("max_priority_fee_per_gas", in.MaxPriorityFeePerGas),
+		GasUsed:              c.Int64("gas_used", in.GasUsed),
+		LogsBloom:            in.LogsBloom,
+		Status:               c.OptionalInt64("status", in.Status),
+		AccessList:           in.AccessList,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert transaction to typed schema")
+	}
 
-	return hex.EncodeToString(hasher.Sum(nil))
+	return &out, nil
+}
+
+// ParquetLogToV2 converts a log from the hex schema to the typed schema
+func ParquetLogToV2(in *model.ParquetLog) (*model.ParquetLogV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetLogV2{
+		BlockNumber:      c.Int64("block_number", in.BlockNumber),
+		BlockHash:        in.BlockHash,
+		TransactionHash:  in.TransactionHash,
+		TransactionIndex: c.Int64("transaction_index", in.TransactionIndex),
+		LogIndex:         c.Int64("log_index", in.LogIndex),
+		Address:          in.Address,
+		Data:             in.Data,
+		Topics:           in.Topics,
+		Removed:          in.Removed,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert log to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetTraceToV2 converts a trace from the hex schema to the typed schema
+func ParquetTraceToV2(in *model.ParquetTrace) (*model.ParquetTraceV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTraceV2{
+		BlockNumber:     c.Int64("block_number", in.BlockNumber),
+		BlockHash:       in.BlockHash,
+		TransactionHash: in.TransactionHash,
+		Hash:            in.Hash,
+		ParentHash:      in.ParentHash,
+		Index:           in.Index,
+		TraceAddress:    in.TraceAddress,
+		Depth:           in.Depth,
+		Subtraces:       in.Subtraces,
+		Type:            in.Type,
+		From:            in.From,
+		To:              in.To,
+		Value:           c.OptionalDecimal38("value", in.Value),
+		Gas:             c.OptionalInt64("gas", in.Gas),
+		GasUsed:         c.OptionalInt64("gas_used", in.GasUsed),
+		Input:           in.Input,
+		Output:          in.Output,
+		Error:           in.Error,
+		RevertReason:    in.RevertReason,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert trace to typed schema")
+	}
+
+	return &out, nil
 }
@@ -2,36 +2,67 @@ package binance
 
 import (
 	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	protos "github.com/coherentopensource/chain-interactor/protos/go/protos/chains/binance"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/binance"
 	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
 	"github.com/coherentopensource/go-service-framework/constants"
-	"github.com/coherentopensource/go-service-framework/util"
-)
-
-const (
-	stageFetchBlock   = "fetch.block"
-	stageFetchReceipt = "fetch.receipt"
-	stageFetchTraces  = "fetch.traces"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
 )
 
 // Driver is the container for all ETL business logic
-type Driver struct {
-	store      *store
-	nodeClient *client
-	logger     util.Logger
-	config     *Config
+type Driver = evm.Driver[*protos.Block, *protos.Transaction, *protos.TransactionReceipt, *protos.Log, *protos.CallTrace]
+
+// Data is a block together with its receipts and call traces, as handed to the writers
+type Data = evm.Data[*protos.Block, *protos.TransactionReceipt, *protos.CallTrace]
+
+// chain plugs the Binance Smart Chain protos and codec into the shared EVM driver
+var chain = &evm.Chain[*protos.Block, *protos.Transaction, *protos.TransactionReceipt, *protos.Log, *protos.CallTrace]{
+	Blockchain: constants.Binance_Smart_Chain,
+	//	Fast finality finalizes blocks within a few seconds of the latest
+	Finality: evm.FinalityFinalized,
+
+	BlockToParquet: func(block *protos.Block) interface{} {
+		return ProtoBlockToParquet(block)
+	},
+	TransactionToParquet: func(tx *protos.Transaction, receipt *protos.TransactionReceipt) (interface{}, error) {
+		return ProtoTransactionToParquet(tx, receipt)
+	},
+	LogToParquet: func(log *protos.Log) interface{} {
+		return ProtoLogToParquet(log)
+	},
+	TraceToParquet: func(frame trace.Frame[*protos.CallTrace], tx *protos.Transaction) interface{} {
+		return ProtoTraceToParquet(frame, tx)
+	},
+	Roots:           ProtoBlockRoots,
+	RootTransaction: ProtoTransactionToRoots,
+	RootReceipt:     ProtoReceiptToRoots,
+
+	Entities: []evm.Entity[*Data]{
+		{Name: evm.EntityBlocks, Model: new(model.ParquetBlock), ModelV2: new(model.ParquetBlockV2), ToV2: evm.Typed(ParquetBlockToV2)},
+		{Name: evm.EntityTransactions, Model: new(model.ParquetTransaction), ModelV2: new(model.ParquetTransactionV2), ToV2: evm.Typed(ParquetTransactionToV2)},
+		{Name: evm.EntityLogs, Model: new(model.ParquetLog), ModelV2: new(model.ParquetLogV2), ToV2: evm.Typed(ParquetLogToV2)},
+		{Name: evm.EntityTraces, Model: new(model.ParquetTrace), ModelV2: new(model.ParquetTraceV2), ToV2: evm.Typed(ParquetTraceToV2)},
+	},
 }
 
-// NewDriver constructs a new Driver
-func NewDriver(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger util.Logger) *Driver {
-	return &Driver{
-		nodeClient: &client{innerClient: nodeClient},
-		store:      &store{innerStore: innerStore},
-		logger:     logger,
-		config:     cfg,
-	}
+// Entities maps each entity directory to the parquet model its files are written with, for tools that read the output
+// back generically
+var Entities = chain.Models(util.SchemaVersionHex)
+
+// EntitiesV2 is Entities for the typed parquet schema
+var EntitiesV2 = chain.Models(util.SchemaVersionTyped)
+
+// New constructs a new Driver
+func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	return evm.New(chain, cfg, nodeClient, innerStore, logger)
 }
 
-// Blockchain returns the name of the blockchain
-func (d *Driver) Blockchain() string {
-	return string(constants.Binance_Smart_Chain)
+// NewDriver constructs a new Driver
+//
+// Deprecated: use New, which every chain provides
+func NewDriver(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	return New(cfg, nodeClient, innerStore, logger)
 }
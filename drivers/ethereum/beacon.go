@@ -0,0 +1,47 @@
+package ethereum
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/ethereum"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
+)
+
+// postMergeDifficulty is the difficulty of every block since the Merge
+const postMergeDifficulty = "0x0"
+
+// withBeacon extends a chain with the beacon_blocks and blob_sidecars entities, joining every post-Merge block to the
+// beacon block that carried it through a beacon client
+func withBeacon(chain *evm.Chain[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace], beaconClient *beacon.Client) *evm.Chain[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace] {
+	extended := *chain
+	extended.CompleteBlock = func(ctx context.Context, block *Block) error {
+		return completeBeaconBlock(ctx, beaconClient, block)
+	}
+	extended.Entities = append(append([]evm.Entity[*Data]{}, chain.Entities...),
+		evm.Entity[*Data]{Name: entityBeaconBlocks, Model: new(model.ParquetBeaconBlock), ModelV2: new(model.ParquetBeaconBlockV2), ToV2: evm.Typed(ParquetBeaconBlockToV2), Rows: beaconBlockRows},
+		evm.Entity[*Data]{Name: entityBlobSidecars, Model: new(model.ParquetBlobSidecar), ModelV2: new(model.ParquetBlobSidecarV2), ToV2: evm.Typed(ParquetBlobSidecarToV2), Rows: blobSidecarRows},
+	)
+	return &extended
+}
+
+// completeBeaconBlock fills in the beacon block that carried a block, found by the slot of its timestamp; blocks from
+// before the Merge have none
+func completeBeaconBlock(ctx context.Context, c *beacon.Client, block *Block) error {
+	if !block.PostMerge() {
+		return nil
+	}
+
+	timestamp, err := util.HexToInt64(block.Timestamp)
+	if err != nil {
+		return errors.Wrap(err, "invalid block timestamp")
+	}
+	beaconBlock, err := c.GetPayloadBlock(ctx, uint64(timestamp), block.Hash, block.ParentBeaconBlockRoot)
+	if err != nil {
+		return err
+	}
+	block.Beacon = beaconBlock
+
+	return nil
+}
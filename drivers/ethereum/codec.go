@@ -1,38 +1,49 @@
 package ethereum
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
-	"fmt"
-	protos "github.com/coherentopensource/chain-interactor/protos/go/protos/chains/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
 	model "github.com/coherentopensource/evm-etl/model/ethereum"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/coherentopensource/evm-etl/shared/roots"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
 	"github.com/pkg/errors"
 )
 
-// ProtoBlockToParquet converts a block proto to parquet
-func ProtoBlockToParquet(in *protos.Block) *model.ParquetBlock {
+// accessEntry is an access list entry as written to parquet, keeping the snake_case keys of the files written while the
+// driver still read nodes with protos
+type accessEntry struct {
+	Address     string   `json:"address,omitempty"`
+	StorageKeys []string `json:"storage_keys,omitempty"`
+}
+
+// BlockToParquet converts a block to parquet
+func BlockToParquet(in *Block) *model.ParquetBlock {
 	out := model.ParquetBlock{
-		Number:           in.Number,
-		Hash:             in.Hash,
-		ParentHash:       in.ParentHash,
-		Nonce:            in.Nonce,
-		SHA3Uncles:       in.Sha3Uncles,
-		LogsBloom:        in.LogsBloom,
-		TransactionsRoot: in.TransactionsRoot,
-		StateRoot:        in.StateRoot,
-		ReceiptsRoot:     in.ReceiptsRoot,
-		Miner:            in.Miner,
-		Difficulty:       in.Difficulty,
-		TotalDifficulty:  in.TotalDifficulty,
-		ExtraData:        in.ExtraData,
-		Size:             in.Size,
-		GasLimit:         in.GasLimit,
-		GasUsed:          in.GasUsed,
-		Timestamp:        in.Timestamp,
-		BaseFeePerGas:    in.BaseFeePerGas,
-		MixHash:          in.MixHash,
-		WithdrawalsRoot:  in.WithdrawalsRoot,
+		Number:                in.Number,
+		Hash:                  in.Hash,
+		ParentHash:            in.ParentHash,
+		Nonce:                 in.Nonce,
+		SHA3Uncles:            in.Sha3Uncles,
+		LogsBloom:             in.LogsBloom,
+		TransactionsRoot:      in.TransactionsRoot,
+		StateRoot:             in.StateRoot,
+		ReceiptsRoot:          in.ReceiptsRoot,
+		Miner:                 in.Miner,
+		Difficulty:            in.Difficulty,
+		TotalDifficulty:       in.TotalDifficulty,
+		ExtraData:             in.ExtraData,
+		Size:                  in.Size,
+		GasLimit:              in.GasLimit,
+		GasUsed:               in.GasUsed,
+		Timestamp:             in.Timestamp,
+		BaseFeePerGas:         in.BaseFeePerGas,
+		MixHash:               in.MixHash,
+		WithdrawalsRoot:       in.WithdrawalsRoot,
+		BlobGasUsed:           in.BlobGasUsed,
+		ExcessBlobGas:         in.ExcessBlobGas,
+		ParentBeaconBlockRoot: in.ParentBeaconBlockRoot,
 	}
 
 	for _, uncle := range in.Uncles {
@@ -42,8 +53,8 @@ func ProtoBlockToParquet(in *protos.Block) *model.ParquetBlock {
 	return &out
 }
 
-// ProtoTransactionToParquet converts a transaction proto to parquet, given a transaction and receipt
-func ProtoTransactionToParquet(inTx *protos.Transaction, inReceipt *protos.TransactionReceipt) (*model.ParquetTransaction, error) {
+// TransactionToParquet converts a transaction to parquet, given a transaction and receipt
+func TransactionToParquet(inTx *Transaction, inReceipt *TransactionReceipt) (*model.ParquetTransaction, error) {
 	out := model.ParquetTransaction{
 		BlockNumber:          inTx.BlockNumber,
 		BlockHash:            inTx.BlockHash,
@@ -60,17 +71,20 @@ func ProtoTransactionToParquet(inTx *protos.Transaction, inReceipt *protos.Trans
 		V:                    inTx.V,
 		R:                    inTx.R,
 		S:                    inTx.S,
-		MaxFeePerGas:         inTx.MaxPriorityFeePerGas,
+		MaxFeePerGas:         inTx.MaxFeePerGas,
 		MaxPriorityFeePerGas: inTx.MaxPriorityFeePerGas,
 		CumulativeGasUsed:    inReceipt.CumulativeGasUsed,
 		EffectiveGasPrice:    inReceipt.EffectiveGasPrice,
 		GasUsed:              inReceipt.GasUsed,
 		LogsBloom:            inReceipt.LogsBloom,
 		Status:               inReceipt.Status,
+		MaxFeePerBlobGas:     inTx.MaxFeePerBlobGas,
+		BlobVersionedHashes:  inTx.BlobVersionedHashes,
+		BlobGasPrice:         inReceipt.BlobGasPrice,
 	}
 
 	for _, access := range inTx.AccessList {
-		accessJSON, err := json.Marshal(access)
+		accessJSON, err := json.Marshal(accessEntry{Address: access.Address, StorageKeys: access.StorageKeys})
 		if err != nil {
 			return nil, errors.Errorf("failed to convert struct to json: %v", err)
 		}
@@ -80,8 +94,8 @@ func ProtoTransactionToParquet(inTx *protos.Transaction, inReceipt *protos.Trans
 	return &out, nil
 }
 
-// ProtoLogToParquet converts a log proto to parquet
-func ProtoLogToParquet(in *protos.Log) *model.ParquetLog {
+// LogToParquet converts a log to parquet
+func LogToParquet(in *Log) *model.ParquetLog {
 	out := model.ParquetLog{
 		BlockNumber:      in.BlockNumber,
 		BlockHash:        in.BlockHash,
@@ -99,8 +113,8 @@ func ProtoLogToParquet(in *protos.Log) *model.ParquetLog {
 	return &out
 }
 
-// ProtoWithdrawalToParquet converts a withdrawal proto to parquet
-func ProtoWithdrawalToParquet(in *protos.Withdrawal, blockNumber string) *model.ParquetWithdrawal {
+// WithdrawalToParquet converts a withdrawal to parquet
+func WithdrawalToParquet(in *Withdrawal, blockNumber string) *model.ParquetWithdrawal {
 	out := model.ParquetWithdrawal{
 		BlockNumber:    blockNumber,
 		Index:          in.Index,
@@ -112,15 +126,82 @@ func ProtoWithdrawalToParquet(in *protos.Withdrawal, blockNumber string) *model.
 	return &out
 }
 
-// ProtoTraceToParquet converts a trace proto to parquet, given a trace, a transaction, and other supplemental data
-func ProtoTraceToParquet(inTrace *protos.CallTrace, inTransaction *protos.Transaction, hash string, parentHash string, index int64) *model.ParquetTrace {
+// NativeWithdrawals lists a block's withdrawals for the shared native transfers entity
+func NativeWithdrawals(block *Block) []evm.Withdrawal {
+	var out []evm.Withdrawal
+	for _, withdrawal := range block.Withdrawals {
+		out = append(out, evm.Withdrawal{Index: withdrawal.Index, Address: withdrawal.Address, Amount: withdrawal.Amount})
+	}
+	return out
+}
+
+// BlobToParquet converts one of a blob transaction's versioned hashes to parquet, given its index within the
+// transaction
+func BlobToParquet(inTransaction *Transaction, index int, versionedHash string) *model.ParquetBlob {
+	out := model.ParquetBlob{
+		BlockNumber:      inTransaction.BlockNumber,
+		BlockHash:        inTransaction.BlockHash,
+		TransactionHash:  inTransaction.Hash,
+		TransactionIndex: inTransaction.TransactionIndex,
+		Index:            int64(index),
+		VersionedHash:    versionedHash,
+	}
+
+	return &out
+}
+
+// BeaconBlockToParquet converts the beacon block behind a block to parquet
+func BeaconBlockToParquet(in *Block) *model.ParquetBeaconBlock {
+	out := model.ParquetBeaconBlock{
+		BlockNumber:   in.Number,
+		BlockHash:     in.Hash,
+		Slot:          int64(in.Beacon.Header.Slot),
+		Root:          in.Beacon.Root,
+		ParentRoot:    in.Beacon.Header.ParentRoot,
+		StateRoot:     in.Beacon.Header.StateRoot,
+		ProposerIndex: int64(in.Beacon.Header.ProposerIndex),
+		Graffiti:      in.Beacon.Graffiti,
+		BlobCount:     int64(len(in.Beacon.BlobKzgCommitments)),
+	}
+
+	return &out
+}
+
+// BlobSidecarToParquet converts one of the blob sidecars of the beacon block behind a block to parquet
+func BlobSidecarToParquet(inBlock *Block, in *beacon.BlobSidecar) (*model.ParquetBlobSidecar, error) {
+	versionedHash, err := in.VersionedHash()
+	if err != nil {
+		return nil, err
+	}
+
+	out := model.ParquetBlobSidecar{
+		BlockNumber:   inBlock.Number,
+		BlockHash:     inBlock.Hash,
+		Slot:          int64(inBlock.Beacon.Header.Slot),
+		Root:          inBlock.Beacon.Root,
+		Index:         int64(in.Index),
+		KzgCommitment: in.KzgCommitment,
+		KzgProof:      in.KzgProof,
+		VersionedHash: versionedHash,
+		Size:          int64(in.Size),
+	}
+
+	return &out, nil
+}
+
+// TraceToParquet converts a flattened trace frame to parquet, given the transaction it belongs to
+func TraceToParquet(frame trace.Frame[*CallTrace], inTransaction *Transaction) *model.ParquetTrace {
+	inTrace := frame.Call
 	return &model.ParquetTrace{
 		BlockNumber:     inTransaction.BlockNumber,
 		BlockHash:       inTransaction.BlockHash,
 		TransactionHash: inTransaction.Hash,
-		Hash:            hash,
-		ParentHash:      parentHash,
-		Index:           index,
+		Hash:            frame.Hash,
+		ParentHash:      frame.ParentHash,
+		Index:           frame.Index,
+		TraceAddress:    frame.TraceAddress,
+		Depth:           frame.Depth,
+		Subtraces:       frame.Subtraces,
 		Type:            inTrace.Type,
 		From:            inTrace.From,
 		To:              inTrace.To,
@@ -134,10 +215,287 @@ func ProtoTraceToParquet(inTrace *protos.CallTrace, inTransaction *protos.Transa
 	}
 }
 
-// hashCallTrace hashes a trace proto
-func hashCallTrace(callTrace *protos.CallTrace) string {
-	hasher := sha256.New()
-	hasher.Write([]byte(fmt.Sprintf("%v", callTrace)))
+// ProtoBlockToParquet is the name BlockToParquet went by while the driver read nodes with protos
+//
+// Deprecated: use BlockToParquet
+func ProtoBlockToParquet(in *Block) *model.ParquetBlock {
+	return BlockToParquet(in)
+}
+
+// ProtoTransactionToParquet is the name TransactionToParquet went by while the driver read nodes with protos
+//
+// Deprecated: use TransactionToParquet
+func ProtoTransactionToParquet(inTx *Transaction, inReceipt *TransactionReceipt) (*model.ParquetTransaction, error) {
+	return TransactionToParquet(inTx, inReceipt)
+}
+
+// ProtoLogToParquet is the name LogToParquet went by while the driver read nodes with protos
+//
+// Deprecated: use LogToParquet
+func ProtoLogToParquet(in *Log) *model.ParquetLog {
+	return LogToParquet(in)
+}
+
+// ProtoWithdrawalToParquet is the name WithdrawalToParquet went by while the driver read nodes with protos
+//
+// Deprecated: use WithdrawalToParquet
+func ProtoWithdrawalToParquet(in *Withdrawal, blockNumber string) *model.ParquetWithdrawal {
+	return WithdrawalToParquet(in, blockNumber)
+}
+
+// ProtoTraceToParquet is the name TraceToParquet went by while the driver read nodes with protos
+//
+// Deprecated: use TraceToParquet
+func ProtoTraceToParquet(frame trace.Frame[*CallTrace], inTransaction *Transaction) *model.ParquetTrace {
+	return TraceToParquet(frame, inTransaction)
+}
+
+// BlockRoots returns the transactions and receipts roots a block's header commits to
+func BlockRoots(in *Block) (string, string) {
+	return in.TransactionsRoot, in.ReceiptsRoot
+}
+
+// TransactionToRoots copies out the fields of a transaction that the transactions root hashes
+func TransactionToRoots(in *Transaction) *roots.Transaction {
+	out := roots.Transaction{
+		Type:                 in.Type,
+		ChainId:              in.ChainId,
+		Nonce:                in.Nonce,
+		GasPrice:             in.GasPrice,
+		MaxPriorityFeePerGas: in.MaxPriorityFeePerGas,
+		MaxFeePerGas:         in.MaxFeePerGas,
+		Gas:                  in.Gas,
+		To:                   in.To,
+		Value:                in.Value,
+		Input:                in.Input,
+		MaxFeePerBlobGas:     in.MaxFeePerBlobGas,
+		BlobVersionedHashes:  in.BlobVersionedHashes,
+		V:                    in.V,
+		R:                    in.R,
+		S:                    in.S,
+	}
+	for _, access := range in.AccessList {
+		out.AccessList = append(out.AccessList, roots.Access{Address: access.Address, StorageKeys: access.StorageKeys})
+	}
+
+	return &out
+}
+
+// ReceiptToRoots copies out the fields of a receipt that the receipts root hashes
+func ReceiptToRoots(in *TransactionReceipt) *roots.Receipt {
+	out := roots.Receipt{
+		Type:              in.Type,
+		Root:              in.Root,
+		Status:            in.Status,
+		CumulativeGasUsed: in.CumulativeGasUsed,
+		LogsBloom:         in.LogsBloom,
+	}
+	for _, log := range in.Logs {
+		out.Logs = append(out.Logs, roots.Log{Address: log.Address, Topics: log.Topics, Data: log.Data})
+	}
+
+	return &out
+}
+
+// ParquetBlockToV2 converts a block from the hex schema to the typed schema
+func ParquetBlockToV2(in *model.ParquetBlock) (*model.ParquetBlockV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetBlockV2{
+		Number:                c.Int64("block_number", in.Number),
+		Hash:                  in.Hash,
+		ParentHash:            in.ParentHash,
+		Nonce:                 in.Nonce,
+		SHA3Uncles:            in.SHA3Uncles,
+		LogsBloom:             in.LogsBloom,
+		TransactionsRoot:      in.TransactionsRoot,
+		StateRoot:             in.StateRoot,
+		ReceiptsRoot:          in.ReceiptsRoot,
+		Miner:                 in.Miner,
+		Difficulty:            c.OptionalDecimal38("difficulty", in.Difficulty),
+		TotalDifficulty:       c.OptionalDecimal38("total_difficulty", in.TotalDifficulty),
+		ExtraData:             in.ExtraData,
+		Size:                  c.Int64("size", in.Size),
+		GasLimit:              c.Int64("gas_limit", in.GasLimit),
+		GasUsed:               c.Int64("gas_used", in.GasUsed),
+		Timestamp:             c.TimestampMillis("timestamp", in.Timestamp),
+		Uncles:                in.Uncles,
+		BaseFeePerGas:         c.OptionalDecimal38("base_fee_per_gas", in.BaseFeePerGas),
+		MixHash:               in.MixHash,
+		WithdrawalsRoot:       in.WithdrawalsRoot,
+		BlobGasUsed:           c.OptionalInt64("blob_gas_used", in.BlobGasUsed),
+		ExcessBlobGas:         c.OptionalInt64("excess_blob_gas", in.ExcessBlobGas),
+		ParentBeaconBlockRoot: in.ParentBeaconBlockRoot,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert block to typed schema")
+	}
 
-	return hex.EncodeToString(hasher.Sum(nil))
+	return &out, nil
+}
+
+// ParquetTransactionToV2 converts a transaction from the hex schema to the typed schema
+func ParquetTransactionToV2(in *model.ParquetTransaction) (*model.ParquetTransactionV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTransactionV2{
+		BlockNumber:          c.Int64("block_number", in.BlockNumber),
+		BlockHash:            in.BlockHash,
+		Hash:                 in.Hash,
+		From:                 in.From,
+		To:                   in.To,
+		Value:                c.Decimal38("value", in.Value),
+		Gas:                  c.Int64("gas", in.Gas),
+		GasPrice:             c.OptionalDecimal38("gas_price", in.GasPrice),
+		Input:                in.Input,
+		Type:                 c.OptionalInt64("type", in.Type),
+		Nonce:                c.Int64("nonce", in.Nonce),
+		TransactionIndex:     c.Int64("transaction_index", in.TransactionIndex),
+		V:                    in.V,
+		R:                    in.R,
+		S:                    in.S,
+		CumulativeGasUsed:    c.Int64("cumulative_gas_used", in.CumulativeGasUsed),
+		EffectiveGasPrice:    c.OptionalDecimal38("effective_gas_price", in.EffectiveGasPrice),
+		MaxFeePerGas:         c.OptionalDecimal38("max_fee_per_gas", in.MaxFeePerGas),
+		MaxPriorityFeePerGas: c.OptionalDecimal38("max_priority_fee_per_gas", in.MaxPriorityFeePerGas),
+		GasUsed:              c.Int64("gas_used", in.GasUsed),
+		LogsBloom:            in.LogsBloom,
+		Status:               c.OptionalInt64("status", in.Status),
+		AccessList:           in.AccessList,
+		MaxFeePerBlobGas:     c.OptionalDecimal38("max_fee_per_blob_gas", in.MaxFeePerBlobGas),
+		BlobVersionedHashes:  in.BlobVersionedHashes,
+		BlobGasPrice:         c.OptionalDecimal38("blob_gas_price", in.BlobGasPrice),
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert transaction to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetLogToV2 converts a log from the hex schema to the typed schema
+func ParquetLogToV2(in *model.ParquetLog) (*model.ParquetLogV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetLogV2{
+		BlockNumber:      c.Int64("block_number", in.BlockNumber),
+		BlockHash:        in.BlockHash,
+		TransactionHash:  in.TransactionHash,
+		TransactionIndex: c.Int64("transaction_index", in.TransactionIndex),
+		LogIndex:         c.Int64("log_index", in.LogIndex),
+		Address:          in.Address,
+		Data:             in.Data,
+		Topics:           in.Topics,
+		Removed:          in.Removed,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert log to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetTraceToV2 converts a trace from the hex schema to the typed schema
+func ParquetTraceToV2(in *model.ParquetTrace) (*model.ParquetTraceV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTraceV2{
+		BlockNumber:     c.Int64("block_number", in.BlockNumber),
+		BlockHash:       in.BlockHash,
+		TransactionHash: in.TransactionHash,
+		Hash:            in.Hash,
+		ParentHash:      in.ParentHash,
+		Index:           in.Index,
+		TraceAddress:    in.TraceAddress,
+		Depth:           in.Depth,
+		Subtraces:       in.Subtraces,
+		Type:            in.Type,
+		From:            in.From,
+		To:              in.To,
+		Value:           c.OptionalDecimal38("value", in.Value),
+		Gas:             c.OptionalInt64("gas", in.Gas),
+		GasUsed:         c.OptionalInt64("gas_used", in.GasUsed),
+		Input:           in.Input,
+		Output:          in.Output,
+		Error:           in.Error,
+		RevertReason:    in.RevertReason,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert trace to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetWithdrawalToV2 converts a withdrawal from the hex schema to the typed schema
+func ParquetWithdrawalToV2(in *model.ParquetWithdrawal) (*model.ParquetWithdrawalV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetWithdrawalV2{
+		BlockNumber:    c.Int64("block_number", in.BlockNumber),
+		Index:          c.Int64("index", in.Index),
+		ValidatorIndex: c.Int64("validator_index", in.ValidatorIndex),
+		Address:        in.Address,
+		Amount:         c.Int64("amount", in.Amount),
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert withdrawal to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetBlobToV2 converts a blob from the hex schema to the typed schema
+func ParquetBlobToV2(in *model.ParquetBlob) (*model.ParquetBlobV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetBlobV2{
+		BlockNumber:      c.Int64("block_number", in.BlockNumber),
+		BlockHash:        in.BlockHash,
+		TransactionHash:  in.TransactionHash,
+		TransactionIndex: c.Int64("transaction_index", in.TransactionIndex),
+		Index:            in.Index,
+		VersionedHash:    in.VersionedHash,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert blob to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetBeaconBlockToV2 converts a beacon block from the hex schema to the typed schema
+func ParquetBeaconBlockToV2(in *model.ParquetBeaconBlock) (*model.ParquetBeaconBlockV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetBeaconBlockV2{
+		BlockNumber:   c.Int64("block_number", in.BlockNumber),
+		BlockHash:     in.BlockHash,
+		Slot:          in.Slot,
+		Root:          in.Root,
+		ParentRoot:    in.ParentRoot,
+		StateRoot:     in.StateRoot,
+		ProposerIndex: in.ProposerIndex,
+		Graffiti:      in.Graffiti,
+		BlobCount:     in.BlobCount,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert beacon block to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetBlobSidecarToV2 converts a blob sidecar from the hex schema to the typed schema
+func ParquetBlobSidecarToV2(in *model.ParquetBlobSidecar) (*model.ParquetBlobSidecarV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetBlobSidecarV2{
+		BlockNumber:   c.Int64("block_number", in.BlockNumber),
+		BlockHash:     in.BlockHash,
+		Slot:          in.Slot,
+		Root:          in.Root,
+		Index:         in.Index,
+		KzgCommitment: in.KzgCommitment,
+		KzgProof:      in.KzgProof,
+		VersionedHash: in.VersionedHash,
+		Size:          in.Size,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert blob sidecar to typed schema")
+	}
+
+	return &out, nil
 }
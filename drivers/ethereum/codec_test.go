@@ -0,0 +1,54 @@
+package ethereum
+
+import (
+	"testing"
+)
+
+func TestTransactionToParquetFees(t *testing.T) {
+	tests := []struct {
+		name            string
+		tx              *Transaction
+		wantMaxFee      string
+		wantPriorityFee string
+		wantBlobFee     string
+	}{
+		{
+			name: "legacy",
+			tx:   &Transaction{Type: "0x0", GasPrice: "0x64"},
+		},
+		{
+			name:            "dynamic fee",
+			tx:              &Transaction{Type: "0x2", GasPrice: "0x64", MaxFeePerGas: "0xc8", MaxPriorityFeePerGas: "0x2"},
+			wantMaxFee:      "0xc8",
+			wantPriorityFee: "0x2",
+		},
+		{
+			name:            "blob",
+			tx:              &Transaction{Type: "0x3", GasPrice: "0x64", MaxFeePerGas: "0x12c", MaxPriorityFeePerGas: "0x3", MaxFeePerBlobGas: "0x1"},
+			wantMaxFee:      "0x12c",
+			wantPriorityFee: "0x3",
+			wantBlobFee:     "0x1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := TransactionToParquet(tt.tx, &TransactionReceipt{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.MaxFeePerGas != tt.wantMaxFee {
+				t.Errorf("max_fee_per_gas = %q, want %q", out.MaxFeePerGas, tt.wantMaxFee)
+			}
+			if out.MaxPriorityFeePerGas != tt.wantPriorityFee {
+				t.Errorf("max_priority_fee_per_gas = %q, want %q", out.MaxPriorityFeePerGas, tt.wantPriorityFee)
+			}
+			if out.MaxFeePerBlobGas != tt.wantBlobFee {
+				t.Errorf("max_fee_per_blob_gas = %q, want %q", out.MaxFeePerBlobGas, tt.wantBlobFee)
+			}
+			if out.GasPrice != tt.tx.GasPrice {
+				t.Errorf("gas_price = %q, want %q", out.GasPrice, tt.tx.GasPrice)
+			}
+		})
+	}
+}
@@ -1,37 +1,76 @@
+// Package ethereum indexes Ethereum mainnet. The chain-interactor protos predate Dencun and reject the fields it added,
+// so the node's JSON-RPC responses are decoded into plain structs that keep the protos' field names.
 package ethereum
 
 import (
 	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/ethereum"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
 	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
 	"github.com/coherentopensource/go-service-framework/constants"
-	"github.com/coherentopensource/go-service-framework/util"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
 )
 
-const (
-	stageFetchBlock   = "fetch.block"
-	stageFetchReceipt = "fetch.receipt"
-	stageFetchTraces  = "fetch.traces"
-)
+// Driver is the container for all ETL business logic
+type Driver = evm.Driver[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace]
+
+// Data is a block together with its receipts and call traces, as handed to the writers
+type Data = evm.Data[*Block, *TransactionReceipt, *CallTrace]
+
+// EthereumDriver is the name Driver went by before the drivers shared a core
+//
+// Deprecated: use Driver
+type EthereumDriver = Driver
+
+// chain plugs the Ethereum node types and codec into the shared EVM driver
+var chain = &evm.Chain[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace]{
+	Blockchain:  constants.Ethereum,
+	Finality:    evm.FinalityFinalized,
+	Withdrawals: NativeWithdrawals,
 
-// EthereumDriver is the container for all ETL business logic
-type EthereumDriver struct {
-	store      *store
-	nodeClient *client
-	logger     util.Logger
-	config     *Config
+	BlockToParquet: func(block *Block) interface{} {
+		return BlockToParquet(block)
+	},
+	TransactionToParquet: func(tx *Transaction, receipt *TransactionReceipt) (interface{}, error) {
+		return TransactionToParquet(tx, receipt)
+	},
+	LogToParquet: func(log *Log) interface{} {
+		return LogToParquet(log)
+	},
+	TraceToParquet: func(frame trace.Frame[*CallTrace], tx *Transaction) interface{} {
+		return TraceToParquet(frame, tx)
+	},
+	Roots:           BlockRoots,
+	RootTransaction: TransactionToRoots,
+	RootReceipt:     ReceiptToRoots,
+
+	Entities: []evm.Entity[*Data]{
+		{Name: evm.EntityBlocks, Model: new(model.ParquetBlock), ModelV2: new(model.ParquetBlockV2), ToV2: evm.Typed(ParquetBlockToV2)},
+		{Name: evm.EntityTransactions, Model: new(model.ParquetTransaction), ModelV2: new(model.ParquetTransactionV2), ToV2: evm.Typed(ParquetTransactionToV2)},
+		{Name: evm.EntityLogs, Model: new(model.ParquetLog), ModelV2: new(model.ParquetLogV2), ToV2: evm.Typed(ParquetLogToV2)},
+		{Name: evm.EntityTraces, Model: new(model.ParquetTrace), ModelV2: new(model.ParquetTraceV2), ToV2: evm.Typed(ParquetTraceToV2)},
+		{Name: entityWithdrawals, Model: new(model.ParquetWithdrawal), ModelV2: new(model.ParquetWithdrawalV2), ToV2: evm.Typed(ParquetWithdrawalToV2), Rows: withdrawalRows},
+		{Name: entityBlobs, Model: new(model.ParquetBlob), ModelV2: new(model.ParquetBlobV2), ToV2: evm.Typed(ParquetBlobToV2), Rows: blobRows},
+	},
 }
 
-// New constructs a new EthereumDriver
-func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger util.Logger) *EthereumDriver {
-	return &EthereumDriver{
-		nodeClient: &client{innerClient: nodeClient},
-		store:      &store{innerStore: innerStore},
-		logger:     logger,
-		config:     cfg,
-	}
+// Entities maps each entity directory to the parquet model its files are written with, for tools that read the output
+// back generically; it includes the beacon chain entities, which only a driver constructed by NewWithBeacon writes
+var Entities = withBeacon(chain, nil).Models(util.SchemaVersionHex)
+
+// EntitiesV2 is Entities for the typed parquet schema
+var EntitiesV2 = withBeacon(chain, nil).Models(util.SchemaVersionTyped)
+
+// New constructs a new Driver
+func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	return evm.New(chain, cfg, nodeClient, innerStore, logger)
 }
 
-// Blockchain returns the name of the blockchain
-func (e *EthereumDriver) Blockchain() string {
-	return string(constants.Ethereum)
+// NewWithBeacon constructs a new Driver that also writes the beacon block behind every post-Merge block, and its blob
+// sidecars, read from a beacon node
+func NewWithBeacon(cfg *Config, nodeClient nodeClient.Client, beaconClient *beacon.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	return evm.New(withBeacon(chain, beaconClient), cfg, nodeClient, innerStore, logger)
 }
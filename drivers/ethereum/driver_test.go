@@ -0,0 +1,177 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/ethereum"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+// countingTransport stands in for a beacon node, recording the paths requested of it
+type countingTransport struct {
+	inner beacon.Transport
+	paths []string
+}
+
+func (t *countingTransport) Get(ctx context.Context, path string) ([]byte, error) {
+	t.paths = append(t.paths, path)
+	return t.inner.Get(ctx, path)
+}
+
+// writeHeight runs a height through the driver as the poller would: fetch, accumulate, then every writer
+func writeHeight(ctx context.Context, d *Driver, height uint64) error {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		res, err := runner(ctx)
+		if err != nil {
+			return err
+		}
+		set[stage] = res
+	}
+	data, err := d.Accumulate(set)(ctx)
+	if err != nil {
+		return err
+	}
+	for _, writer := range d.Writers() {
+		if _, err := writer(data)(ctx); err != nil {
+			return err
+		}
+	}
+	return d.Flush(ctx)
+}
+
+// readEntity reads back every row written for an entity
+func readEntity(ctx context.Context, store *storage.MemoryConnector, entity string, mapToStruct interface{}) ([]interface{}, error) {
+	var rows []interface{}
+	for _, file := range store.FilesWithPrefix(entity + "/") {
+		fileRows, err := storage.ReadAll(ctx, store, file, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fileRows...)
+	}
+	return rows, nil
+}
+
+func TestDriverWritesFixtures(t *testing.T) {
+	tests := []struct {
+		name      string
+		dir       string
+		height    uint64
+		wantRows  map[string]int
+		wantPaths []string
+		// wantBlobs are the versioned hashes of the block's blobs, named by its transactions and its blob sidecars alike
+		wantBlobs []string
+	}{
+		{
+			name:   "dencun block with blob sidecars",
+			dir:    "dencun",
+			height: 19426587,
+			wantRows: map[string]int{
+				evm.EntityBlocks:       1,
+				evm.EntityTransactions: 1,
+				evm.EntityTraces:       1,
+				entityWithdrawals:      1,
+				entityBlobs:            2,
+				entityBeaconBlocks:     1,
+				entityBlobSidecars:     2,
+			},
+			wantPaths: []string{
+				"/eth/v1/beacon/genesis",
+				"/eth/v1/beacon/headers/8626176",
+				"/eth/v2/beacon/blocks/0x8888888888888888888888888888888888888888888888888888888888888888",
+				"/eth/v1/beacon/blob_sidecars/0x8888888888888888888888888888888888888888888888888888888888888888",
+			},
+			wantBlobs: []string{
+				"0x01659f8a49133759d495ee5d15262cdc0050f9027e20c7bed3e0599e27adec4b",
+				"0x018fc9d98c32189fe8232b46db86446b16895b4e2a911803d4b9c1d229838914",
+			},
+		},
+		{
+			//	A proof of work block was carried by no beacon block, so the beacon node is never asked for one
+			name:   "pre-merge block",
+			dir:    "premerge",
+			height: 15537393,
+			wantRows: map[string]int{
+				evm.EntityBlocks:       1,
+				evm.EntityTransactions: 1,
+				evm.EntityTraces:       1,
+				entityWithdrawals:      0,
+				entityBlobs:            0,
+				entityBeaconBlocks:     0,
+				entityBlobSidecars:     0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := filepath.Join("testdata", tt.dir)
+			store := storage.NewMemoryConnector(10000)
+			transport := &countingTransport{inner: fixture.NewBeaconTransport(dir)}
+			beaconClient := beacon.NewClient(&beacon.Config{SecondsPerSlot: 12}, transport)
+			cfg := Config{MaxRetries: 1, DirectoryRange: 10000, WriteMode: storage.WriteModeBlock, SchemaVersion: util.SchemaVersionHex}
+			d := NewWithBeacon(&cfg, fixture.NewClient(dir), beaconClient, store, testLogger{})
+
+			if err := writeHeight(ctx, d, tt.height); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(transport.paths, tt.wantPaths) {
+				t.Errorf("beacon requests = %v, want %v", transport.paths, tt.wantPaths)
+			}
+			for entity, want := range tt.wantRows {
+				rows, err := readEntity(ctx, store, entity, Entities[entity])
+				if err != nil {
+					t.Fatalf("read %s: %v", entity, err)
+				}
+				if len(rows) != want {
+					t.Errorf("%s has %d rows, want %d", entity, len(rows), want)
+				}
+			}
+
+			blobs, err := readEntity(ctx, store, entityBlobs, new(model.ParquetBlob))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sidecars, err := readEntity(ctx, store, entityBlobSidecars, new(model.ParquetBlobSidecar))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(blobs) != len(tt.wantBlobs) || len(sidecars) != len(tt.wantBlobs) {
+				t.Fatalf("got %d blobs and %d blob sidecars, want %d", len(blobs), len(sidecars), len(tt.wantBlobs))
+			}
+			for i, want := range tt.wantBlobs {
+				if got := blobs[i].(*model.ParquetBlob).VersionedHash; got != want {
+					t.Errorf("blob %d has versioned hash %s, want %s", i, got, want)
+				}
+				if got := sidecars[i].(*model.ParquetBlobSidecar).VersionedHash; got != want {
+					t.Errorf("blob sidecar %d has versioned hash %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
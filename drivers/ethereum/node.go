@@ -0,0 +1,124 @@
+package ethereum
+
+import (
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/coherentopensource/evm-etl/shared/rpc"
+)
+
+// TxTypeBlob is the EIP-4844 transaction type, which carries blobs on top of the dynamic fee (0x2) fields
+const TxTypeBlob = "0x3"
+
+// Block is an Ethereum block. The withdrawal fields are empty before Shapella, and the blob gas and beacon root fields
+// before Dencun. Beacon is not part of eth_getBlockByNumber, and is filled in by completeBeaconBlock when the driver
+// has a beacon node
+type Block struct {
+	Number                string               `json:"number"`
+	Hash                  string               `json:"hash"`
+	ParentHash            string               `json:"parentHash"`
+	Nonce                 string               `json:"nonce"`
+	Sha3Uncles            string               `json:"sha3Uncles"`
+	LogsBloom             string               `json:"logsBloom"`
+	TransactionsRoot      string               `json:"transactionsRoot"`
+	StateRoot             string               `json:"stateRoot"`
+	ReceiptsRoot          string               `json:"receiptsRoot"`
+	Miner                 string               `json:"miner"`
+	Difficulty            string               `json:"difficulty"`
+	TotalDifficulty       string               `json:"totalDifficulty"`
+	ExtraData             string               `json:"extraData"`
+	Size                  string               `json:"size"`
+	GasLimit              string               `json:"gasLimit"`
+	GasUsed               string               `json:"gasUsed"`
+	Timestamp             string               `json:"timestamp"`
+	Transactions          []*Transaction       `json:"transactions"`
+	Uncles                []string             `json:"uncles"`
+	BaseFeePerGas         string               `json:"baseFeePerGas"`
+	MixHash               string               `json:"mixHash"`
+	WithdrawalsRoot       string               `json:"withdrawalsRoot"`
+	Withdrawals           []*Withdrawal        `json:"withdrawals"`
+	BlobGasUsed           string               `json:"blobGasUsed"`
+	ExcessBlobGas         string               `json:"excessBlobGas"`
+	ParentBeaconBlockRoot string               `json:"parentBeaconBlockRoot"`
+	Beacon                *beacon.PayloadBlock `json:"-"`
+}
+
+func (x *Block) GetNumber() string               { return x.Number }
+func (x *Block) GetHash() string                 { return x.Hash }
+func (x *Block) GetParentHash() string           { return x.ParentHash }
+func (x *Block) GetTransactions() []*Transaction { return x.Transactions }
+
+// PostMerge reports whether a block was sealed by proof of stake, and so carried by a beacon block
+func (x *Block) PostMerge() bool {
+	return x.Difficulty == postMergeDifficulty
+}
+
+// Transaction is an Ethereum transaction; MaxFeePerBlobGas and BlobVersionedHashes are only set for TxTypeBlob
+type Transaction struct {
+	BlockHash            string    `json:"blockHash"`
+	BlockNumber          string    `json:"blockNumber"`
+	From                 string    `json:"from"`
+	Gas                  string    `json:"gas"`
+	GasPrice             string    `json:"gasPrice"`
+	Hash                 string    `json:"hash"`
+	Input                string    `json:"input"`
+	Nonce                string    `json:"nonce"`
+	To                   string    `json:"to"`
+	TransactionIndex     string    `json:"transactionIndex"`
+	Value                string    `json:"value"`
+	V                    string    `json:"v"`
+	R                    string    `json:"r"`
+	S                    string    `json:"s"`
+	AccessList           []*Access `json:"accessList"`
+	ChainId              string    `json:"chainId"`
+	MaxFeePerGas         string    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string    `json:"maxPriorityFeePerGas"`
+	Type                 string    `json:"type"`
+	MaxFeePerBlobGas     string    `json:"maxFeePerBlobGas"`
+	BlobVersionedHashes  []string  `json:"blobVersionedHashes"`
+}
+
+func (x *Transaction) GetHash() string      { return x.Hash }
+func (x *Transaction) GetBlockHash() string { return x.BlockHash }
+func (x *Transaction) GetFrom() string      { return x.From }
+func (x *Transaction) GetTo() string        { return x.To }
+func (x *Transaction) GetValue() string     { return x.Value }
+
+// Access is an entry of a transaction's access list
+type Access = rpc.Access
+
+// Withdrawal is a withdrawal from the beacon chain, credited to an execution layer address
+type Withdrawal struct {
+	Index          string `json:"index"`
+	ValidatorIndex string `json:"validatorIndex"`
+	Address        string `json:"address"`
+	Amount         string `json:"amount"`
+}
+
+// TransactionReceipt is an Ethereum transaction receipt; BlobGasPrice is only set for TxTypeBlob
+type TransactionReceipt struct {
+	TransactionHash   string `json:"transactionHash"`
+	TransactionIndex  string `json:"transactionIndex"`
+	BlockHash         string `json:"blockHash"`
+	BlockNumber       string `json:"blockNumber"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	CumulativeGasUsed string `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+	GasUsed           string `json:"gasUsed"`
+	ContractAddress   string `json:"contractAddress"`
+	Logs              []*Log `json:"logs"`
+	LogsBloom         string `json:"logsBloom"`
+	Type              string `json:"type"`
+	Root              string `json:"root"`
+	Status            string `json:"status"`
+	BlobGasPrice      string `json:"blobGasPrice"`
+}
+
+func (x *TransactionReceipt) GetContractAddress() string { return x.ContractAddress }
+func (x *TransactionReceipt) GetStatus() string          { return x.Status }
+func (x *TransactionReceipt) GetLogs() []*Log            { return x.Logs }
+
+// Log is an Ethereum log
+type Log = rpc.Log
+
+// CallTrace is a call frame as returned by geth's callTracer
+type CallTrace = rpc.CallTrace
@@ -0,0 +1,88 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"github.com/coherentopensource/go-service-framework/pool"
+)
+
+// Accumulate combines a block, receipts, and traces from multiple protos into a single object, given a generic
+// result from the "fetch" step. With VERIFY_ROOTS, the block and receipts are then checked against the roots in the
+// block's header
+func (d *Driver[B, Tx, R, L, T]) Accumulate(res interface{}) pool.Runner {
+	return func(ctx context.Context) (interface{}, error) {
+		set, ok := res.(pool.ResultSet)
+		if !ok {
+			return nil, errors.New("result is not expected type")
+		}
+
+		block, receipts, err := d.extractBlockAndReceipts(set)
+		if err != nil {
+			return nil, err
+		}
+		var traces []T
+		if !d.chain.NoTraces {
+			if traces, err = extractTraces[T](set); err != nil {
+				return nil, err
+			}
+		}
+		if d.config.VerifyRoots {
+			if block, receipts, traces, err = d.verifiedBlockData(ctx, block, receipts, traces); err != nil {
+				return nil, err
+			}
+		}
+
+		return &Data[B, R, T]{
+			Block:               block,
+			TransactionReceipts: receipts,
+			CallTraces:          traces,
+		}, nil
+	}
+}
+
+// extractBlockAndReceipts extracts a block and its receipts from the generic ResultSet from the fetch step, where
+// they were fetched either together or as separate steps
+func (d *Driver[B, Tx, R, L, T]) extractBlockAndReceipts(set pool.ResultSet) (B, []R, error) {
+	var zero B
+	blockRes, ok := set[stageFetchBlock]
+	if !ok {
+		return zero, nil, errors.New("no block data")
+	}
+
+	if d.chain.ReceiptsPerTransaction {
+		wrapper, ok := blockRes.(*blockAndReceiptWrapper[B, R])
+		if !ok {
+			return zero, nil, errors.New("incorrect data type for block/receipt wrapper")
+		}
+		return wrapper.block, wrapper.receipts, nil
+	}
+
+	block, ok := blockRes.(B)
+	if !ok {
+		return zero, nil, errors.New("incorrect data type for block")
+	}
+	receiptsRes, ok := set[stageFetchReceipt]
+	if !ok {
+		return zero, nil, errors.New("no receipts data")
+	}
+	receipts, ok := receiptsRes.([]R)
+	if !ok {
+		return zero, nil, errors.New("incorrect data type for transaction receipts")
+	}
+
+	return block, receipts, nil
+}
+
+// extractTraces extracts traces from the generic ResultSet from the fetch step
+func extractTraces[T any](set pool.ResultSet) ([]T, error) {
+	tracesRes, ok := set[stageFetchTraces]
+	if !ok {
+		return nil, errors.New("no traces data")
+	}
+	traces, ok := tracesRes.([]T)
+	if !ok {
+		return nil, errors.New("incorrect data type for traces")
+	}
+
+	return traces, nil
+}
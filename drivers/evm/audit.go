@@ -0,0 +1,26 @@
+package evm
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/shared/audit"
+)
+
+// Expect fetches a block and its receipts from the node and returns what its files should hold, for audit.Auditor;
+// traces are not fetched, as only whether the block should have them matters
+func (d *Driver[B, Tx, R, L, T]) Expect(ctx context.Context, height uint64) (*audit.Expectation, error) {
+	block, receipts, err := d.fetchBlockAndReceipts(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := block.GetTransactions()
+	expectation := &audit.Expectation{
+		Transactions: int64(len(transactions)),
+		//	Transactions between null addresses are never traced
+		Traces: !d.chain.NoTraces && len(filterNonTraceTransactions(transactions)) > 0,
+	}
+	for _, receipt := range receipts {
+		expectation.Logs += int64(len(receipt.GetLogs()))
+	}
+	return expectation, nil
+}
@@ -0,0 +1,116 @@
+package evm
+
+import (
+	"context"
+	model "github.com/coherentopensource/evm-etl/model/evm"
+	"github.com/coherentopensource/evm-etl/shared/roots"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/constants"
+	"github.com/pkg/errors"
+)
+
+// Entities name the top-level directory each writer outputs to
+const (
+	EntityBlocks       = "blocks"
+	EntityTransactions = "transactions"
+	EntityLogs         = "logs"
+	EntityTraces       = "traces"
+	// The entities below share their models between chains, so chains do not list them among their Entities
+	EntityContracts       = "contracts"
+	EntityNativeTransfers = "native_transfers"
+	EntityTokenTransfers  = "token_transfers"
+	EntityDecodedEvents   = "decoded_events"
+	EntityDecodedCalls    = "decoded_calls"
+)
+
+// Chain describes everything that sets one EVM chain apart from the others
+type Chain[B Block[Tx], Tx Transaction, R Receipt[L], L Log, T Trace[T]] struct {
+	Blockchain constants.Blockchain
+
+	// ReceiptsPerTransaction fetches receipts one transaction at a time, for nodes without eth_getBlockReceipts
+	ReceiptsPerTransaction bool
+	// RequireTransactions refetches blocks without transactions, for chains where every block carries one
+	RequireTransactions bool
+	// IgnoredTraceErrors are node errors on which a block is written without traces rather than retried
+	IgnoredTraceErrors []string
+	// Finality is the chain's default finality mode; see ParseFinality
+	Finality string
+	// NoTraces neither fetches nor writes traces, for nodes without debug_traceBlockByNumber
+	NoTraces bool
+	// DiscardUnknownFields drops JSON-RPC fields the protos do not know, for chains that reuse another chain's protos
+	DiscardUnknownFields bool
+	// CompleteBlock fills in what eth_getBlockByNumber leaves out of a block, from the chain's own RPC methods
+	CompleteBlock func(ctx context.Context, block B) error
+	// Withdrawals lists the beacon chain withdrawals a block credits, for native transfers
+	Withdrawals func(block B) []Withdrawal
+	// Roots, RootTransaction and RootReceipt read what VERIFY_ROOTS hashes; unverifiable chains leave them unset
+	Roots           func(block B) (transactionsRoot string, receiptsRoot string)
+	RootTransaction func(tx Tx) *roots.Transaction
+	RootReceipt     func(receipt R) *roots.Receipt
+
+	// BlockToParquet, TransactionToParquet, LogToParquet and TraceToParquet convert node types to hex schema rows
+	BlockToParquet       func(block B) interface{}
+	TransactionToParquet func(tx Tx, receipt R) (interface{}, error)
+	LogToParquet         func(log L) interface{}
+	TraceToParquet       func(frame trace.Frame[T], tx Tx) interface{}
+
+	// Entities lists the directories the chain writes to, but for those whose models the core shares
+	Entities []Entity[*Data[B, R, T]]
+}
+
+// Withdrawal is a beacon chain withdrawal read off a block, with its amount in Gwei
+type Withdrawal struct {
+	Index   string
+	Address string
+	Amount  string
+}
+
+// Entity describes the files written to a single top-level directory
+type Entity[D any] struct {
+	Name string
+	// Model and ModelV2 are the parquet models of the hex and typed schema versions
+	Model   interface{}
+	ModelV2 interface{}
+	// ToV2 converts a row of Model into a row of ModelV2; see Typed
+	ToV2 func(row interface{}) (interface{}, error)
+	// Rows extracts an extra entity's rows from an accumulated block; the core entities leave it unset
+	Rows func(data D) ([]interface{}, error)
+}
+
+// Models maps each of the chain's entity directories to its parquet model in a schema version
+func (c *Chain[B, Tx, R, L, T]) Models(schemaVersion int) map[string]interface{} {
+	entities := c.entities()
+	models := make(map[string]interface{}, len(entities))
+	for _, entity := range entities {
+		if schemaVersion == util.SchemaVersionTyped {
+			models[entity.Name] = entity.ModelV2
+		} else {
+			models[entity.Name] = entity.Model
+		}
+	}
+	return models
+}
+
+// entities lists the chain's entities along with the ones whose models the core shares between every chain
+func (c *Chain[B, Tx, R, L, T]) entities() []Entity[*Data[B, R, T]] {
+	entities := append([]Entity[*Data[B, R, T]]{}, c.Entities...)
+	return append(entities,
+		Entity[*Data[B, R, T]]{Name: EntityContracts, Model: new(model.ParquetContract), ModelV2: new(model.ParquetContractV2), ToV2: Typed(ParquetContractToV2)},
+		Entity[*Data[B, R, T]]{Name: EntityNativeTransfers, Model: new(model.ParquetNativeTransfer), ModelV2: new(model.ParquetNativeTransferV2), ToV2: Typed(ParquetNativeTransferToV2)},
+		Entity[*Data[B, R, T]]{Name: EntityTokenTransfers, Model: new(model.ParquetTokenTransfer), ModelV2: new(model.ParquetTokenTransferV2), ToV2: Typed(ParquetTokenTransferToV2)},
+		Entity[*Data[B, R, T]]{Name: EntityDecodedEvents, Model: new(model.ParquetDecodedEvent), ModelV2: new(model.ParquetDecodedEventV2), ToV2: Typed(ParquetDecodedEventToV2)},
+		Entity[*Data[B, R, T]]{Name: EntityDecodedCalls, Model: new(model.ParquetDecodedCall), ModelV2: new(model.ParquetDecodedCallV2), ToV2: Typed(ParquetDecodedCallToV2)},
+	)
+}
+
+// Typed adapts a chain's hex-to-typed row conversion, e.g. ParquetBlockToV2, to the signature of Entity.ToV2
+func Typed[In any, Out any](convert func(In) (Out, error)) func(row interface{}) (interface{}, error) {
+	return func(row interface{}) (interface{}, error) {
+		in, ok := row.(In)
+		if !ok {
+			return nil, errors.Errorf("no typed schema for %T", row)
+		}
+		return convert(in)
+	}
+}
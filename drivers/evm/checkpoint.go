@@ -0,0 +1,67 @@
+package evm
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/shared/checkpoint"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"github.com/pkg/errors"
+)
+
+// errNoCheckpoints is returned by the checkpoint queries of a driver that keeps no checkpoints
+var errNoCheckpoints = errors.New("driver keeps no checkpoints; set CHECKPOINT_BACKEND")
+
+// NextHeight returns the lowest height from a height on that the writers have not all written, for resuming after a
+// crash
+func (d *Driver[B, Tx, R, L, T]) NextHeight(ctx context.Context, from uint64) (uint64, error) {
+	if d.store.checkpoints == nil {
+		return 0, errNoCheckpoints
+	}
+	return d.store.checkpoints.NextHeight(ctx, from, d.WrittenEntities())
+}
+
+// Gaps lists the heights from..to that the writers have not all written, along with the entities each lacks
+func (d *Driver[B, Tx, R, L, T]) Gaps(ctx context.Context, from uint64, to uint64) ([]checkpoint.Gap, error) {
+	if d.store.checkpoints == nil {
+		return nil, errNoCheckpoints
+	}
+	return d.store.checkpoints.Gaps(ctx, from, to, d.WrittenEntities())
+}
+
+// Backfill fetches every height of a gap from the node again and writes the entities it lacks; the entities it has
+// are left as they are, so that batched files are not written twice over. Flush must be called once backfilling is
+// done
+func (d *Driver[B, Tx, R, L, T]) Backfill(ctx context.Context, gap checkpoint.Gap) error {
+	missing := make(map[string]bool, len(gap.Missing))
+	for _, entity := range gap.Missing {
+		missing[entity] = true
+	}
+	var writers []pool.FeedTransformer
+	for _, w := range d.entityWriters() {
+		if missing[w.entity] {
+			writers = append(writers, d.writeStep(w))
+		}
+	}
+
+	for height := gap.From; height <= gap.To; height++ {
+		data, err := d.fetchHeight(ctx, height)
+		if err != nil {
+			return errors.Wrapf(err, "cannot fetch block %d", height)
+		}
+		for _, writer := range writers {
+			if _, err := writer(data)(ctx); err != nil {
+				return errors.Wrapf(err, "cannot backfill block %d", height)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WrittenEntities lists the entities the writers write for every height, all of which a height needs to be complete
+func (d *Driver[B, Tx, R, L, T]) WrittenEntities() []string {
+	var entities []string
+	for _, w := range d.entityWriters() {
+		entities = append(entities, w.entity)
+	}
+	return entities
+}
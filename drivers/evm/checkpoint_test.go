@@ -0,0 +1,147 @@
+package evm_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/coherentopensource/evm-etl/shared/checkpoint"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"path"
+	"reflect"
+	"testing"
+)
+
+// ingestPartly writes a height with only the first of the driver's writers, as when a crash interrupts the rest
+func ingestPartly(ctx context.Context, d *ethereum.Driver, height uint64) error {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		res, err := runner(ctx)
+		if err != nil {
+			return err
+		}
+		set[stage] = res
+	}
+	data, err := d.Accumulate(set)(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := d.Writers()[0](data)(ctx); err != nil {
+		return err
+	}
+	return d.Flush(ctx)
+}
+
+// countRows counts the rows an entity has for each height, from the field of its rows that holds the height
+func countRows(ctx context.Context, store *storage.MemoryConnector, dir string, mapToStruct interface{}, numberField string) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	for _, file := range store.FilesWithPrefix(dir + "/") {
+		rows, err := storage.ReadAll(ctx, store, file, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			var height int64
+			if _, err := fmt.Sscanf(reflect.ValueOf(row).Elem().FieldByName(numberField).String(), "0x%x", &height); err != nil {
+				return nil, err
+			}
+			counts[height]++
+		}
+	}
+	return counts, nil
+}
+
+func TestCheckpointsBackfillGaps(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  evm.Config
+	}{
+		{name: "file", cfg: evm.Config{WriteMode: storage.WriteModeBlock, CheckpointBackend: checkpoint.BackendFile}},
+		{name: "manifest", cfg: evm.Config{WriteMode: storage.WriteModeBlock, CheckpointBackend: checkpoint.BackendManifest}},
+		{name: "manifest in batch mode", cfg: evm.Config{WriteMode: storage.WriteModeBatch, BatchSize: 2, CheckpointBackend: checkpoint.BackendManifest}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			writeFork(t, dir, "a", map[uint64]string{4: "a", 5: "a", 6: "a", 7: "a"})
+
+			store := storage.NewMemoryConnector(10000)
+			cfg := tt.cfg
+			cfg.MaxRetries, cfg.DirectoryRange, cfg.SchemaVersion, cfg.MaxReorgDepth = 1, 10000, 1, 64
+			cfg.CheckpointPath, cfg.CheckpointFlushSize = path.Join(dir, "checkpoints"), 1000
+			d := ethereum.New(&cfg, &forkClient{fixture.NewClient(dir)}, store, nopLogger{})
+
+			//	Height 6 is written by its first writer alone, and 7 not at all
+			if err := ingest(ctx, d, 4, 5); err != nil {
+				t.Fatal(err)
+			}
+			if err := ingestPartly(ctx, d, 6); err != nil {
+				t.Fatal(err)
+			}
+
+			entities := d.WrittenEntities()
+			next, err := d.NextHeight(ctx, 4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next != 6 {
+				t.Errorf("next height = %d, want 6", next)
+			}
+			gaps, err := d.Gaps(ctx, 4, 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantGaps := []checkpoint.Gap{{From: 6, To: 6, Missing: entities[1:]}, {From: 7, To: 7, Missing: entities}}
+			if !reflect.DeepEqual(gaps, wantGaps) {
+				t.Fatalf("gaps = %+v, want %+v", gaps, wantGaps)
+			}
+
+			for _, gap := range gaps {
+				if err := d.Backfill(ctx, gap); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := d.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if gaps, err := d.Gaps(ctx, 4, 7); err != nil || len(gaps) != 0 {
+				t.Errorf("gaps after backfill = %+v, %v", gaps, err)
+			}
+			if next, err := d.NextHeight(ctx, 4); err != nil || next != 8 {
+				t.Errorf("next height after backfill = %d, %v; want 8", next, err)
+			}
+			//	Every height has a block and a transaction, and the block height 6 already had was not written again
+			for _, entity := range []struct {
+				name        string
+				numberField string
+			}{
+				{evm.EntityBlocks, "Number"},
+				{evm.EntityTransactions, "BlockNumber"},
+			} {
+				got, err := countRows(ctx, store, entity.name, ethereum.Entities[entity.name], entity.numberField)
+				if err != nil {
+					t.Fatalf("read %s: %v", entity.name, err)
+				}
+				if want := map[int64]int{4: 1, 5: 1, 6: 1, 7: 1}; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s rows per block = %v, want %v", entity.name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckpointsNeedABackend(t *testing.T) {
+	cfg := evm.Config{WriteMode: storage.WriteModeBlock, MaxRetries: 1, DirectoryRange: 10000, SchemaVersion: 1}
+	d := ethereum.New(&cfg, &forkClient{fixture.NewClient(t.TempDir())}, storage.NewMemoryConnector(10000), nopLogger{})
+	if _, err := d.Gaps(context.Background(), 0, 10); err == nil {
+		t.Error("listed gaps without a checkpoint backend")
+	}
+	if _, err := d.NextHeight(context.Background(), 0); err == nil {
+		t.Error("found the next height without a checkpoint backend")
+	}
+}
@@ -0,0 +1,166 @@
+package evm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/go-service-framework/util"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"math/big"
+	"reflect"
+)
+
+// caller makes JSON-RPC calls outside of node.Client's fixed set of methods, as fixture.Client does
+type caller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// taggedBlockNumbers are the block numbers ethclient stands the block tags for
+var taggedBlockNumbers = map[string]rpc.BlockNumber{
+	FinalitySafe:      rpc.SafeBlockNumber,
+	FinalityFinalized: rpc.FinalizedBlockNumber,
+}
+
+type client[B any, R any, T any] struct {
+	innerClient        node.Client
+	logger             util.Logger
+	ignoredTraceErrors []string
+	unmarshalOptions   protojson.UnmarshalOptions
+}
+
+// GetLatestBlockNumber gets the most recent block number
+func (c *client[B, R, T]) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	number, err := c.innerClient.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
+// GetTaggedBlockNumber gets the number of the block a tag, FinalitySafe or FinalityFinalized, names
+func (c *client[B, R, T]) GetTaggedBlockNumber(ctx context.Context, tag string) (uint64, error) {
+	//	A fixture client has no live connection, but replays raw calls
+	ethClient := c.innerClient.GetEthClient()
+	if ethClient == nil {
+		rawCaller, ok := c.innerClient.(caller)
+		if !ok {
+			return 0, errors.Errorf("node client can look up no %s block", tag)
+		}
+		var header struct {
+			Number *hexutil.Big `json:"number"`
+		}
+		if err := rawCaller.CallContext(ctx, &header, "eth_getBlockByNumber", tag, false); err != nil {
+			return 0, err
+		}
+		if header.Number == nil {
+			return 0, errors.Errorf("node has no %s block", tag)
+		}
+		return header.Number.ToInt().Uint64(), nil
+	}
+
+	header, err := ethClient.HeaderByNumber(ctx, big.NewInt(int64(taggedBlockNumbers[tag])))
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+// GetBlockByNumber gets a block by number
+func (c *client[B, R, T]) GetBlockByNumber(ctx context.Context, blockNumber uint64) (B, error) {
+	res, err := c.innerClient.GetBlockByNumber(ctx, blockNumber)
+	if err != nil {
+		var zero B
+		return zero, err
+	}
+
+	return unmarshal[B](c.unmarshalOptions, res.Result)
+}
+
+// GetTracesForBlock gets the call trace of every transaction in a block
+func (c *client[B, R, T]) GetTracesForBlock(ctx context.Context, blockNumber uint64) ([]T, error) {
+	// genesis block has no traces
+	if blockNumber == 0 {
+		return nil, nil
+	}
+
+	res, err := c.innerClient.GetTracesForBlock(ctx, blockNumber)
+	if err != nil {
+		//	Special case where known errors should fail gracefully
+		for _, ignored := range c.ignoredTraceErrors {
+			if err.Error() == ignored {
+				c.logger.Warnf("Known trace error encountered; defaulting to empty traces response: %v", err)
+				return []T{}, nil
+			}
+		}
+
+		return nil, err
+	}
+
+	var rawTraces []T
+	for _, trace := range res.Result {
+		if trace.Error != nil {
+			return nil, fmt.Errorf("%v", trace.Error)
+		}
+		rawTrace, err := unmarshal[T](c.unmarshalOptions, trace.Result)
+		if err != nil {
+			return nil, err
+		}
+		rawTraces = append(rawTraces, rawTrace)
+	}
+
+	return rawTraces, nil
+}
+
+// GetBlockReceipt gets the receipts of every transaction in a block
+func (c *client[B, R, T]) GetBlockReceipt(ctx context.Context, blockNumber uint64) ([]R, error) {
+	res, err := c.innerClient.GetBlockReceipt(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawReceipts []R
+	for _, receipt := range res.Result {
+		rawReceipt, err := unmarshal[R](c.unmarshalOptions, receipt)
+		if err != nil {
+			return nil, err
+		}
+		rawReceipts = append(rawReceipts, rawReceipt)
+	}
+
+	return rawReceipts, nil
+}
+
+// GetTransactionReceipt gets the receipt of a single transaction
+func (c *client[B, R, T]) GetTransactionReceipt(ctx context.Context, txHash string) (R, error) {
+	res, err := c.innerClient.GetTransactionReceipt(ctx, txHash)
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+
+	return unmarshal[R](c.unmarshalOptions, res.Result)
+}
+
+// unmarshal decodes JSON into a new value of type M, which must be a pointer to a struct: generated protos are decoded
+// with protojson, and plain structs with encoding/json, which always skips unknown fields
+func unmarshal[M any](options protojson.UnmarshalOptions, data []byte) (M, error) {
+	var zero M
+	value := reflect.New(reflect.TypeOf(zero).Elem()).Interface().(M)
+
+	var err error
+	if message, ok := any(value).(proto.Message); ok {
+		err = options.Unmarshal(data, message)
+	} else {
+		err = json.Unmarshal(data, value)
+	}
+	if err != nil {
+		return zero, err
+	}
+
+	return value, nil
+}
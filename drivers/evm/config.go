@@ -0,0 +1,45 @@
+package evm
+
+import (
+	"github.com/caarlos0/env/v7"
+	"github.com/coherentopensource/go-service-framework/util"
+)
+
+// Config stores configurable properties of the driver
+type Config struct {
+	MaxRetries      int    `env:"HTTP_MAX_RETRIES" envDefault:"10"`
+	DirectoryRange  uint64 `env:"BUCKET_DIRECTORY_RANGE" envDefault:"10000"`
+	IsTraceBackfill bool   `env:"IS_TRACE_BACKFILL" envDefault:"false"`
+	WriteMode       string `env:"WRITE_MODE" envDefault:"block"`
+	BatchSize       uint64 `env:"WRITE_BATCH_SIZE" envDefault:"100"`
+	BatchMaxBytes   int    `env:"WRITE_BATCH_MAX_BYTES" envDefault:"0"`
+	SchemaVersion   int    `env:"PARQUET_SCHEMA_VERSION" envDefault:"1"`
+	// TokenTransfers writes the ERC-20, ERC-721 and ERC-1155 transfers decoded from each block's logs
+	TokenTransfers bool `env:"WRITE_TOKEN_TRANSFERS" envDefault:"true"`
+	// ABIDir is a directory of contract ABIs to decode logs and calls against; see decoder.LoadRegistry
+	ABIDir string `env:"ABI_DIR"`
+	// MaxReorgDepth is the most blocks IsValidBlock walks back in search of the common ancestor of a reorg
+	MaxReorgDepth uint64 `env:"MAX_REORG_DEPTH" envDefault:"64"`
+	// Finality overrides the chain's finality mode; see ParseFinality
+	Finality string `env:"FINALITY"`
+	// UnfinalizedPrefix follows the latest block, writing blocks beneath it until they finalize; it needs block mode
+	UnfinalizedPrefix string `env:"UNFINALIZED_PREFIX"`
+	// CheckpointBackend records the entities written for each height: checkpoint.BackendFile or BackendManifest
+	CheckpointBackend string `env:"CHECKPOINT_BACKEND"`
+	// CheckpointPath is the directory completions are kept in
+	CheckpointPath string `env:"CHECKPOINT_PATH" envDefault:"checkpoints"`
+	// CheckpointFlushSize is the number of completions checkpoint.BackendManifest buffers before writing them out
+	CheckpointFlushSize int `env:"CHECKPOINT_FLUSH_SIZE" envDefault:"1000"`
+	// VerifyRoots refetches a block until its transactions and receipts hash to the roots in its header
+	VerifyRoots bool `env:"VERIFY_ROOTS" envDefault:"false"`
+}
+
+// MustParseConfig uses env.Parse to initialize config with environment variables
+func MustParseConfig(logger util.Logger, chainName string) *Config {
+	var cfg Config
+	if err := env.Parse(&cfg); err != nil {
+		logger.Fatalf("could not parse %s driver config: %v", chainName, err)
+	}
+
+	return &cfg
+}
@@ -0,0 +1,159 @@
+package evm
+
+import (
+	model "github.com/coherentopensource/evm-etl/model/evm"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// Call types of the frames that deploy contracts, upper-cased
+const (
+	callTypeCreate  = "CREATE"
+	callTypeCreate2 = "CREATE2"
+)
+
+// receiptStatusFailed is the status of a receipt whose transaction reverted
+const receiptStatusFailed = "0x0"
+
+// interfaceSelectors are the functions a contract must dispatch on to be taken for implementing a token standard
+var interfaceSelectors = []struct {
+	standard  string
+	selectors []uint32
+}{
+	//	totalSupply, balanceOf, transfer, transferFrom, approve, allowance
+	{TokenStandardERC20, []uint32{0x18160ddd, 0x70a08231, 0xa9059cbb, 0x23b872dd, 0x095ea7b3, 0xdd62ed3e}},
+	//	balanceOf, ownerOf, both safeTransferFroms, transferFrom, approve, setApprovalForAll, getApproved, isApprovedForAll
+	{TokenStandardERC721, []uint32{0x70a08231, 0x6352211e, 0x42842e0e, 0xb88d4fde, 0x23b872dd, 0x095ea7b3, 0xa22cb465, 0x081812fc, 0xe985e9c5}},
+	//	balanceOf, balanceOfBatch, safeTransferFrom, safeBatchTransferFrom, setApprovalForAll, isApprovedForAll
+	{TokenStandardERC1155, []uint32{0x00fdd58e, 0x4e1273f4, 0xf242432a, 0x2eb2c2d6, 0xa22cb465, 0xe985e9c5}},
+}
+
+// ContractToParquet converts a frame that deployed a contract to parquet, returning nil for any other frame
+func ContractToParquet[Tx Transaction, T Trace[T]](frame trace.Frame[T], tx Tx, blockNumber string) *model.ParquetContract {
+	call := frame.Call
+	creationType := strings.ToUpper(call.GetType())
+	//	Frames beneath a failed frame are rolled back along with it, which only the caller can tell
+	if (creationType != callTypeCreate && creationType != callTypeCreate2) || call.GetError() != "" {
+		return nil
+	}
+
+	out := model.ParquetContract{
+		BlockNumber:     blockNumber,
+		BlockHash:       tx.GetBlockHash(),
+		TransactionHash: tx.GetHash(),
+		TraceHash:       frame.Hash,
+		TraceAddress:    frame.TraceAddress,
+		Address:         call.GetTo(),
+		Deployer:        tx.GetFrom(),
+		CreationType:    creationType,
+	}
+	if frame.Depth > 0 {
+		out.Factory = call.GetFrom()
+	}
+	//	A creation's output is the runtime code it deployed
+	if code, err := hexutil.Decode(call.GetOutput()); err == nil {
+		out.BytecodeHash = crypto.Keccak256Hash(code).Hex()
+		out.Interfaces = detectInterfaces(code)
+	}
+
+	return &out
+}
+
+// ReceiptContractToParquet converts the contract a receipt records to parquet, for blocks without traces
+func ReceiptContractToParquet[Tx Transaction, R Receipt[L], L any](receipt R, tx Tx, blockNumber string) *model.ParquetContract {
+	if receipt.GetContractAddress() == "" || receipt.GetStatus() == receiptStatusFailed {
+		return nil
+	}
+	return &model.ParquetContract{
+		BlockNumber:     blockNumber,
+		BlockHash:       tx.GetBlockHash(),
+		TransactionHash: tx.GetHash(),
+		Address:         receipt.GetContractAddress(),
+		Deployer:        tx.GetFrom(),
+		CreationType:    callTypeCreate,
+	}
+}
+
+// ParquetContractToV2 converts a contract deployment from the hex schema to the typed schema
+func ParquetContractToV2(in *model.ParquetContract) (*model.ParquetContractV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetContractV2{
+		BlockNumber:     c.Int64("block_number", in.BlockNumber),
+		BlockHash:       in.BlockHash,
+		TransactionHash: in.TransactionHash,
+		TraceHash:       in.TraceHash,
+		TraceAddress:    in.TraceAddress,
+		Address:         in.Address,
+		Deployer:        in.Deployer,
+		Factory:         in.Factory,
+		CreationType:    in.CreationType,
+		BytecodeHash:    in.BytecodeHash,
+		Interfaces:      in.Interfaces,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert contract to typed schema")
+	}
+
+	return &out, nil
+}
+
+// revertedFrames returns the hashes of the frames that failed, or sit beneath one that did
+func revertedFrames[T Trace[T]](frames []trace.Frame[T]) map[string]bool {
+	reverted := make(map[string]bool)
+	for _, frame := range frames {
+		//	Frames come parent first, so a parent's outcome is always known before its children's
+		if frame.Call.GetError() != "" || reverted[frame.ParentHash] {
+			reverted[frame.Hash] = true
+		}
+	}
+	return reverted
+}
+
+// detectInterfaces returns the token standards whose every function selector runtime code pushes onto the stack
+func detectInterfaces(code []byte) []string {
+	pushed := pushedSelectors(code)
+
+	var interfaces []string
+	for _, candidate := range interfaceSelectors {
+		implemented := true
+		for _, selector := range candidate.selectors {
+			if !pushed[selector] {
+				implemented = false
+				break
+			}
+		}
+		if implemented {
+			interfaces = append(interfaces, candidate.standard)
+		}
+	}
+	return interfaces
+}
+
+// pushedSelectors collects the operands of every PUSH1 to PUSH4 in code
+func pushedSelectors(code []byte) map[uint32]bool {
+	const push1, push32 = 0x60, 0x7f
+
+	pushed := make(map[uint32]bool)
+	for i := 0; i < len(code); i++ {
+		op := code[i]
+		if op < push1 || op > push32 {
+			continue
+		}
+		//	Selectors with leading zero bytes are pushed with a shorter PUSH, and longer pushes are stepped over so that
+		//	their operands are never read as opcodes
+		size := int(op-push1) + 1
+		if size <= 4 && i+size < len(code) {
+			var value uint32
+			for _, b := range code[i+1 : i+1+size] {
+				value = value<<8 | uint32(b)
+			}
+			pushed[value] = true
+		}
+		i += size
+	}
+	return pushed
+}
@@ -0,0 +1,108 @@
+package evm
+
+import (
+	model "github.com/coherentopensource/evm-etl/model/evm"
+	"github.com/coherentopensource/evm-etl/shared/decoder"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// DecodedEventToParquet decodes a log against the ABIs, returning nil if none of them has its event
+func DecodedEventToParquet[L Log](registry *decoder.Registry, log L) *model.ParquetDecodedEvent {
+	decoded, ok := registry.DecodeLog(log.GetAddress(), log.GetTopics(), log.GetData())
+	if !ok {
+		return nil
+	}
+	return &model.ParquetDecodedEvent{
+		BlockNumber:      log.GetBlockNumber(),
+		BlockHash:        log.GetBlockHash(),
+		TransactionHash:  log.GetTransactionHash(),
+		TransactionIndex: log.GetTransactionIndex(),
+		LogIndex:         log.GetLogIndex(),
+		Address:          log.GetAddress(),
+		EventName:        decoded.Name,
+		EventSignature:   decoded.Signature,
+		Args:             decoded.Args,
+		DecodeError:      decoded.Error,
+	}
+}
+
+// DecodedCallToParquet decodes a call frame against the ABIs, returning nil if none of them has its function
+func DecodedCallToParquet[T Trace[T]](registry *decoder.Registry, frame trace.Frame[T], blockNumber string, blockHash string, txHash string) *model.ParquetDecodedCall {
+	call := frame.Call
+	//	Contract creations carry init code rather than a call
+	if strings.HasPrefix(strings.ToUpper(call.GetType()), "CREATE") {
+		return nil
+	}
+	output := call.GetOutput()
+	if call.GetError() != "" {
+		output = ""
+	}
+
+	decoded, ok := registry.DecodeCall(call.GetTo(), call.GetInput(), output)
+	if !ok {
+		return nil
+	}
+	return &model.ParquetDecodedCall{
+		BlockNumber:       blockNumber,
+		BlockHash:         blockHash,
+		TransactionHash:   txHash,
+		TraceHash:         frame.Hash,
+		TraceAddress:      frame.TraceAddress,
+		FromAddress:       call.GetFrom(),
+		ToAddress:         call.GetTo(),
+		FunctionName:      decoded.Name,
+		FunctionSignature: decoded.Signature,
+		Args:              decoded.Args,
+		Outputs:           decoded.Outputs,
+		DecodeError:       decoded.Error,
+	}
+}
+
+// ParquetDecodedEventToV2 converts a decoded log from the hex schema to the typed schema
+func ParquetDecodedEventToV2(in *model.ParquetDecodedEvent) (*model.ParquetDecodedEventV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetDecodedEventV2{
+		BlockNumber:      c.Int64("block_number", in.BlockNumber),
+		BlockHash:        in.BlockHash,
+		TransactionHash:  in.TransactionHash,
+		TransactionIndex: c.Int64("transaction_index", in.TransactionIndex),
+		LogIndex:         c.Int64("log_index", in.LogIndex),
+		Address:          in.Address,
+		EventName:        in.EventName,
+		EventSignature:   in.EventSignature,
+		Args:             in.Args,
+		DecodeError:      in.DecodeError,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert decoded event to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetDecodedCallToV2 converts a decoded call frame from the hex schema to the typed schema
+func ParquetDecodedCallToV2(in *model.ParquetDecodedCall) (*model.ParquetDecodedCallV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetDecodedCallV2{
+		BlockNumber:       c.Int64("block_number", in.BlockNumber),
+		BlockHash:         in.BlockHash,
+		TransactionHash:   in.TransactionHash,
+		TraceHash:         in.TraceHash,
+		TraceAddress:      in.TraceAddress,
+		FromAddress:       in.FromAddress,
+		ToAddress:         in.ToAddress,
+		FunctionName:      in.FunctionName,
+		FunctionSignature: in.FunctionSignature,
+		Args:              in.Args,
+		Outputs:           in.Outputs,
+		DecodeError:       in.DecodeError,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert decoded call to typed schema")
+	}
+
+	return &out, nil
+}
@@ -0,0 +1,68 @@
+package evm
+
+import (
+	model "github.com/coherentopensource/evm-etl/model/evm"
+	"github.com/coherentopensource/evm-etl/shared/decoder"
+	"github.com/coherentopensource/evm-etl/shared/rpc"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testERC20ABI = `[{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}]`
+
+func TestDecodedEventToParquet(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "erc20.json"), []byte(testERC20ABI), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registry, err := decoder.LoadRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+	tests := []struct {
+		name   string
+		topics []string
+		data   string
+		want   *model.ParquetDecodedEvent
+	}{
+		{
+			name:   "decoded",
+			topics: []string{transfer, addressTopic(testFrom), addressTopic(testTo)},
+			data:   words("2a"),
+			want: &model.ParquetDecodedEvent{
+				BlockNumber: "0x1", TransactionIndex: "0x0", LogIndex: "0x0", Address: "0xt0ken",
+				EventName: "Transfer", EventSignature: "Transfer(address,address,uint256)",
+				Args: `{"from":"` + testFrom + `","to":"` + testTo + `","value":"42"}`,
+			},
+		},
+		{
+			//	A log whose data does not decode is still written, with the reason in place of its arguments
+			name:   "malformed",
+			topics: []string{transfer, addressTopic(testFrom), addressTopic(testTo)},
+			data:   "0x2a",
+			want: &model.ParquetDecodedEvent{
+				BlockNumber: "0x1", TransactionIndex: "0x0", LogIndex: "0x0", Address: "0xt0ken",
+				EventName: "Transfer", EventSignature: "Transfer(address,address,uint256)",
+				DecodeError: "abi: cannot marshal in to go type: length insufficient 1 require 32",
+			},
+		},
+		{
+			name:   "unknown event",
+			topics: []string{"0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"},
+			data:   words("2a"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &rpc.Log{LogIndex: "0x0", TransactionIndex: "0x0", BlockNumber: "0x1", Address: "0xt0ken", Topics: tt.topics, Data: tt.data}
+			got := DecodedEventToParquet(registry, log)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("row = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Package evm is the driver core shared by every EVM chain; a chain plugs in its node types and codec through a Chain.
+package evm
+
+import (
+	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/shared/decoder"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/go-service-framework/util"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	stageFetchBlock   = "fetch.block"
+	stageFetchReceipt = "fetch.receipt"
+	stageFetchTraces  = "fetch.traces"
+)
+
+// Block is implemented by the block type of every chain
+type Block[Tx any] interface {
+	GetNumber() string
+	GetHash() string
+	GetParentHash() string
+	GetTransactions() []Tx
+}
+
+// Transaction is implemented by the transaction type of every chain
+type Transaction interface {
+	GetHash() string
+	GetBlockHash() string
+	GetFrom() string
+	GetTo() string
+	GetValue() string
+}
+
+// Receipt is implemented by the transaction receipt type of every chain
+type Receipt[L any] interface {
+	GetContractAddress() string
+	GetStatus() string
+	GetLogs() []L
+}
+
+// Log is implemented by the log type of every chain
+type Log interface {
+	GetLogIndex() string
+	GetTransactionIndex() string
+	GetTransactionHash() string
+	GetBlockNumber() string
+	GetBlockHash() string
+	GetAddress() string
+	GetData() string
+	GetTopics() []string
+}
+
+// Trace is implemented by the call trace type of every chain
+type Trace[T any] interface {
+	trace.Node[T]
+	GetType() string
+	GetFrom() string
+	GetTo() string
+	GetValue() string
+	GetInput() string
+	GetOutput() string
+	GetError() string
+}
+
+// Data is a block together with its receipts and call traces, as combined by Accumulate and handed to the writers
+type Data[B any, R any, T any] struct {
+	Block               B
+	TransactionReceipts []R
+	CallTraces          []T
+}
+
+// Driver is the container for all ETL business logic
+type Driver[B Block[Tx], Tx Transaction, R Receipt[L], L Log, T Trace[T]] struct {
+	chain      *Chain[B, Tx, R, L, T]
+	store      *store[*Data[B, R, T]]
+	nodeClient *client[B, R, T]
+	decoder    *decoder.Registry
+	finality   Finality
+	logger     util.Logger
+	config     *Config
+}
+
+// New constructs a new Driver for a chain
+func New[B Block[Tx], Tx Transaction, R Receipt[L], L Log, T Trace[T]](chain *Chain[B, Tx, R, L, T], cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger util.Logger) *Driver[B, Tx, R, L, T] {
+	var registry *decoder.Registry
+	if cfg.ABIDir != "" {
+		var err error
+		if registry, err = decoder.LoadRegistry(cfg.ABIDir); err != nil {
+			logger.Fatalf("Could not load ABIs: %v", err)
+		}
+	}
+	mode := cfg.Finality
+	if mode == "" {
+		mode = chain.Finality
+	}
+	if mode == "" {
+		mode = FinalityLatest
+	}
+	finality, err := ParseFinality(mode)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+	if err := storage.ValidateWriteMode(cfg.WriteMode); err != nil {
+		logger.Fatalf("%v", err)
+	}
+	if cfg.VerifyRoots && !chain.verifiesRoots() {
+		logger.Fatalf("Root verification is not supported on %s", chain.Blockchain)
+	}
+
+	return &Driver[B, Tx, R, L, T]{
+		chain: chain,
+		nodeClient: &client[B, R, T]{
+			innerClient:        nodeClient,
+			logger:             logger,
+			ignoredTraceErrors: chain.IgnoredTraceErrors,
+			unmarshalOptions:   protojson.UnmarshalOptions{DiscardUnknown: chain.DiscardUnknownFields},
+		},
+		store:    newStore(cfg, chain.entities(), innerStore, logger),
+		decoder:  registry,
+		finality: finality,
+		logger:   logger,
+		config:   cfg,
+	}
+}
+
+// Blockchain returns the name of the blockchain
+func (d *Driver[B, Tx, R, L, T]) Blockchain() string {
+	return string(d.chain.Blockchain)
+}
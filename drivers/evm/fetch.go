@@ -0,0 +1,245 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"github.com/coherentopensource/go-service-framework/retry"
+	"sync"
+)
+
+type blockAndReceiptWrapper[B any, R any] struct {
+	block    B
+	receipts []R
+}
+
+// FetchSequence defines the parallelizable steps in the fetch sequence
+func (d *Driver[B, Tx, R, L, T]) FetchSequence(blockHeight uint64) map[string]pool.Runner {
+	sequence := map[string]pool.Runner{
+		stageFetchBlock:   d.queueGetBlockByNumber(blockHeight),
+		stageFetchReceipt: d.queueGetBlockReceiptsByNumber(blockHeight),
+		stageFetchTraces:  d.queueGetBlockTraceByNumber(blockHeight),
+	}
+	if d.chain.ReceiptsPerTransaction {
+		sequence[stageFetchBlock] = d.queueGetBlockAndTxReceiptByNumber(blockHeight)
+		delete(sequence, stageFetchReceipt)
+	}
+	if d.chain.NoTraces {
+		delete(sequence, stageFetchTraces)
+	}
+
+	return sequence
+}
+
+// GetChainTipNumber gets the block number of the chaintip, as the finality mode has it. With an unfinalized prefix the
+// chaintip is the latest block whatever the mode, and the blocks the mode has come to consider final since the last
+// call are promoted first
+func (d *Driver[B, Tx, R, L, T]) GetChainTipNumber(ctx context.Context) (uint64, error) {
+	var latest, finalized uint64
+	if err := retry.Exec(d.config.MaxRetries, func() error {
+		var err error
+		if latest, err = d.nodeClient.GetLatestBlockNumber(ctx); err != nil {
+			d.logger.Warnf("error thrown while trying to retrieve latest block number: %v", err)
+			return err
+		}
+		if finalized, err = d.finalizedNumber(ctx, latest); err != nil {
+			d.logger.Warnf("error thrown while trying to retrieve %s block number: %v", d.finality.Tag, err)
+			return err
+		}
+		return nil
+	}, nil); err != nil {
+		d.logger.Errorf("max retries exceeded trying to get chaintip number: %v", err)
+		return 0, err
+	}
+
+	if d.store.unfinalized == nil {
+		return finalized, nil
+	}
+	if err := d.promote(ctx, finalized); err != nil {
+		d.logger.Errorf("failed to promote finalized blocks: %v", err)
+		return 0, err
+	}
+	return latest, nil
+}
+
+// fetchHeight runs the fetch sequence and accumulator for a height outside of the poller, as when it is written again
+func (d *Driver[B, Tx, R, L, T]) fetchHeight(ctx context.Context, height uint64) (interface{}, error) {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		res, err := runner(ctx)
+		if err != nil {
+			return nil, err
+		}
+		set[stage] = res
+	}
+	return d.Accumulate(set)(ctx)
+}
+
+// fetchBlockAndReceipts runs the stages of the fetch sequence that fetch a height's block and receipts, leaving out its
+// traces, and extracts them
+func (d *Driver[B, Tx, R, L, T]) fetchBlockAndReceipts(ctx context.Context, height uint64) (B, []R, error) {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		if stage == stageFetchTraces {
+			continue
+		}
+		res, err := runner(ctx)
+		if err != nil {
+			var zero B
+			return zero, nil, err
+		}
+		set[stage] = res
+	}
+	return d.extractBlockAndReceipts(set)
+}
+
+// getBlockByNumber fetches a full block by number
+func (d *Driver[B, Tx, R, L, T]) getBlockByNumber(ctx context.Context, blockHeight uint64) (B, error) {
+	var block B
+	var err error
+	if err := retry.Exec(d.config.MaxRetries, func() error {
+		block, err = d.nodeClient.GetBlockByNumber(ctx, blockHeight)
+		if err != nil {
+			d.logger.Warnf("error thrown while trying to retrieve block: %d, %v", blockHeight, err)
+			return err
+		}
+		if d.chain.CompleteBlock != nil {
+			if err = d.chain.CompleteBlock(ctx, block); err != nil {
+				d.logger.Warnf("error thrown while trying to complete block: %d, %v", blockHeight, err)
+				return err
+			}
+		}
+
+		return nil
+	}, nil); err != nil {
+		d.logger.Errorf("max retries exceeded trying to get block by number: %v", err)
+		var zero B
+		return zero, err
+	}
+
+	return block, nil
+}
+
+// getBlockTraceByNumber fetches all traces for a given block
+func (d *Driver[B, Tx, R, L, T]) getBlockTraceByNumber(ctx context.Context, blockHeight uint64) ([]T, error) {
+	var traces []T
+	var err error
+	if err := retry.Exec(d.config.MaxRetries, func() error {
+		traces, err = d.nodeClient.GetTracesForBlock(ctx, blockHeight)
+		if err != nil {
+			d.logger.Warnf("error thrown while trying to retrieve block trace: %d, %v", blockHeight, err)
+			return err
+		}
+
+		return nil
+	}, nil); err != nil {
+		d.logger.Errorf("max retries exceeded trying to get traces: %v", err)
+		return nil, err
+	}
+
+	return traces, nil
+}
+
+// getBlockReceiptsByNumber fetches a set of block receipts for a given block
+func (d *Driver[B, Tx, R, L, T]) getBlockReceiptsByNumber(ctx context.Context, blockHeight uint64) ([]R, error) {
+	var receipts []R
+	var err error
+	if err := retry.Exec(d.config.MaxRetries, func() error {
+		receipts, err = d.nodeClient.GetBlockReceipt(ctx, blockHeight)
+		if err != nil {
+			d.logger.Warnf("error thrown while trying to retrieve block receipts: %d, %v", blockHeight, err)
+			return err
+		}
+
+		return nil
+	}, nil); err != nil {
+		d.logger.Errorf("max retries exceeded trying to get receipts: %v", err)
+		return nil, err
+	}
+
+	return receipts, nil
+}
+
+// getTransactionReceipt fetches the receipt of a single transaction
+func (d *Driver[B, Tx, R, L, T]) getTransactionReceipt(ctx context.Context, txHash string) (R, error) {
+	var txReceipt R
+	var err error
+	if err := retry.Exec(d.config.MaxRetries, func() error {
+		txReceipt, err = d.nodeClient.GetTransactionReceipt(ctx, txHash)
+		if err != nil {
+			d.logger.Warnf("error thrown while trying to retrieve transaction receipt: %s, %v", txHash, err)
+			return err
+		}
+		return nil
+	}, nil); err != nil {
+		d.logger.Errorf("max retries exceeded trying to get transaction receipt: %v", err)
+		var zero R
+		return zero, err
+	}
+
+	return txReceipt, nil
+}
+
+// queueGetBlockTraceByNumber wraps getBlockTraceByNumber in a queueable Runner func
+func (d *Driver[B, Tx, R, L, T]) queueGetBlockTraceByNumber(blockHeight uint64) pool.Runner {
+	return func(ctx context.Context) (interface{}, error) {
+		return d.getBlockTraceByNumber(ctx, blockHeight)
+	}
+}
+
+// queueGetBlockByNumber wraps getBlockByNumber in a queueable Runner func
+func (d *Driver[B, Tx, R, L, T]) queueGetBlockByNumber(blockHeight uint64) pool.Runner {
+	return func(ctx context.Context) (interface{}, error) {
+		block, err := d.getBlockByNumber(ctx, blockHeight)
+		if err != nil {
+			return nil, err
+		}
+		if d.chain.RequireTransactions && len(block.GetTransactions()) == 0 {
+			return nil, fmt.Errorf("no transactions present in block %d", blockHeight)
+		}
+
+		return block, nil
+	}
+}
+
+// queueGetBlockReceiptsByNumber wraps getBlockReceiptsByNumber in a queueable Runner func
+func (d *Driver[B, Tx, R, L, T]) queueGetBlockReceiptsByNumber(blockHeight uint64) pool.Runner {
+	return func(ctx context.Context) (interface{}, error) {
+		return d.getBlockReceiptsByNumber(ctx, blockHeight)
+	}
+}
+
+// queueGetBlockAndTxReceiptByNumber fetches a block, then the receipts of all of its transactions in parallel, in a
+// queueable Runner func
+func (d *Driver[B, Tx, R, L, T]) queueGetBlockAndTxReceiptByNumber(blockHeight uint64) pool.Runner {
+	getBlock := d.queueGetBlockByNumber(blockHeight)
+	return func(ctx context.Context) (interface{}, error) {
+		res, err := getBlock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		block := res.(B)
+		transactions := block.GetTransactions()
+
+		receipts := make([]R, len(transactions))
+		errs := make([]error, len(transactions))
+
+		var wg sync.WaitGroup
+		wg.Add(len(transactions))
+		for index, transaction := range transactions {
+			go func(ctx context.Context, i int, tx Tx) {
+				defer wg.Done()
+				receipts[i], errs[i] = d.getTransactionReceipt(ctx, tx.GetHash())
+			}(ctx, index, transaction)
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				return nil, fmt.Errorf("error fetching transaction receipt with hash: %s, %v", transactions[i].GetHash(), err)
+			}
+		}
+
+		return &blockAndReceiptWrapper[B, R]{block: block, receipts: receipts}, nil
+	}
+}
@@ -0,0 +1,138 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// Finality modes, which decide how far behind the latest block the chaintip is kept. FinalityLatest may name a number
+// of confirmations to wait for, as in "latest-12"; FinalitySafe and FinalityFinalized follow the node's block tags
+const (
+	FinalityLatest    = "latest"
+	FinalitySafe      = "safe"
+	FinalityFinalized = "finalized"
+)
+
+// Finality is a parsed finality mode
+type Finality struct {
+	// Tag is the block tag the mode follows
+	Tag string
+	// Confirmations is the number of blocks kept behind the latest one, for FinalityLatest
+	Confirmations uint64
+}
+
+// ParseFinality parses a finality mode: FinalityLatest, optionally followed by "-" and a number of confirmations,
+// FinalitySafe or FinalityFinalized
+func ParseFinality(mode string) (Finality, error) {
+	tag, confirmations, hasConfirmations := strings.Cut(mode, "-")
+	switch {
+	case tag == FinalityLatest && hasConfirmations:
+		n, err := strconv.ParseUint(confirmations, 10, 64)
+		if err != nil {
+			return Finality{}, errors.Errorf("invalid confirmations in finality mode %q", mode)
+		}
+		return Finality{Tag: tag, Confirmations: n}, nil
+	case hasConfirmations:
+	case tag == FinalityLatest, tag == FinalitySafe, tag == FinalityFinalized:
+		return Finality{Tag: tag}, nil
+	}
+	return Finality{}, errors.Errorf("unsupported finality mode %q; expected %s, %s-<confirmations>, %s or %s", mode, FinalityLatest, FinalityLatest, FinalitySafe, FinalityFinalized)
+}
+
+// finalizedNumber gets the highest block the driver's finality mode considers final, given the latest block
+func (d *Driver[B, Tx, R, L, T]) finalizedNumber(ctx context.Context, latest uint64) (uint64, error) {
+	if d.finality.Tag != FinalityLatest {
+		return d.nodeClient.GetTaggedBlockNumber(ctx, d.finality.Tag)
+	}
+	if latest < d.finality.Confirmations {
+		return 0, nil
+	}
+	return latest - d.finality.Confirmations, nil
+}
+
+// promote moves every block written under the unfinalized prefix that has since finalized into place: as it was
+// written, if the node still has it, or else written again from the node
+func (d *Driver[B, Tx, R, L, T]) promote(ctx context.Context, finalized uint64) error {
+	unfinalized := d.store.unfinalized
+	files, err := unfinalized.innerStore.List(ctx, fmt.Sprintf("%s/%s/", unfinalized.prefix, EntityBlocks))
+	if err != nil {
+		return err
+	}
+
+	//	From here on, reads and writes of finalized heights go to the main layout
+	d.store.finalized.Store(finalized)
+	for _, file := range files {
+		height, _, err := util.ParseFileHeights(file.Name)
+		if err != nil {
+			return err
+		}
+		if height > finalized {
+			continue
+		}
+		if err := d.promoteHeight(ctx, height); err != nil {
+			return errors.Wrapf(err, "cannot promote block %d", height)
+		}
+	}
+
+	return nil
+}
+
+// promoteHeight moves a finalized block out from under the unfinalized prefix. Blocks are moved last, so that a
+// promotion cut short is picked up again by the next one
+func (d *Driver[B, Tx, R, L, T]) promoteHeight(ctx context.Context, height uint64) error {
+	unfinalized := d.store.unfinalized
+	storedHash, err := unfinalized.RetrieveBlockHash(ctx, height)
+	if err != nil {
+		return err
+	}
+	block, err := d.getBlockByNumber(ctx, height)
+	if err != nil {
+		return err
+	}
+
+	var entities []string
+	for _, entity := range d.chain.entities() {
+		if entity.Name != EntityBlocks {
+			entities = append(entities, entity.Name)
+		}
+	}
+	entities = append(entities, EntityBlocks)
+
+	if block.GetHash() == storedHash {
+		for _, entity := range entities {
+			src := unfinalized.filename(entity, height)
+			exists, err := unfinalized.innerStore.Exists(ctx, src)
+			if err != nil {
+				return err
+			}
+			if exists {
+				if err := d.store.innerStore.Copy(ctx, src, d.store.filename(entity, height)); err != nil {
+					return err
+				}
+			}
+		}
+	} else {
+		d.logger.Infof("block %d was reorged out before it finalized; writing it again", height)
+		if err := d.rewriteHeight(ctx, height); err != nil {
+			return err
+		}
+	}
+
+	for _, entity := range d.WrittenEntities() {
+		if err := d.store.complete(ctx, entity, height); err != nil {
+			return err
+		}
+	}
+	for _, entity := range entities {
+		if err := unfinalized.innerStore.Delete(ctx, unfinalized.filename(entity, height)); err != nil {
+			return err
+		}
+	}
+	d.logger.Infof("promoted finalized block %d", height)
+
+	return nil
+}
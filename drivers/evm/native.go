@@ -0,0 +1,166 @@
+package evm
+
+import (
+	model "github.com/coherentopensource/evm-etl/model/evm"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+)
+
+// Kinds of native transfer, written to the transfer_type column
+const (
+	TransferTypeTransaction  = "transaction"
+	TransferTypeInternal     = "internal"
+	TransferTypeSelfdestruct = "selfdestruct"
+	TransferTypeWithdrawal   = "withdrawal"
+)
+
+// Call types of the frames that move value other than by creation
+const (
+	callTypeCall         = "CALL"
+	callTypeSelfdestruct = "SELFDESTRUCT"
+)
+
+// Statuses of a native transfer
+const (
+	transferStatusFailed  = 0
+	transferStatusSuccess = 1
+)
+
+// weiPerGwei converts withdrawal amounts, which the node gives in Gwei, to wei
+var weiPerGwei = big.NewInt(1_000_000_000)
+
+// NativeTransferToParquet converts the value a frame moved to parquet, returning nil for frames that moved none
+func NativeTransferToParquet[Tx Transaction, T Trace[T]](frame trace.Frame[T], tx Tx, blockNumber string) (*model.ParquetNativeTransfer, error) {
+	call := frame.Call
+	callType := strings.ToUpper(call.GetType())
+	//	DELEGATECALL frames repeat their caller's value and CALLCODE frames send it back, so neither moves anything
+	if callType != callTypeCall && callType != callTypeCreate && callType != callTypeCreate2 && callType != callTypeSelfdestruct {
+		return nil, nil
+	}
+	moved, err := movesValue(call.GetValue())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid value of trace %s", frame.Hash)
+	}
+	if !moved {
+		return nil, nil
+	}
+
+	out := model.ParquetNativeTransfer{
+		BlockNumber:     blockNumber,
+		BlockHash:       tx.GetBlockHash(),
+		TransactionHash: tx.GetHash(),
+		TraceHash:       frame.Hash,
+		TraceAddress:    frame.TraceAddress,
+		TransferType:    TransferTypeInternal,
+		CallType:        callType,
+		FromAddress:     call.GetFrom(),
+		ToAddress:       call.GetTo(),
+		Value:           call.GetValue(),
+		Status:          transferStatusSuccess,
+	}
+	switch {
+	case callType == callTypeSelfdestruct:
+		out.TransferType = TransferTypeSelfdestruct
+	case frame.Depth == 0:
+		//	Only the root frame carries a status; the caller leaves out frames beneath a failed one
+		out.TransferType = TransferTypeTransaction
+		if call.GetError() != "" {
+			out.Status = transferStatusFailed
+		}
+	}
+
+	return &out, nil
+}
+
+// TransactionNativeTransferToParquet converts the value a transaction sent to parquet, for blocks without traces
+func TransactionNativeTransferToParquet[Tx Transaction, R Receipt[L], L any](tx Tx, receipt R, blockNumber string) (*model.ParquetNativeTransfer, error) {
+	moved, err := movesValue(tx.GetValue())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid value of transaction %s", tx.GetHash())
+	}
+	if !moved {
+		return nil, nil
+	}
+
+	out := model.ParquetNativeTransfer{
+		BlockNumber:     blockNumber,
+		BlockHash:       tx.GetBlockHash(),
+		TransactionHash: tx.GetHash(),
+		TransferType:    TransferTypeTransaction,
+		CallType:        callTypeCall,
+		FromAddress:     tx.GetFrom(),
+		ToAddress:       tx.GetTo(),
+		Value:           tx.GetValue(),
+		Status:          transferStatusSuccess,
+	}
+	if tx.GetTo() == "" {
+		out.CallType = callTypeCreate
+		out.ToAddress = receipt.GetContractAddress()
+	}
+	if receipt.GetStatus() == receiptStatusFailed {
+		out.Status = transferStatusFailed
+	}
+
+	return &out, nil
+}
+
+// WithdrawalNativeTransferToParquet converts a beacon chain withdrawal to parquet, returning nil for one of no amount
+func WithdrawalNativeTransferToParquet(withdrawal Withdrawal, blockNumber string, blockHash string) (*model.ParquetNativeTransfer, error) {
+	gwei, err := util.ParseQuantity(withdrawal.Amount)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid amount of withdrawal %s", withdrawal.Index)
+	}
+	if gwei.Sign() == 0 {
+		return nil, nil
+	}
+
+	return &model.ParquetNativeTransfer{
+		BlockNumber:     blockNumber,
+		BlockHash:       blockHash,
+		TransferType:    TransferTypeWithdrawal,
+		ToAddress:       withdrawal.Address,
+		Value:           hexutil.EncodeBig(new(big.Int).Mul(gwei, weiPerGwei)),
+		Status:          transferStatusSuccess,
+		WithdrawalIndex: withdrawal.Index,
+	}, nil
+}
+
+// ParquetNativeTransferToV2 converts a native transfer from the hex schema to the typed schema
+func ParquetNativeTransferToV2(in *model.ParquetNativeTransfer) (*model.ParquetNativeTransferV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetNativeTransferV2{
+		BlockNumber:     c.Int64("block_number", in.BlockNumber),
+		BlockHash:       in.BlockHash,
+		TransactionHash: in.TransactionHash,
+		TraceHash:       in.TraceHash,
+		TraceAddress:    in.TraceAddress,
+		TransferType:    in.TransferType,
+		CallType:        in.CallType,
+		FromAddress:     in.FromAddress,
+		ToAddress:       in.ToAddress,
+		Value:           c.Decimal38("value", in.Value),
+		Status:          in.Status,
+		WithdrawalIndex: c.OptionalInt64("withdrawal_index", in.WithdrawalIndex),
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert native transfer to typed schema")
+	}
+
+	return &out, nil
+}
+
+// movesValue reports whether a value is above zero; nodes leave it out of frames that cannot carry any
+func movesValue(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	n, err := util.ParseQuantity(value)
+	if err != nil {
+		return false, err
+	}
+	return n.Sign() > 0, nil
+}
@@ -0,0 +1,104 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	model "github.com/coherentopensource/evm-etl/model/evm"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// reorgPrefix is the top-level directory holding reorg audit records, kept apart from entity directories
+const reorgPrefix = "reorgs"
+
+// recoverReorg brings the written files back in line with the node after a reorg detected at a block: it walks back
+// from the block's parent until the stored hash and the node's agree, writes every height above that common ancestor
+// again from the node, across every entity, and records the reorg. Blocks are rewritten last, so that a recovery cut
+// short is detected, and resumed, by the next validation
+func (d *Driver[B, Tx, R, L, T]) recoverReorg(ctx context.Context, index uint64) error {
+	audit := model.ParquetReorg{
+		DetectedAt:  time.Now().UnixMilli(),
+		BlockNumber: int64(index),
+	}
+
+	height := index - 1
+	for {
+		storedHash, err := d.store.RetrieveBlockHash(ctx, height)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read back block %d", height)
+		}
+		block, err := d.getBlockByNumber(ctx, height)
+		if err != nil {
+			return err
+		}
+		if block.GetHash() == storedHash {
+			break
+		}
+
+		if uint64(len(audit.Heights)) == d.config.MaxReorgDepth || height == 0 {
+			return errors.Errorf("no common ancestor within %d blocks of block %d", len(audit.Heights), index)
+		}
+		audit.Heights = append([]int64{int64(height)}, audit.Heights...)
+		audit.OldHashes = append([]string{storedHash}, audit.OldHashes...)
+		height--
+	}
+	//	The node may have reorganized again between fetching the block and its parent; the next validation retries
+	if len(audit.Heights) == 0 {
+		return errors.Errorf("block %d does not extend the chain the node reports at its parent", index)
+	}
+	audit.CommonAncestor = int64(height)
+	audit.Depth = int64(len(audit.Heights))
+	d.logger.Infof("recovering from a reorg of %d blocks above block %d", audit.Depth, audit.CommonAncestor)
+
+	for _, orphaned := range audit.Heights {
+		if err := d.rewriteHeight(ctx, uint64(orphaned)); err != nil {
+			return errors.Wrapf(err, "cannot rewrite block %d", orphaned)
+		}
+		newHash, err := d.store.RetrieveBlockHash(ctx, uint64(orphaned))
+		if err != nil {
+			return err
+		}
+		audit.NewHashes = append(audit.NewHashes, newHash)
+	}
+
+	first, last := uint64(audit.Heights[0]), uint64(audit.Heights[len(audit.Heights)-1])
+	filename := fmt.Sprintf("%s/%s/%d-%d_%d.parquet", reorgPrefix, util.RangeName(first, d.config.DirectoryRange), first, last, audit.DetectedAt)
+	if err := d.store.record(ctx, first, filename, &audit, new(model.ParquetReorg)); err != nil {
+		return errors.Wrapf(err, "cannot write reorg record %s", filename)
+	}
+	d.logger.Infof("recovered from the reorg of blocks %d to %d", first, last)
+
+	return nil
+}
+
+// rewriteHeight fetches a height from the node again and overwrites what every entity wrote for it, blocks last
+func (d *Driver[B, Tx, R, L, T]) rewriteHeight(ctx context.Context, height uint64) error {
+	data, err := d.fetchHeight(ctx, height)
+	if err != nil {
+		return err
+	}
+	block, _, err := d.unpackBlock(data)
+	if err != nil {
+		return err
+	}
+
+	writers := d.allEntityWriters()
+	for i, w := range writers {
+		if w.entity == EntityBlocks {
+			writers = append(append(writers[:i:i], writers[i+1:]...), w)
+			break
+		}
+	}
+	for _, w := range writers {
+		rows, err := w.rows(ctx, block, height)
+		if err != nil {
+			return err
+		}
+		if err := d.store.replace(ctx, w.entity, height, rows); err != nil {
+			return errors.Wrapf(err, "cannot overwrite %s", w.entity)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package evm_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/evm"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/roots"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nopLogger discards everything logged
+type nopLogger struct{}
+
+func (nopLogger) Error(...interface{})                 {}
+func (nopLogger) Info(...interface{})                  {}
+func (nopLogger) Fatal(...interface{})                 { panic("fatal") }
+func (nopLogger) Panic(...interface{})                 { panic("panic") }
+func (nopLogger) Warn(...interface{})                  {}
+func (nopLogger) Errorf(string, ...interface{})        {}
+func (nopLogger) Infof(string, ...interface{})         {}
+func (nopLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (nopLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (nopLogger) Warnf(string, ...interface{})         {}
+
+// forkClient replays the fixtures of one fork of the chain, and can be switched to another
+type forkClient struct {
+	*fixture.Client
+}
+
+// blockHash names the block at a height on a fork
+func blockHash(height uint64, fork string) string {
+	return fmt.Sprintf("0x%02x%s", height, fork)
+}
+
+// writeFork records the node's responses for blocks of a fork, each with a single transaction, given the fork of each
+// block's parent
+func writeFork(t *testing.T, dir string, fork string, heights map[uint64]string) {
+	t.Helper()
+	write := func(sub string, height uint64, result interface{}) {
+		raw, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, sub, fmt.Sprintf("%d.json", height))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, raw, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for height, parentFork := range heights {
+		number := fmt.Sprintf("0x%x", height)
+		hash := blockHash(height, fork)
+		txHash := "0x7a" + hash[2:]
+		tx := map[string]interface{}{
+			"hash": txHash, "blockHash": hash, "blockNumber": number, "transactionIndex": "0x0", "type": "0x2",
+			"from": "0x" + fmt.Sprintf("%040x", 0xa), "to": "0x" + fmt.Sprintf("%040x", 0xb), "value": "0x1",
+			"gas": "0x5208", "gasPrice": "0x7", "maxFeePerGas": "0x8", "maxPriorityFeePerGas": "0x1", "nonce": "0x0",
+		}
+		receipt := map[string]interface{}{
+			"transactionHash": txHash, "transactionIndex": "0x0", "blockHash": hash, "blockNumber": number,
+			"gasUsed": "0x5208", "cumulativeGasUsed": "0x5208", "effectiveGasPrice": "0x7", "status": "0x1", "type": "0x2",
+			"logsBloom": "0x" + strings.Repeat("00", 256), "logs": []interface{}{},
+		}
+		transactionsRoot, receiptsRoot := fixtureRoots(t, tx, receipt)
+		write("blocks", height, map[string]interface{}{
+			"number": number, "hash": hash, "parentHash": blockHash(height-1, parentFork), "difficulty": "0x0",
+			"timestamp": "0x65f1b057", "gasUsed": "0x5208", "transactionsRoot": transactionsRoot, "receiptsRoot": receiptsRoot,
+			"transactions": []interface{}{tx},
+		})
+		write("block_receipts", height, []interface{}{receipt})
+		//	Traces name the fork in their input, so that those of one block can be told from another's
+		write("traces", height, []map[string]interface{}{{"result": map[string]interface{}{
+			"type": "CALL", "from": "0x" + fmt.Sprintf("%040x", 0xa), "to": "0x" + fmt.Sprintf("%040x", 0xb), "value": "0x1",
+			"gas": "0x5208", "gasUsed": "0x5208", "input": "0x0" + fork,
+		}}})
+	}
+}
+
+// fixtureRoots returns the roots committed to by a block of a single transaction, given as the node returns it
+func fixtureRoots(t *testing.T, tx interface{}, receipt interface{}) (string, string) {
+	t.Helper()
+	var inTx ethereum.Transaction
+	var inReceipt ethereum.TransactionReceipt
+	for _, c := range []struct{ in, out interface{} }{{tx, &inTx}, {receipt, &inReceipt}} {
+		raw, err := json.Marshal(c.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(raw, c.out); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transactionsRoot, err := roots.TransactionsRoot([]*roots.Transaction{ethereum.TransactionToRoots(&inTx)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiptsRoot, err := roots.ReceiptsRoot([]*roots.Receipt{ethereum.ReceiptToRoots(&inReceipt)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transactionsRoot, receiptsRoot
+}
+
+// ingest writes heights as the poller would: fetch, accumulate, then every writer
+func ingest(ctx context.Context, d *ethereum.Driver, heights ...uint64) error {
+	for _, height := range heights {
+		set := pool.ResultSet{}
+		for stage, runner := range d.FetchSequence(height) {
+			res, err := runner(ctx)
+			if err != nil {
+				return err
+			}
+			set[stage] = res
+		}
+		data, err := d.Accumulate(set)(ctx)
+		if err != nil {
+			return err
+		}
+		for _, writer := range d.Writers() {
+			if _, err := writer(data)(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return d.Flush(ctx)
+}
+
+// readHashes maps each height an entity has rows for to the block hash its rows name, from the fields of the rows that
+// hold them
+func readHashes(ctx context.Context, store *storage.MemoryConnector, dir string, mapToStruct interface{}, numberField string, hashField string) (map[int64]string, error) {
+	hashes := make(map[int64]string)
+	for _, file := range store.FilesWithPrefix(dir + "/") {
+		rows, err := storage.ReadAll(ctx, store, file, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			v := reflect.ValueOf(row).Elem()
+			var height int64
+			if _, err := fmt.Sscanf(v.FieldByName(numberField).String(), "0x%x", &height); err != nil {
+				return nil, err
+			}
+			hashes[height] = v.FieldByName(hashField).String()
+		}
+	}
+	return hashes, nil
+}
+
+func TestReorgRewritesOrphanedHeights(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  evm.Config
+		// backfill validates with a driver backfilling traces, over what a full driver wrote
+		backfill bool
+		prefix   string
+	}{
+		{name: "block mode", cfg: evm.Config{WriteMode: storage.WriteModeBlock}},
+		{name: "batch mode", cfg: evm.Config{WriteMode: storage.WriteModeBatch, BatchSize: 2}},
+		{name: "trace backfill", cfg: evm.Config{WriteMode: storage.WriteModeBlock}, backfill: true},
+		{name: "unfinalized prefix", cfg: evm.Config{WriteMode: storage.WriteModeBlock, UnfinalizedPrefix: "unfinalized"}, prefix: "unfinalized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			old, reorged := filepath.Join(dir, "old"), filepath.Join(dir, "reorged")
+			//	Blocks 5 and 6 are orphaned, and 7 builds on the fork that replaced them
+			writeFork(t, old, "a", map[uint64]string{4: "a", 5: "a", 6: "a"})
+			writeFork(t, reorged, "a", map[uint64]string{4: "a"})
+			writeFork(t, reorged, "b", map[uint64]string{5: "a", 6: "b", 7: "b"})
+
+			store := storage.NewMemoryConnector(10000)
+			client := &forkClient{fixture.NewClient(old)}
+			cfg := tt.cfg
+			cfg.MaxRetries, cfg.DirectoryRange, cfg.SchemaVersion, cfg.MaxReorgDepth = 1, 10000, 1, 64
+			if err := ingest(ctx, ethereum.New(&cfg, client, store, nopLogger{}), 4, 5, 6); err != nil {
+				t.Fatal(err)
+			}
+
+			client.Client = fixture.NewClient(reorged)
+			validateCfg := cfg
+			validateCfg.IsTraceBackfill = tt.backfill
+			validator := ethereum.New(&validateCfg, client, store, nopLogger{})
+			if err := validator.IsValidBlock(ctx, 7); err != nil {
+				t.Fatal(err)
+			}
+			if err := validator.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[int64]string{4: blockHash(4, "a"), 5: blockHash(5, "b"), 6: blockHash(6, "b")}
+			for _, entity := range []struct {
+				name        string
+				numberField string
+				hashField   string
+			}{
+				{evm.EntityBlocks, "Number", "Hash"},
+				{evm.EntityTransactions, "BlockNumber", "BlockHash"},
+				{evm.EntityTraces, "BlockNumber", "BlockHash"},
+				{evm.EntityNativeTransfers, "BlockNumber", "BlockHash"},
+			} {
+				got, err := readHashes(ctx, store, path.Join(tt.prefix, entity.name), ethereum.Entities[entity.name], entity.numberField, entity.hashField)
+				if err != nil {
+					t.Fatalf("read %s: %v", entity.name, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s are of blocks %v, want %v", entity.name, got, want)
+				}
+			}
+
+			records := store.FilesWithPrefix(path.Join(tt.prefix, "reorgs") + "/")
+			if len(records) != 1 {
+				t.Fatalf("reorg records = %v, want one beneath %q", records, tt.prefix)
+			}
+			rows, err := storage.ReadAll(ctx, store, records[0], new(model.ParquetReorg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			record := rows[0].(*model.ParquetReorg)
+			if !reflect.DeepEqual(record.Heights, []int64{5, 6}) || !reflect.DeepEqual(record.NewHashes, []string{want[5], want[6]}) {
+				t.Errorf("reorg record = %+v", record)
+			}
+		})
+	}
+}
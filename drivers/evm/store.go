@@ -0,0 +1,336 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/checkpoint"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
+	"path"
+	"reflect"
+	"sync/atomic"
+)
+
+type store[D any] struct {
+	innerStore     storage.Store
+	batcher        *storage.BatchWriter
+	entities       map[string]Entity[D]
+	directoryRange uint64
+	schemaVersion  int
+	// checkpoints records the entities written for each height, if the driver is configured to keep checkpoints
+	checkpoints *checkpoint.Tracker
+
+	// prefix is the directory the store's files are written beneath, which is only set for unfinalized blocks
+	prefix string
+	// unfinalized takes the heights above finalized, when the driver is configured with an unfinalized prefix
+	unfinalized *store[D]
+	finalized   atomic.Uint64
+}
+
+// newStore wraps the backing store, adding a batch writer when the driver is configured for batched output, a
+// checkpoint tracker when it is configured to keep checkpoints, and a store for unfinalized blocks when it is
+// configured with an unfinalized prefix
+func newStore[D any](cfg *Config, entities []Entity[D], innerStore storage.Store, logger frameworkUtil.Logger) *store[D] {
+	if cfg.SchemaVersion != util.SchemaVersionHex && cfg.SchemaVersion != util.SchemaVersionTyped {
+		logger.Fatalf("Unsupported parquet schema version: %d", cfg.SchemaVersion)
+	}
+
+	s := &store[D]{
+		innerStore:     innerStore,
+		entities:       make(map[string]Entity[D], len(entities)),
+		directoryRange: cfg.DirectoryRange,
+		schemaVersion:  cfg.SchemaVersion,
+	}
+	for _, entity := range entities {
+		s.entities[entity.Name] = entity
+	}
+	if cfg.CheckpointBackend != "" {
+		backend, err := checkpoint.NewBackend(checkpoint.Config{
+			Backend:        cfg.CheckpointBackend,
+			Path:           cfg.CheckpointPath,
+			FlushSize:      cfg.CheckpointFlushSize,
+			DirectoryRange: cfg.DirectoryRange,
+		}, innerStore)
+		if err != nil {
+			logger.Fatalf("Could not instantiate checkpoint backend: %v", err)
+		}
+		s.checkpoints = checkpoint.New(backend, cfg.DirectoryRange)
+	}
+	if cfg.WriteMode == storage.WriteModeBatch {
+		batcher, err := storage.NewBatchWriter(innerStore, storage.BatchConfig{
+			BatchSize:      cfg.BatchSize,
+			MaxBytes:       cfg.BatchMaxBytes,
+			DirectoryRange: cfg.DirectoryRange,
+			OnWrite:        s.completeBatch,
+		})
+		if err != nil {
+			logger.Fatalf("Could not instantiate batch writer: %v", err)
+		}
+		s.batcher = batcher
+	}
+	if cfg.UnfinalizedPrefix != "" {
+		if s.batcher != nil {
+			logger.Fatalf("Unfinalized prefix needs the %s write mode", storage.WriteModeBlock)
+		}
+		s.unfinalized = &store[D]{
+			innerStore:     innerStore,
+			entities:       s.entities,
+			directoryRange: cfg.DirectoryRange,
+			schemaVersion:  cfg.SchemaVersion,
+			prefix:         cfg.UnfinalizedPrefix,
+		}
+	}
+
+	return s
+}
+
+// route returns the store that holds a height: the unfinalized store for heights not yet final, if there is one
+func (s *store[D]) route(height uint64) *store[D] {
+	if s.unfinalized != nil && height > s.finalized.Load() {
+		return s.unfinalized
+	}
+	return s
+}
+
+// write outputs an entity's rows for a height, either as a file of its own or into the height's batch; rows are
+// always given in the hex schema, and converted here if the typed schema is configured
+func (s *store[D]) write(ctx context.Context, entity string, height uint64, rows []interface{}) error {
+	if target := s.route(height); target != s {
+		return target.write(ctx, entity, height, rows)
+	}
+
+	rows, mapToStruct, err := s.schema(entity, rows)
+	if err != nil {
+		return err
+	}
+
+	if s.batcher != nil {
+		return s.batcher.Add(ctx, entity, height, rows, mapToStruct)
+	}
+
+	if err := s.innerStore.WriteMany(ctx, rows, mapToStruct, s.filename(entity, height)); err != nil {
+		return err
+	}
+	return s.complete(ctx, entity, height)
+}
+
+// skip records that an entity has nothing to write for a height; in batch mode the height still counts towards
+// completing its batch
+func (s *store[D]) skip(ctx context.Context, entity string, height uint64) error {
+	if target := s.route(height); target != s {
+		return target.skip(ctx, entity, height)
+	}
+
+	if s.batcher == nil {
+		return s.complete(ctx, entity, height)
+	}
+	return s.write(ctx, entity, height, nil)
+}
+
+// flush writes any partially-filled batches, then any checkpoints still buffered
+func (s *store[D]) flush(ctx context.Context) error {
+	if s.batcher != nil {
+		if err := s.batcher.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	if s.checkpoints != nil {
+		return s.checkpoints.Flush(ctx)
+	}
+	return nil
+}
+
+// complete records that an entity has been written for heights, if checkpoints are kept
+func (s *store[D]) complete(ctx context.Context, entity string, heights ...uint64) error {
+	if s.checkpoints == nil {
+		return nil
+	}
+	return s.checkpoints.Complete(ctx, entity, heights...)
+}
+
+// completeBatch records the heights of a written batch as complete
+func (s *store[D]) completeBatch(ctx context.Context, manifest *storage.BatchManifest) error {
+	heights := make([]uint64, len(manifest.Heights))
+	for i, height := range manifest.Heights {
+		heights[i] = uint64(height)
+	}
+	return s.complete(ctx, manifest.Entity, heights...)
+}
+
+// read returns the rows written for an entity at a height, in whichever schema version is configured
+func (s *store[D]) read(ctx context.Context, entity string, height uint64) ([]interface{}, error) {
+	if target := s.route(height); target != s {
+		return target.read(ctx, entity, height)
+	}
+
+	_, mapToStruct, err := s.schema(entity, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.readAs(ctx, entity, height, mapToStruct)
+}
+
+// readAs returns the rows written for an entity at a height as the given model, which may hold only some of the
+// entity's columns; columns a file predates are left zero
+func (s *store[D]) readAs(ctx context.Context, entity string, height uint64, mapToStruct interface{}) ([]interface{}, error) {
+	if target := s.route(height); target != s {
+		return target.readAs(ctx, entity, height, mapToStruct)
+	}
+
+	if s.batcher != nil {
+		return s.batcher.Read(ctx, entity, height, mapToStruct)
+	}
+
+	manifest, err := s.compacted(ctx, entity, height)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		heights, err := storage.ReadBatch(ctx, s.innerStore, manifest, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		return heights[height], nil
+	}
+	rows, _, err := storage.ReadCompatible(ctx, s.innerStore, s.filename(entity, height), mapToStruct)
+	return rows, err
+}
+
+// compacted returns the manifest of the compacted file holding an entity's rows for a height in block mode, or nil if
+// the height still has a file of its own
+func (s *store[D]) compacted(ctx context.Context, entity string, height uint64) (*storage.BatchManifest, error) {
+	exists, err := s.innerStore.Exists(ctx, s.filename(entity, height))
+	if err != nil || exists {
+		return nil, err
+	}
+	return storage.FindManifest(ctx, s.innerStore, path.Join(s.prefix, entity), height, s.directoryRange)
+}
+
+// exists reports whether an entity has been written at a height, either as a file of its own or as part of a batch
+func (s *store[D]) exists(ctx context.Context, entity string, height uint64) (bool, error) {
+	if target := s.route(height); target != s {
+		return target.exists(ctx, entity, height)
+	}
+
+	if s.batcher != nil {
+		return s.batcher.Exists(ctx, entity, height)
+	}
+	exists, err := s.innerStore.Exists(ctx, s.filename(entity, height))
+	if err != nil || exists {
+		return exists, err
+	}
+	manifest, err := storage.FindManifest(ctx, s.innerStore, path.Join(s.prefix, entity), height, s.directoryRange)
+	return manifest != nil, err
+}
+
+// replace overwrites the rows written for an entity at a height, as when a reorg orphans it; rows are given in the hex
+// schema, as to write. A height left without rows has its file removed in block mode
+func (s *store[D]) replace(ctx context.Context, entity string, height uint64, rows []interface{}) error {
+	if target := s.route(height); target != s {
+		return target.replace(ctx, entity, height, rows)
+	}
+
+	rows, mapToStruct, err := s.schema(entity, rows)
+	if err != nil {
+		return err
+	}
+
+	if s.batcher != nil {
+		return s.batcher.Replace(ctx, entity, height, rows, mapToStruct)
+	}
+
+	//	A compacted height is swapped within its file, which is written again in full
+	manifest, err := s.compacted(ctx, entity, height)
+	if err != nil {
+		return err
+	}
+	if manifest != nil {
+		heights, err := storage.ReadBatch(ctx, s.innerStore, manifest, mapToStruct)
+		if err != nil {
+			return err
+		}
+		heights[height] = rows
+		if _, err := storage.WriteBatch(ctx, s.innerStore, manifest.Entity, heights, mapToStruct, s.directoryRange); err != nil {
+			return err
+		}
+		return s.complete(ctx, entity, height)
+	}
+
+	filename := s.filename(entity, height)
+	if len(rows) == 0 {
+		err = s.innerStore.Delete(ctx, filename)
+	} else {
+		err = s.innerStore.WriteMany(ctx, rows, mapToStruct, filename)
+	}
+	if err != nil {
+		return err
+	}
+	return s.complete(ctx, entity, height)
+}
+
+// record writes a row outside the entity directories, beneath the prefix of the store that holds a height
+func (s *store[D]) record(ctx context.Context, height uint64, name string, row interface{}, mapToStruct interface{}) error {
+	if target := s.route(height); target != s {
+		return target.record(ctx, height, name, row, mapToStruct)
+	}
+	return s.innerStore.WriteOne(ctx, row, mapToStruct, path.Join(s.prefix, name))
+}
+
+// filename names the file holding an entity's rows for a height in block mode
+func (s *store[D]) filename(entity string, height uint64) string {
+	return path.Join(s.prefix, fmt.Sprintf("%s/%s/%d.parquet", entity, util.RangeName(height, s.directoryRange), height))
+}
+
+// schema returns an entity's rows and model in the configured schema version, converting the rows from the hex schema
+// if need be
+func (s *store[D]) schema(entity string, rows []interface{}) ([]interface{}, interface{}, error) {
+	e, ok := s.entities[entity]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown entity %q", entity)
+	}
+	if s.schemaVersion != util.SchemaVersionTyped {
+		return rows, e.Model, nil
+	}
+
+	var typed []interface{}
+	for _, row := range rows {
+		out, err := e.ToV2(row)
+		if err != nil {
+			return nil, nil, err
+		}
+		typed = append(typed, out)
+	}
+	return typed, e.ModelV2, nil
+}
+
+// RetrieveBlockHash reads back the hash of a block that has already been written
+func (s *store[D]) RetrieveBlockHash(ctx context.Context, blockHeight uint64) (string, error) {
+	_, mapToStruct, err := s.schema(EntityBlocks, nil)
+	if err != nil {
+		return "", err
+	}
+	//	Only the hash column is read, which block files of every vintage have
+	blocks, err := s.readAs(ctx, EntityBlocks, blockHeight, storage.Project(mapToStruct, "Hash"))
+	if err != nil {
+		return "", err
+	}
+
+	//	We expect 1 row - make sure there is at least 1 and take the first 1
+	if len(blocks) == 0 {
+		return "", errors.New("no rows in block parquet")
+	}
+
+	//	The block models of every chain and schema version name their hash column Hash
+	hash := reflect.ValueOf(blocks[0]).Elem().FieldByName("Hash")
+	if hash.Kind() != reflect.String {
+		return "", fmt.Errorf("unexpected block row %T", blocks[0])
+	}
+	return hash.String(), nil
+}
+
+// CheckForTrace reports whether traces have already been written for a block
+func (s *store[D]) CheckForTrace(ctx context.Context, blockHeight uint64) (bool, error) {
+	return s.exists(ctx, EntityTraces, blockHeight)
+}
@@ -0,0 +1,202 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/compaction"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"reflect"
+	"testing"
+)
+
+type testBlock struct {
+	Number      string `parquet:"name=number, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Hash        string `parquet:"name=hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	BlobGasUsed string `parquet:"name=blob_gas_used, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// legacyTestBlock is testBlock as written before its blob column was added
+type legacyTestBlock struct {
+	Number string `parquet:"name=number, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Hash   string `parquet:"name=hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+func newTestStore(cfg *Config, innerStore storage.Store) *store[struct{}] {
+	cfg.DirectoryRange = 10
+	cfg.SchemaVersion = util.SchemaVersionHex
+	return newStore[struct{}](cfg, []Entity[struct{}]{{Name: EntityBlocks, Model: new(testBlock)}}, innerStore, testLogger{})
+}
+
+func TestStoreReadsCompactedRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		heights []uint64
+		missing []uint64
+	}{
+		{
+			name:    "block mode",
+			cfg:     Config{WriteMode: storage.WriteModeBlock},
+			heights: []uint64{0, 1, 2, 3, 4, 5, 6, 8, 9},
+			missing: []uint64{7},
+		},
+		{
+			name:    "batch mode",
+			cfg:     Config{WriteMode: storage.WriteModeBatch, BatchSize: 5},
+			heights: []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			innerStore := storage.NewMemoryConnector(10)
+			cfg := tt.cfg
+			s := newTestStore(&cfg, innerStore)
+			for _, height := range tt.heights {
+				row := &testBlock{Number: fmt.Sprint(height), Hash: fmt.Sprintf("0x%x", height)}
+				if err := s.write(ctx, EntityBlocks, height, []interface{}{row}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			compactor := compaction.New(innerStore, compaction.Config{DirectoryRange: 10, Before: 10}, testLogger{})
+			if err := compactor.Compact(ctx, EntityBlocks, new(testBlock), 0); err != nil {
+				t.Fatal(err)
+			}
+			want := []string{fmt.Sprintf("blocks/blocks_0-9/%d-%d.parquet", tt.heights[0], tt.heights[len(tt.heights)-1])}
+			if got := innerStore.FilesWithPrefix("blocks/"); !reflect.DeepEqual(got, want) {
+				t.Fatalf("files = %v, want %v", got, want)
+			}
+
+			//	A fresh store reads the range back, as after a restart
+			cfg = tt.cfg
+			s = newTestStore(&cfg, innerStore)
+			for _, height := range tt.heights {
+				hash, err := s.RetrieveBlockHash(ctx, height)
+				if err != nil {
+					t.Fatalf("retrieve %d: %v", height, err)
+				}
+				if hash != fmt.Sprintf("0x%x", height) {
+					t.Errorf("hash of %d = %s", height, hash)
+				}
+			}
+			for _, height := range tt.missing {
+				if exists, err := s.exists(ctx, EntityBlocks, height); err != nil || exists {
+					t.Errorf("exists(%d) = %v, %v", height, exists, err)
+				}
+			}
+
+			//	Replacing a height rewrites the compacted file in place
+			orphaned := tt.heights[3]
+			if err := s.replace(ctx, EntityBlocks, orphaned, []interface{}{&testBlock{Number: fmt.Sprint(orphaned), Hash: "0xnew"}}); err != nil {
+				t.Fatal(err)
+			}
+			if got := innerStore.FilesWithPrefix("blocks/"); !reflect.DeepEqual(got, want) {
+				t.Fatalf("files after replace = %v, want %v", got, want)
+			}
+			for _, height := range tt.heights {
+				wantHash := fmt.Sprintf("0x%x", height)
+				if height == orphaned {
+					wantHash = "0xnew"
+				}
+				if hash, err := s.RetrieveBlockHash(ctx, height); err != nil || hash != wantHash {
+					t.Errorf("hash of %d = %s, %v; want %s", height, hash, err, wantHash)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreReadsLegacyFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, innerStore storage.Store, rows map[uint64][]interface{}) error
+	}{
+		{
+			name: "block mode",
+			write: func(ctx context.Context, innerStore storage.Store, rows map[uint64][]interface{}) error {
+				for height, heightRows := range rows {
+					filename := fmt.Sprintf("blocks/blocks_0-9/%d.parquet", height)
+					if err := innerStore.WriteMany(ctx, heightRows, new(legacyTestBlock), filename); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name: "batched",
+			write: func(ctx context.Context, innerStore storage.Store, rows map[uint64][]interface{}) error {
+				_, err := storage.WriteBatch(ctx, innerStore, EntityBlocks, rows, new(legacyTestBlock), 10)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			innerStore := storage.NewMemoryConnector(10)
+			rows := make(map[uint64][]interface{})
+			for height := uint64(0); height < 3; height++ {
+				rows[height] = []interface{}{&legacyTestBlock{Number: fmt.Sprint(height), Hash: fmt.Sprintf("0x%x", height)}}
+			}
+			if err := tt.write(ctx, innerStore, rows); err != nil {
+				t.Fatal(err)
+			}
+
+			s := newTestStore(&Config{WriteMode: storage.WriteModeBlock}, innerStore)
+			for height := uint64(0); height < 3; height++ {
+				hash, err := s.RetrieveBlockHash(ctx, height)
+				if err != nil {
+					t.Fatalf("retrieve %d: %v", height, err)
+				}
+				if hash != fmt.Sprintf("0x%x", height) {
+					t.Errorf("hash of %d = %s", height, hash)
+				}
+
+				got, err := s.read(ctx, EntityBlocks, height)
+				if err != nil {
+					t.Fatalf("read %d: %v", height, err)
+				}
+				want := []interface{}{&testBlock{Number: fmt.Sprint(height), Hash: fmt.Sprintf("0x%x", height)}}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("rows of %d = %+v, want %+v", height, got, want)
+				}
+			}
+
+			//	Replacing a height rewrites its file with every column of the current model
+			replaced := &testBlock{Number: "1", Hash: "0xnew", BlobGasUsed: "0x20000"}
+			if err := s.replace(ctx, EntityBlocks, 1, []interface{}{replaced}); err != nil {
+				t.Fatal(err)
+			}
+			for height, wantHash := range map[uint64]string{0: "0x0", 1: "0xnew", 2: "0x2"} {
+				if hash, err := s.RetrieveBlockHash(ctx, height); err != nil || hash != wantHash {
+					t.Errorf("hash of %d = %s, %v; want %s", height, hash, err, wantHash)
+				}
+			}
+			if got, err := s.read(ctx, EntityBlocks, 1); err != nil || !reflect.DeepEqual(got, []interface{}{replaced}) {
+				t.Errorf("rows of 1 = %+v, %v; want %+v", got, err, replaced)
+			}
+		})
+	}
+}
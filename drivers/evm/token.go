@@ -0,0 +1,209 @@
+package evm
+
+import (
+	"fmt"
+	model "github.com/coherentopensource/evm-etl/model/evm"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+)
+
+// Token standards, as written to the token_standard column
+const (
+	TokenStandardERC20   = "erc20"
+	TokenStandardERC721  = "erc721"
+	TokenStandardERC1155 = "erc1155"
+)
+
+// Topics of the events token transfers are decoded from
+const (
+	// Transfer(address indexed from, address indexed to, uint256 value), or uint256 indexed tokenId for ERC-721
+	topicTransfer = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// TransferSingle(address indexed operator, address indexed from, address indexed to, uint256 id, uint256 value)
+	topicTransferSingle = "0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62"
+	// TransferBatch(address indexed operator, address indexed from, address indexed to, uint256[] ids, uint256[] values)
+	topicTransferBatch = "0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb"
+)
+
+// wordLength is the size of an ABI-encoded word, and so of a topic
+const wordLength = 32
+
+// tokenAmount is a single transfer of a log; id is nil for ERC-20 transfers
+type tokenAmount struct {
+	id     *big.Int
+	amount *big.Int
+}
+
+// TokenTransfersToParquet decodes the token transfers a log records, skipping logs not laid out as the standard events
+func TokenTransfersToParquet[L Log](log L) []*model.ParquetTokenTransfer {
+	topics := log.GetTopics()
+	if len(topics) == 0 {
+		return nil
+	}
+	var data []byte
+	if log.GetData() != "" {
+		var err error
+		if data, err = hexutil.Decode(log.GetData()); err != nil || len(data)%wordLength != 0 {
+			return nil
+		}
+	}
+
+	var standard string
+	var amounts []tokenAmount
+	switch strings.ToLower(topics[0]) {
+	case topicTransfer:
+		//	ERC-20 and ERC-721 share Transfer, and are told apart by whether its third argument is indexed
+		if len(topics) == 3 && len(data) == wordLength {
+			standard, amounts = TokenStandardERC20, []tokenAmount{{amount: word(data, 0)}}
+		} else if id, ok := topicWord(topics); ok && len(data) == 0 {
+			standard, amounts = TokenStandardERC721, []tokenAmount{{id: id, amount: big.NewInt(1)}}
+		}
+	case topicTransferSingle:
+		if len(topics) == 4 && len(data) == 2*wordLength {
+			standard, amounts = TokenStandardERC1155, []tokenAmount{{id: word(data, 0), amount: word(data, 1)}}
+		}
+	case topicTransferBatch:
+		ids, idsOK := wordArray(data, 0)
+		values, valuesOK := wordArray(data, 1)
+		if len(topics) == 4 && idsOK && valuesOK && len(ids) == len(values) {
+			standard = TokenStandardERC1155
+			for i := range ids {
+				amounts = append(amounts, tokenAmount{id: ids[i], amount: values[i]})
+			}
+		}
+	}
+	if standard == "" {
+		return nil
+	}
+
+	//	ERC-1155 events name the operator ahead of the sender and recipient
+	var operator string
+	parties := topics[1:3]
+	if standard == TokenStandardERC1155 {
+		var ok bool
+		if operator, ok = topicAddress(topics[1]); !ok {
+			return nil
+		}
+		parties = topics[2:4]
+	}
+	from, fromOK := topicAddress(parties[0])
+	to, toOK := topicAddress(parties[1])
+	if !fromOK || !toOK {
+		return nil
+	}
+
+	var outputs []*model.ParquetTokenTransfer
+	for i, transfer := range amounts {
+		out := model.ParquetTokenTransfer{
+			BlockNumber:      log.GetBlockNumber(),
+			BlockHash:        log.GetBlockHash(),
+			TransactionHash:  log.GetTransactionHash(),
+			TransactionIndex: log.GetTransactionIndex(),
+			LogIndex:         log.GetLogIndex(),
+			BatchIndex:       int64(i),
+			TokenStandard:    standard,
+			TokenAddress:     log.GetAddress(),
+			Operator:         operator,
+			FromAddress:      from,
+			ToAddress:        to,
+		}
+		var failures []string
+		if transfer.id != nil {
+			out.TokenID = tokenDecimal("token_id", transfer.id, &failures)
+		}
+		out.Amount = tokenDecimal("amount", transfer.amount, &failures)
+		out.DecodeError = strings.Join(failures, "; ")
+		outputs = append(outputs, &out)
+	}
+	return outputs
+}
+
+// ParquetTokenTransferToV2 converts a token transfer from the hex schema to the typed schema
+func ParquetTokenTransferToV2(in *model.ParquetTokenTransfer) (*model.ParquetTokenTransferV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTokenTransferV2{
+		BlockNumber:      c.Int64("block_number", in.BlockNumber),
+		BlockHash:        in.BlockHash,
+		TransactionHash:  in.TransactionHash,
+		TransactionIndex: c.Int64("transaction_index", in.TransactionIndex),
+		LogIndex:         c.Int64("log_index", in.LogIndex),
+		BatchIndex:       in.BatchIndex,
+		TokenStandard:    in.TokenStandard,
+		TokenAddress:     in.TokenAddress,
+		Operator:         in.Operator,
+		FromAddress:      in.FromAddress,
+		ToAddress:        in.ToAddress,
+		TokenID:          in.TokenID,
+		Amount:           in.Amount,
+		DecodeError:      in.DecodeError,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert token transfer to typed schema")
+	}
+
+	return &out, nil
+}
+
+// tokenDecimal converts a token ID or amount to a DECIMAL(76, 0), adding the reason to failures if it does not fit
+func tokenDecimal(column string, n *big.Int, failures *[]string) *string {
+	d, err := util.HexToDecimal76(hexutil.EncodeBig(n))
+	if err != nil {
+		*failures = append(*failures, fmt.Sprintf("%s: %v", column, err))
+		return nil
+	}
+	return &d
+}
+
+// word returns the i-th word of ABI-encoded data as an unsigned integer
+func word(data []byte, i int) *big.Int {
+	return new(big.Int).SetBytes(data[i*wordLength : (i+1)*wordLength])
+}
+
+// topicWord returns the token ID an ERC-721 Transfer indexes as its fourth topic
+func topicWord(topics []string) (*big.Int, bool) {
+	if len(topics) != 4 {
+		return nil, false
+	}
+	b, err := hexutil.Decode(topics[3])
+	if err != nil || len(b) != wordLength {
+		return nil, false
+	}
+	return new(big.Int).SetBytes(b), true
+}
+
+// topicAddress returns the address an indexed address argument holds
+func topicAddress(topic string) (string, bool) {
+	const padding = 2 + 2*(wordLength-20)
+	if len(topic) != 2+2*wordLength || strings.Trim(topic[2:padding], "0") != "" {
+		return "", false
+	}
+	if _, err := hexutil.Decode(topic); err != nil {
+		return "", false
+	}
+	return "0x" + strings.ToLower(topic[padding:]), true
+}
+
+// wordArray decodes the uint256[] whose offset is the i-th word of ABI-encoded data
+func wordArray(data []byte, i int) ([]*big.Int, bool) {
+	words := len(data) / wordLength
+	if i >= words {
+		return nil, false
+	}
+	offset := word(data, i)
+	if !offset.IsUint64() || offset.Uint64()%wordLength != 0 || offset.Uint64()/wordLength >= uint64(words) {
+		return nil, false
+	}
+	start := int(offset.Uint64() / wordLength)
+	length := word(data, start)
+	if !length.IsUint64() || length.Uint64() > uint64(words-start-1) {
+		return nil, false
+	}
+
+	out := make([]*big.Int, length.Uint64())
+	for j := range out {
+		out[j] = word(data, start+1+j)
+	}
+	return out, true
+}
@@ -0,0 +1,152 @@
+package evm
+
+import (
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/rpc"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testOperator = "0x1111111111111111111111111111111111111111"
+	testFrom     = "0x2222222222222222222222222222222222222222"
+	testTo       = "0x3333333333333333333333333333333333333333"
+)
+
+// addressTopic pads an address to a word, as indexed address arguments are
+func addressTopic(address string) string {
+	return "0x" + strings.Repeat("0", 24) + address[2:]
+}
+
+// words ABI-encodes hex quantities as consecutive words, each given without its 0x prefix
+func words(quantities ...string) string {
+	out := "0x"
+	for _, q := range quantities {
+		out += fmt.Sprintf("%064s", q)
+	}
+	return out
+}
+
+// tokenTransfer is the part of a token transfer a test checks, with token ID and amount as hex, or empty for null
+type tokenTransfer struct {
+	standard    string
+	operator    string
+	batchIndex  int64
+	tokenID     string
+	amount      string
+	decodeError string
+}
+
+func decimalHex(d *string) string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("0x%x", util.DecimalToBig(*d))
+}
+
+func TestTokenTransfersToParquet(t *testing.T) {
+	// overflow is 2^256-1, which has 78 decimal digits
+	overflow := strings.Repeat("f", 64)
+
+	tests := []struct {
+		name   string
+		topics []string
+		data   string
+		want   []tokenTransfer
+	}{
+		{
+			name:   "erc20",
+			topics: []string{topicTransfer, addressTopic(testFrom), addressTopic(testTo)},
+			data:   words("2a"),
+			want:   []tokenTransfer{{standard: TokenStandardERC20, amount: "0x2a"}},
+		},
+		{
+			name:   "erc721",
+			topics: []string{topicTransfer, addressTopic(testFrom), addressTopic(testTo), words("7")},
+			want:   []tokenTransfer{{standard: TokenStandardERC721, tokenID: "0x7", amount: "0x1"}},
+		},
+		{
+			name:   "erc721 with an ID above DECIMAL(76)",
+			topics: []string{topicTransfer, addressTopic(testFrom), addressTopic(testTo), words(overflow)},
+			want: []tokenTransfer{{
+				standard:    TokenStandardERC721,
+				amount:      "0x1",
+				decodeError: fmt.Sprintf(`token_id: quantity "0x%s" overflows DECIMAL(76, 0)`, overflow),
+			}},
+		},
+		{
+			name:   "erc1155 single",
+			topics: []string{topicTransferSingle, addressTopic(testOperator), addressTopic(testFrom), addressTopic(testTo)},
+			data:   words("5", "3"),
+			want:   []tokenTransfer{{standard: TokenStandardERC1155, operator: testOperator, tokenID: "0x5", amount: "0x3"}},
+		},
+		{
+			name:   "erc1155 batch",
+			topics: []string{topicTransferBatch, addressTopic(testOperator), addressTopic(testFrom), addressTopic(testTo)},
+			data:   words("40", "a0", "2", "5", overflow, "2", "3", overflow),
+			want: []tokenTransfer{
+				{standard: TokenStandardERC1155, operator: testOperator, tokenID: "0x5", amount: "0x3"},
+				{
+					standard:   TokenStandardERC1155,
+					operator:   testOperator,
+					batchIndex: 1,
+					decodeError: fmt.Sprintf(`token_id: quantity "0x%s" overflows DECIMAL(76, 0); amount: quantity "0x%s" overflows DECIMAL(76, 0)`,
+						overflow, overflow),
+				},
+			},
+		},
+		{
+			name:   "transfer with an unindexed sender",
+			topics: []string{topicTransfer, addressTopic(testFrom)},
+			data:   words("2a"),
+		},
+		{
+			name:   "batch with more ids than values",
+			topics: []string{topicTransferBatch, addressTopic(testOperator), addressTopic(testFrom), addressTopic(testTo)},
+			data:   words("40", "a0", "2", "5", "6", "1", "3"),
+		},
+		{
+			name:   "other event",
+			topics: []string{"0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"},
+			data:   words("2a"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &rpc.Log{LogIndex: "0x0", TransactionIndex: "0x0", BlockNumber: "0x1", Address: "0xt0ken", Topics: tt.topics, Data: tt.data}
+			rows := TokenTransfersToParquet(log)
+
+			var got []tokenTransfer
+			for _, row := range rows {
+				if row.FromAddress != testFrom || row.ToAddress != testTo {
+					t.Errorf("transfer from %s to %s, want %s to %s", row.FromAddress, row.ToAddress, testFrom, testTo)
+				}
+				got = append(got, tokenTransfer{
+					standard:    row.TokenStandard,
+					operator:    row.Operator,
+					batchIndex:  row.BatchIndex,
+					tokenID:     decimalHex(row.TokenID),
+					amount:      decimalHex(row.Amount),
+					decodeError: row.DecodeError,
+				})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transfers = %+v, want %+v", got, tt.want)
+			}
+
+			//	The typed schema carries the same values
+			for _, row := range rows {
+				v2, err := ParquetTokenTransferToV2(row)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(v2.TokenID, row.TokenID) || !reflect.DeepEqual(v2.Amount, row.Amount) || v2.DecodeError != row.DecodeError {
+					t.Errorf("typed transfer = %+v, want the values of %+v", v2, row)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package evm
+
+import (
+	"context"
+)
+
+// IsValidBlock checks the given block's parent hash against the hash of the previous block; on a mismatch it recovers
+// from the reorg, failing only if it cannot
+func (d *Driver[B, Tx, R, L, T]) IsValidBlock(ctx context.Context, index uint64) error {
+	d.logger.Infof("comparing block %d to block %d for validation", index, index-1)
+
+	currentBlock, err := d.getBlockByNumber(ctx, index)
+	if err != nil {
+		return err
+	}
+	previousHash, err := d.store.RetrieveBlockHash(ctx, index-1)
+	if err != nil {
+		return err
+	}
+
+	if currentBlock.GetParentHash() != previousHash {
+		d.logger.Infof("chain reorg detected at block %d", index-1)
+		return d.recoverReorg(ctx, index)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package evm
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/shared/roots"
+	"github.com/coherentopensource/go-service-framework/retry"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// verifiesRoots reports whether the chain can recompute the roots a block's header commits to
+func (c *Chain[B, Tx, R, L, T]) verifiesRoots() bool {
+	return c.Roots != nil && c.RootTransaction != nil && c.RootReceipt != nil
+}
+
+// verifiedBlockData checks a block's transactions and receipts against the roots its header commits to. A block that
+// fails is rejected, and it and its receipts fetched again until they pass or the retries run out; should another
+// block have taken its place, its traces are fetched again too
+func (d *Driver[B, Tx, R, L, T]) verifiedBlockData(ctx context.Context, block B, receipts []R, traces []T) (B, []R, []T, error) {
+	err := d.verifyRoots(block, receipts)
+	if err == nil {
+		return block, receipts, traces, nil
+	}
+
+	height, parseErr := strconv.ParseUint(strings.TrimPrefix(block.GetNumber(), "0x"), 16, 64)
+	if parseErr != nil {
+		return block, nil, nil, errors.Wrapf(parseErr, "invalid block number %q", block.GetNumber())
+	}
+	rejected := block.GetHash()
+	if retryErr := retry.Exec(d.config.MaxRetries, func() error {
+		d.logger.Warnf("rejecting block %d: %v", height, err)
+		if block, receipts, err = d.fetchBlockAndReceipts(ctx, height); err != nil {
+			return err
+		}
+		err = d.verifyRoots(block, receipts)
+		return err
+	}, nil); retryErr != nil {
+		d.logger.Errorf("max retries exceeded trying to verify block %d: %v", height, retryErr)
+		return block, nil, nil, retryErr
+	}
+
+	//	Traces were fetched alongside the rejected block, so describe it rather than the block that passed
+	if !d.chain.NoTraces && block.GetHash() != rejected {
+		if traces, err = d.getBlockTraceByNumber(ctx, height); err != nil {
+			return block, nil, nil, err
+		}
+	}
+	return block, receipts, traces, nil
+}
+
+// verifyRoots recomputes a block's transactions and receipts roots from the transactions and receipts fetched for it,
+// and compares them with those its header commits to
+func (d *Driver[B, Tx, R, L, T]) verifyRoots(block B, receipts []R) error {
+	transactions := block.GetTransactions()
+	if len(transactions) != len(receipts) {
+		return errors.Errorf("block %s has %d transactions but %d receipts", block.GetNumber(), len(transactions), len(receipts))
+	}
+
+	rootTransactions := make([]*roots.Transaction, len(transactions))
+	for i, tx := range transactions {
+		rootTransactions[i] = d.chain.RootTransaction(tx)
+	}
+	rootReceipts := make([]*roots.Receipt, len(receipts))
+	for i, receipt := range receipts {
+		rootReceipts[i] = d.chain.RootReceipt(receipt)
+	}
+
+	transactionsRoot, receiptsRoot := d.chain.Roots(block)
+	computed, err := roots.TransactionsRoot(rootTransactions)
+	if err != nil {
+		return errors.Wrapf(err, "cannot recompute transactions root of block %s", block.GetNumber())
+	}
+	if !strings.EqualFold(computed, transactionsRoot) {
+		return errors.Errorf("transactions root mismatch for block %s: header has %s, transactions hash to %s", block.GetNumber(), transactionsRoot, computed)
+	}
+	computed, err = roots.ReceiptsRoot(rootReceipts)
+	if err != nil {
+		return errors.Wrapf(err, "cannot recompute receipts root of block %s", block.GetNumber())
+	}
+	if !strings.EqualFold(computed, receiptsRoot) {
+		return errors.Errorf("receipts root mismatch for block %s: header has %s, receipts hash to %s", block.GetNumber(), receiptsRoot, computed)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package evm_test
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAccumulateVerifiesRoots(t *testing.T) {
+	tests := []struct {
+		name string
+		// truncated drops the receipts the node first returns, so that the block fails verification
+		truncated bool
+		// refetched is the fork the node returns once the block is fetched again
+		refetched string
+		wantFork  string
+	}{
+		{name: "valid block", refetched: "a", wantFork: "a"},
+		//	The same block comes back, so the traces fetched with it are kept; the node has none left to fetch again
+		{name: "truncated receipts", truncated: true, refetched: "a", wantFork: "a"},
+		{name: "block replaced", truncated: true, refetched: "b", wantFork: "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			first, second := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+			writeFork(t, first, "a", map[uint64]string{5: "a"})
+			writeFork(t, second, tt.refetched, map[uint64]string{5: "a"})
+			if tt.truncated {
+				if err := os.WriteFile(filepath.Join(first, "block_receipts", "5.json"), []byte(`{"jsonrpc":"2.0","id":1,"result":[]}`), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.refetched == "a" {
+				if err := os.Remove(filepath.Join(second, "traces", "5.json")); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			client := &forkClient{fixture.NewClient(first)}
+			cfg := evm.Config{MaxRetries: 1, DirectoryRange: 10000, WriteMode: storage.WriteModeBlock, SchemaVersion: 1, VerifyRoots: true}
+			d := ethereum.New(&cfg, client, storage.NewMemoryConnector(10000), nopLogger{})
+			set := pool.ResultSet{}
+			for stage, runner := range d.FetchSequence(5) {
+				res, err := runner(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				set[stage] = res
+			}
+
+			client.Client = fixture.NewClient(second)
+			res, err := d.Accumulate(set)(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := res.(*ethereum.Data)
+			if got, want := data.Block.GetHash(), blockHash(5, tt.wantFork); got != want {
+				t.Errorf("block = %s, want %s", got, want)
+			}
+			var inputs []string
+			for _, trace := range data.CallTraces {
+				inputs = append(inputs, trace.Input)
+			}
+			if want := []string{"0x0" + tt.wantFork}; !reflect.DeepEqual(inputs, want) {
+				t.Errorf("traces have inputs %v, want %v", inputs, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package evm
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	nullAddress = "0x0000000000000000000000000000000000000000"
+)
+
+// entityWriter converts a block to the rows of the entity it writes; rows are nil if the block has none to write
+type entityWriter[D any] struct {
+	entity string
+	rows   func(ctx context.Context, block D, blockNumber uint64) ([]interface{}, error)
+}
+
+// Writers defines a set of parallelizable write steps for processing a block and its children; a trace backfill
+// writes traces alone
+func (d *Driver[B, Tx, R, L, T]) Writers() []pool.FeedTransformer {
+	var writers []pool.FeedTransformer
+	for _, w := range d.entityWriters() {
+		writers = append(writers, d.writeStep(w))
+	}
+	return writers
+}
+
+// entityWriters defines the write steps of Writers, each along with the entity it writes
+func (d *Driver[B, Tx, R, L, T]) entityWriters() []entityWriter[*Data[B, R, T]] {
+	if d.config.IsTraceBackfill {
+		if d.chain.NoTraces {
+			return nil
+		}
+		return []entityWriter[*Data[B, R, T]]{{EntityTraces, d.backfillTraceRows}}
+	}
+	return d.allEntityWriters()
+}
+
+// allEntityWriters defines a write step for every entity the driver writes, whether or not it is backfilling traces
+func (d *Driver[B, Tx, R, L, T]) allEntityWriters() []entityWriter[*Data[B, R, T]] {
+	writers := []entityWriter[*Data[B, R, T]]{
+		{EntityBlocks, d.blockRows},
+		{EntityTransactions, d.transactionRows},
+	}
+	if !d.chain.NoTraces {
+		writers = append(writers, entityWriter[*Data[B, R, T]]{EntityTraces, d.traceRows})
+	}
+	writers = append(writers, entityWriter[*Data[B, R, T]]{EntityLogs, d.logRows})
+	writers = append(writers, entityWriter[*Data[B, R, T]]{EntityContracts, d.contractRows}, entityWriter[*Data[B, R, T]]{EntityNativeTransfers, d.nativeTransferRows})
+	if d.config.TokenTransfers {
+		writers = append(writers, entityWriter[*Data[B, R, T]]{EntityTokenTransfers, d.tokenTransferRows})
+	}
+	if d.decoder != nil {
+		writers = append(writers, entityWriter[*Data[B, R, T]]{EntityDecodedEvents, d.decodedEventRows})
+		if !d.chain.NoTraces {
+			writers = append(writers, entityWriter[*Data[B, R, T]]{EntityDecodedCalls, d.decodedCallRows})
+		}
+	}
+	for _, entity := range d.chain.Entities {
+		if entity.Rows != nil {
+			rows := entity.Rows
+			writers = append(writers, entityWriter[*Data[B, R, T]]{entity.Name, func(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+				return rows(block)
+			}})
+		}
+	}
+	return writers
+}
+
+// Flush writes any rows still buffered in batch mode; it should be called on shutdown, once the writers have drained
+func (d *Driver[B, Tx, R, L, T]) Flush(ctx context.Context) error {
+	return d.store.flush(ctx)
+}
+
+// writeStep returns the write step that writes an entity's rows for a block, or records it as skipped if there are none
+func (d *Driver[B, Tx, R, L, T]) writeStep(w entityWriter[*Data[B, R, T]]) pool.FeedTransformer {
+	return func(res interface{}) pool.Runner {
+		return func(ctx context.Context) (interface{}, error) {
+			block, blockNumber, err := d.unpackBlock(res)
+			if err != nil {
+				return nil, err
+			}
+
+			outputs, err := w.rows(ctx, block, blockNumber)
+			if err != nil {
+				return nil, err
+			}
+			if outputs == nil {
+				return nil, d.store.skip(ctx, w.entity, blockNumber)
+			}
+
+			if err := d.store.write(ctx, w.entity, blockNumber, outputs); err != nil {
+				return nil, err
+			}
+			d.logger.Infof("successfully parqueted %s for %d", w.entity, blockNumber)
+
+			return nil, nil
+		}
+	}
+}
+
+// blockRows converts a block to parquet
+func (d *Driver[B, Tx, R, L, T]) blockRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	return []interface{}{d.chain.BlockToParquet(block.Block)}, nil
+}
+
+// transactionRows converts a block's transactions to parquet
+func (d *Driver[B, Tx, R, L, T]) transactionRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	transactions := block.Block.GetTransactions()
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+	if len(transactions) != len(block.TransactionReceipts) {
+		return nil, errors.Errorf("block %d has %d transactions but %d receipts", blockNumber, len(transactions), len(block.TransactionReceipts))
+	}
+
+	var outputs []interface{}
+	for i, tx := range transactions {
+		parquetTransaction, err := d.chain.TransactionToParquet(tx, block.TransactionReceipts[i])
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, parquetTransaction)
+	}
+	return outputs, nil
+}
+
+// logRows converts the logs of a block's receipts to parquet
+func (d *Driver[B, Tx, R, L, T]) logRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	if len(block.Block.GetTransactions()) == 0 {
+		return nil, nil
+	}
+
+	//	A block with transactions but no logs is written without rows
+	outputs := []interface{}{}
+	for _, receipt := range block.TransactionReceipts {
+		for _, log := range receipt.GetLogs() {
+			outputs = append(outputs, d.chain.LogToParquet(log))
+		}
+	}
+	return outputs, nil
+}
+
+// contractRows converts the contracts deployed in a block, found from its traces or, for blocks without them, from its
+// receipts, to parquet
+func (d *Driver[B, Tx, R, L, T]) contractRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	var outputs []interface{}
+	transactions := block.Block.GetTransactions()
+	switch {
+	case len(transactions) == 0:
+	case len(block.CallTraces) > 0:
+		if _, err := tracedTransactions(block, blockNumber); err != nil {
+			return nil, err
+		}
+		for i, callTrace := range block.CallTraces {
+			tx := transactions[i]
+			frames := trace.Flatten(callTrace, int64(i), tx.GetBlockHash(), tx.GetHash())
+			reverted := revertedFrames(frames)
+			for _, frame := range frames {
+				if reverted[frame.Hash] {
+					continue
+				}
+				if contract := ContractToParquet(frame, tx, block.Block.GetNumber()); contract != nil {
+					outputs = append(outputs, contract)
+				}
+			}
+		}
+	default:
+		if len(transactions) != len(block.TransactionReceipts) {
+			return nil, errors.Errorf("block %d has %d transactions but %d receipts", blockNumber, len(transactions), len(block.TransactionReceipts))
+		}
+		for i, receipt := range block.TransactionReceipts {
+			if contract := ReceiptContractToParquet[Tx, R, L](receipt, transactions[i], block.Block.GetNumber()); contract != nil {
+				outputs = append(outputs, contract)
+			}
+		}
+	}
+	return outputs, nil
+}
+
+// nativeTransferRows converts the native currency a block moved to parquet: by the frames of its traces that did not
+// revert or, for blocks without them, by its transactions, and by its withdrawals
+func (d *Driver[B, Tx, R, L, T]) nativeTransferRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	var outputs []interface{}
+	transactions := block.Block.GetTransactions()
+	switch {
+	case len(transactions) == 0:
+	case len(block.CallTraces) > 0:
+		if _, err := tracedTransactions(block, blockNumber); err != nil {
+			return nil, err
+		}
+		for i, callTrace := range block.CallTraces {
+			tx := transactions[i]
+			frames := trace.Flatten(callTrace, int64(i), tx.GetBlockHash(), tx.GetHash())
+			reverted := revertedFrames(frames)
+			for _, frame := range frames {
+				//	A failed transaction's own transfer is kept, with its status
+				if frame.Depth > 0 && reverted[frame.Hash] {
+					continue
+				}
+				transfer, err := NativeTransferToParquet(frame, tx, block.Block.GetNumber())
+				if err != nil {
+					return nil, err
+				}
+				if transfer != nil {
+					outputs = append(outputs, transfer)
+				}
+			}
+		}
+	default:
+		if len(transactions) != len(block.TransactionReceipts) {
+			return nil, errors.Errorf("block %d has %d transactions but %d receipts", blockNumber, len(transactions), len(block.TransactionReceipts))
+		}
+		for i, tx := range transactions {
+			transfer, err := TransactionNativeTransferToParquet[Tx, R, L](tx, block.TransactionReceipts[i], block.Block.GetNumber())
+			if err != nil {
+				return nil, err
+			}
+			if transfer != nil {
+				outputs = append(outputs, transfer)
+			}
+		}
+	}
+	if d.chain.Withdrawals != nil {
+		for _, withdrawal := range d.chain.Withdrawals(block.Block) {
+			transfer, err := WithdrawalNativeTransferToParquet(withdrawal, block.Block.GetNumber(), block.Block.GetHash())
+			if err != nil {
+				return nil, err
+			}
+			if transfer != nil {
+				outputs = append(outputs, transfer)
+			}
+		}
+	}
+	return outputs, nil
+}
+
+// tokenTransferRows converts the token transfers decoded from a block's logs to parquet
+func (d *Driver[B, Tx, R, L, T]) tokenTransferRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	var outputs []interface{}
+	for _, receipt := range block.TransactionReceipts {
+		for _, log := range receipt.GetLogs() {
+			for _, transfer := range TokenTransfersToParquet(log) {
+				outputs = append(outputs, transfer)
+			}
+		}
+	}
+	return outputs, nil
+}
+
+// decodedEventRows converts the logs of a block the configured ABIs decode to parquet
+func (d *Driver[B, Tx, R, L, T]) decodedEventRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	var outputs []interface{}
+	for _, receipt := range block.TransactionReceipts {
+		for _, log := range receipt.GetLogs() {
+			if event := DecodedEventToParquet(d.decoder, log); event != nil {
+				outputs = append(outputs, event)
+			}
+		}
+	}
+	return outputs, nil
+}
+
+// decodedCallRows converts the call frames of a block the configured ABIs decode to parquet
+func (d *Driver[B, Tx, R, L, T]) decodedCallRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	if len(block.Block.GetTransactions()) == 0 || len(block.CallTraces) == 0 {
+		return nil, nil
+	}
+	transactions, err := tracedTransactions(block, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for i, callTrace := range block.CallTraces {
+		tx := transactions[i]
+		for _, frame := range trace.Flatten(callTrace, int64(i), tx.GetBlockHash(), tx.GetHash()) {
+			if call := DecodedCallToParquet(d.decoder, frame, block.Block.GetNumber(), tx.GetBlockHash(), tx.GetHash()); call != nil {
+				outputs = append(outputs, call)
+			}
+		}
+	}
+	return outputs, nil
+}
+
+// traceRows converts a block's call traces to parquet
+func (d *Driver[B, Tx, R, L, T]) traceRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	if len(block.Block.GetTransactions()) == 0 || len(block.CallTraces) == 0 {
+		return nil, nil
+	}
+	transactions, err := tracedTransactions(block, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for i, callTrace := range block.CallTraces {
+		tx := transactions[i]
+		for _, frame := range trace.Flatten(callTrace, int64(i), tx.GetBlockHash(), tx.GetHash()) {
+			outputs = append(outputs, d.chain.TraceToParquet(frame, tx))
+		}
+	}
+	return outputs, nil
+}
+
+// backfillTraceRows converts a block's call traces to parquet, unless they have already been written
+func (d *Driver[B, Tx, R, L, T]) backfillTraceRows(ctx context.Context, block *Data[B, R, T], blockNumber uint64) ([]interface{}, error) {
+	hasTrace, err := d.store.CheckForTrace(ctx, blockNumber)
+	if err == nil && hasTrace {
+		return nil, nil
+	}
+	return d.traceRows(ctx, block, blockNumber)
+}
+
+// unpackBlock pulls a block out of the generic response from the accumulator
+func (d *Driver[B, Tx, R, L, T]) unpackBlock(res interface{}) (*Data[B, R, T], uint64, error) {
+	obj, ok := res.(*Data[B, R, T])
+	if !ok {
+		return nil, 0, errors.New("result is not correct type")
+	}
+
+	hexBlockNumber := strings.Replace(obj.Block.GetNumber(), "0x", "", -1)
+	blockNumber, err := strconv.ParseInt(hexBlockNumber, 16, 64)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return obj, uint64(blockNumber), nil
+}
+
+// tracedTransactions returns the transactions a block's call traces are paired with, by index, once the traces have
+// been checked to number as many as the transactions that are traced
+func tracedTransactions[B Block[Tx], Tx Transaction, R any, T any](block *Data[B, R, T], blockNumber uint64) ([]Tx, error) {
+	//	Filter null=>null transactions and ensure transaction and trace counts match
+	transactions := block.Block.GetTransactions()
+	filteredTx := filterNonTraceTransactions(transactions)
+	if len(filteredTx) != len(block.CallTraces) {
+		return nil, errors.Errorf("transactions and traces count don't match for block: %d %d != %d", blockNumber, len(filteredTx), len(block.CallTraces))
+	}
+	return transactions, nil
+}
+
+func filterNonTraceTransactions[Tx Transaction](in []Tx) []Tx {
+	var filteredTransactions []Tx
+	for _, tx := range in {
+		if tx.GetFrom() != nullAddress || tx.GetTo() != nullAddress {
+			filteredTransactions = append(filteredTransactions, tx)
+		}
+	}
+	return filteredTransactions
+}
@@ -0,0 +1,90 @@
+package evm
+
+import (
+	"testing"
+)
+
+type testTx struct {
+	hash string
+	from string
+	to   string
+}
+
+func (t *testTx) GetHash() string      { return t.hash }
+func (t *testTx) GetBlockHash() string { return "0xb" }
+func (t *testTx) GetFrom() string      { return t.from }
+func (t *testTx) GetTo() string        { return t.to }
+func (t *testTx) GetValue() string     { return "0x0" }
+
+type testTxBlock struct {
+	transactions []*testTx
+}
+
+func (b *testTxBlock) GetNumber() string          { return "0x1" }
+func (b *testTxBlock) GetHash() string            { return "0xb" }
+func (b *testTxBlock) GetParentHash() string      { return "0xa" }
+func (b *testTxBlock) GetTransactions() []*testTx { return b.transactions }
+
+func TestTracedTransactions(t *testing.T) {
+	transfer := func(hash string) *testTx { return &testTx{hash: hash, from: "0x01", to: "0x02"} }
+	null := func(hash string) *testTx { return &testTx{hash: hash, from: nullAddress, to: nullAddress} }
+
+	tests := []struct {
+		name         string
+		transactions []*testTx
+		traces       int
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name:         "every transaction traced",
+			transactions: []*testTx{transfer("0x1"), transfer("0x2")},
+			traces:       2,
+			want:         []string{"0x1", "0x2"},
+		},
+		{
+			//	Traces are paired with the block's transactions by index, as they always have been
+			name:         "null transaction last",
+			transactions: []*testTx{transfer("0x1"), transfer("0x2"), null("0x3")},
+			traces:       2,
+			want:         []string{"0x1", "0x2", "0x3"},
+		},
+		{
+			name:         "more traces than traced transactions",
+			transactions: []*testTx{transfer("0x1"), null("0x2")},
+			traces:       2,
+			wantErr:      true,
+		},
+		{
+			name:         "fewer traces than traced transactions",
+			transactions: []*testTx{transfer("0x1"), transfer("0x2")},
+			traces:       1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := &Data[*testTxBlock, struct{}, struct{}]{
+				Block:      &testTxBlock{transactions: tt.transactions},
+				CallTraces: make([]struct{}, tt.traces),
+			}
+			transactions, err := tracedTransactions(block, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(transactions) != len(tt.want) {
+				t.Fatalf("got %d transactions, want %d", len(transactions), len(tt.want))
+			}
+			for i, tx := range transactions {
+				if tx.GetHash() != tt.want[i] {
+					t.Errorf("transaction %d = %s, want %s", i, tx.GetHash(), tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package generic
+
+import (
+	"github.com/caarlos0/env/v7"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/coherentopensource/go-service-framework/util"
+)
+
+// Config stores configurable properties of the driver
+type Config struct {
+	evm.Config
+	DescriptorPath string `env:"CHAIN_DESCRIPTOR,required"`
+}
+
+// MustParseConfig uses env.Parse to initialize config with environment variables
+func MustParseConfig(logger util.Logger) *Config {
+	var cfg Config
+	if err := env.Parse(&cfg); err != nil {
+		logger.Fatalf("could not parse generic EVM driver config: %v", err)
+	}
+
+	return &cfg
+}
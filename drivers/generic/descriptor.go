@@ -0,0 +1,159 @@
+package generic
+
+import (
+	"encoding/json"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/pkg/errors"
+	"os"
+)
+
+// Receipt methods a descriptor may choose between
+const (
+	ReceiptMethodBlock       = "eth_getBlockReceipts"
+	ReceiptMethodTransaction = "eth_getTransactionReceipt"
+)
+
+// Trace methods a descriptor may choose between; TraceMethodNone is for nodes without a tracing API, and writes no
+// traces at all
+const (
+	TraceMethodDebug = "debug_traceBlockByNumber"
+	TraceMethodNone  = "none"
+)
+
+// optionalBlockFields are the block columns that not every EVM chain has, and that are only written when the
+// descriptor lists them
+var optionalBlockFields = []string{
+	"nonce",
+	"sha3_uncles",
+	"difficulty",
+	"total_difficulty",
+	"uncles",
+	"base_fee_per_gas",
+	"mix_hash",
+	"withdrawals_root",
+	"blob_gas_used",
+	"excess_blob_gas",
+	"parent_beacon_block_root",
+}
+
+// optionalTransactionFields are the transaction columns that not every EVM chain has, and that are only written when
+// the descriptor lists them
+var optionalTransactionFields = []string{
+	"type",
+	"v",
+	"r",
+	"s",
+	"effective_gas_price",
+	"max_fee_per_gas",
+	"max_priority_fee_per_gas",
+	"access_list",
+	"max_fee_per_blob_gas",
+	"blob_versioned_hashes",
+	"blob_gas_price",
+}
+
+// Descriptor describes an EVM chain well enough to index it without a driver of its own
+type Descriptor struct {
+	// Name is the chain's name, as reported by Blockchain and matched against BLOCKCHAIN
+	Name string `json:"name"`
+	// ChainID is checked against the node's eth_chainId before anything is fetched
+	ChainID uint64 `json:"chain_id"`
+	// ReceiptMethod is either ReceiptMethodBlock or ReceiptMethodTransaction
+	ReceiptMethod string `json:"receipt_method"`
+	// TraceMethod is either TraceMethodDebug or TraceMethodNone
+	TraceMethod string `json:"trace_method"`
+	// BlockFields and TransactionFields list the optional columns to write; see optionalBlockFields and
+	// optionalTransactionFields
+	BlockFields       []string `json:"block_fields"`
+	TransactionFields []string `json:"transaction_fields"`
+	// Withdrawals writes the beacon chain withdrawals of post-Shapella chains
+	Withdrawals bool `json:"withdrawals"`
+	// Blobs writes the versioned hashes of post-Dencun chains' blob transactions
+	Blobs bool `json:"blobs"`
+	// Finality is the chain's finality mode, as parsed by evm.ParseFinality; left out, the chain follows the latest
+	// block
+	Finality string `json:"finality"`
+}
+
+// LoadDescriptor reads and validates a JSON chain descriptor
+func LoadDescriptor(path string) (*Descriptor, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read chain descriptor %s", path)
+	}
+
+	var descriptor Descriptor
+	if err := json.Unmarshal(raw, &descriptor); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse chain descriptor %s", path)
+	}
+	if err := descriptor.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid chain descriptor %s", path)
+	}
+
+	return &descriptor, nil
+}
+
+// validate checks that every setting is one the generic driver supports
+func (d *Descriptor) validate() error {
+	if d.Name == "" {
+		return errors.New("name is required")
+	}
+	if d.ChainID == 0 {
+		return errors.New("chain_id is required")
+	}
+
+	switch d.ReceiptMethod {
+	case ReceiptMethodBlock, ReceiptMethodTransaction:
+	default:
+		return errors.Errorf("unsupported receipt_method %q; expected %s or %s", d.ReceiptMethod, ReceiptMethodBlock, ReceiptMethodTransaction)
+	}
+	switch d.TraceMethod {
+	case TraceMethodDebug, TraceMethodNone:
+	default:
+		return errors.Errorf("unsupported trace_method %q; expected %s or %s", d.TraceMethod, TraceMethodDebug, TraceMethodNone)
+	}
+
+	if err := checkFields(d.BlockFields, optionalBlockFields); err != nil {
+		return errors.Wrap(err, "block_fields")
+	}
+	if err := checkFields(d.TransactionFields, optionalTransactionFields); err != nil {
+		return errors.Wrap(err, "transaction_fields")
+	}
+	if d.Finality != "" {
+		if _, err := evm.ParseFinality(d.Finality); err != nil {
+			return errors.Wrap(err, "finality")
+		}
+	}
+
+	return nil
+}
+
+// checkFields makes sure every listed field is one of the optional ones
+func checkFields(fields []string, optional []string) error {
+	for _, field := range fields {
+		if !contains(optional, field) {
+			return errors.Errorf("%q is not an optional field; expected one of %v", field, optional)
+		}
+	}
+	return nil
+}
+
+// droppedFields returns the optional fields a descriptor does not list
+func droppedFields(fields []string, optional []string) map[string]bool {
+	dropped := make(map[string]bool)
+	for _, field := range optional {
+		if !contains(fields, field) {
+			dropped[field] = true
+		}
+	}
+	return dropped
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
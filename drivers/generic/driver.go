@@ -0,0 +1,160 @@
+// Package generic indexes any EVM chain from a chain descriptor file, for networks that stick to the standard
+// JSON-RPC block, receipt and trace formats and so need no driver or model of their own. Nodes are read with the
+// Ethereum node types, codec and models; fields a chain adds on top of those are ignored, and optional fields the chain
+// lacks are left out of its files by the descriptor, e.g.
+//
+//	{
+//		"name": "gnosis",
+//		"chain_id": 100,
+//		"receipt_method": "eth_getBlockReceipts",
+//		"trace_method": "debug_traceBlockByNumber",
+//		"block_fields": ["base_fee_per_gas", "withdrawals_root"],
+//		"transaction_fields": ["type", "v", "r", "s", "max_fee_per_gas", "max_priority_fee_per_gas"],
+//		"withdrawals": true,
+//		"finality": "finalized"
+//	}
+package generic
+
+import (
+	"context"
+	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/drivers/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/ethereum"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/go-service-framework/constants"
+	"github.com/coherentopensource/go-service-framework/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	entityWithdrawals = "withdrawals"
+	entityBlobs       = "blobs"
+)
+
+// Driver is the container for all ETL business logic
+type Driver = evm.Driver[*ethereum.Block, *ethereum.Transaction, *ethereum.TransactionReceipt, *ethereum.Log, *ethereum.CallTrace]
+
+// Data is a block together with its receipts and call traces, as handed to the writers
+type Data = evm.Data[*ethereum.Block, *ethereum.TransactionReceipt, *ethereum.CallTrace]
+
+// New constructs a new Driver for the chain described by the configured descriptor, after checking that the node
+// serves that chain
+func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger util.Logger) *Driver {
+	descriptor, err := LoadDescriptor(cfg.DescriptorPath)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+
+	//	A fixture client has no live connection to check against
+	if ethClient := nodeClient.GetEthClient(); ethClient != nil {
+		chainID, err := ethClient.ChainID(context.Background())
+		if err != nil {
+			logger.Fatalf("could not get chain ID from node: %v", err)
+		}
+		if chainID.Uint64() != descriptor.ChainID {
+			logger.Fatalf("node serves chain ID %d, but the %s descriptor expects %d", chainID.Uint64(), descriptor.Name, descriptor.ChainID)
+		}
+	}
+
+	return evm.New(newChain(descriptor), &cfg.Config, nodeClient, innerStore, logger)
+}
+
+// Models maps each entity directory of a described chain to the parquet model its files are written with in a schema
+// version, for tools that read the output back generically
+func Models(descriptor *Descriptor, schemaVersion int) map[string]interface{} {
+	return newChain(descriptor).Models(schemaVersion)
+}
+
+// newChain plugs the Ethereum node types and codec into the shared EVM driver, as configured by a descriptor
+func newChain(descriptor *Descriptor) *evm.Chain[*ethereum.Block, *ethereum.Transaction, *ethereum.TransactionReceipt, *ethereum.Log, *ethereum.CallTrace] {
+	droppedBlockFields := droppedFields(descriptor.BlockFields, optionalBlockFields)
+	blocks := newProjection(new(model.ParquetBlock), droppedBlockFields)
+	blocksV2 := newProjection(new(model.ParquetBlockV2), droppedBlockFields)
+
+	droppedTransactionFields := droppedFields(descriptor.TransactionFields, optionalTransactionFields)
+	transactions := newProjection(new(model.ParquetTransaction), droppedTransactionFields)
+	transactionsV2 := newProjection(new(model.ParquetTransactionV2), droppedTransactionFields)
+
+	chain := &evm.Chain[*ethereum.Block, *ethereum.Transaction, *ethereum.TransactionReceipt, *ethereum.Log, *ethereum.CallTrace]{
+		Blockchain:             constants.Blockchain(descriptor.Name),
+		Finality:               descriptor.Finality,
+		ReceiptsPerTransaction: descriptor.ReceiptMethod == ReceiptMethodTransaction,
+		NoTraces:               descriptor.TraceMethod == TraceMethodNone,
+
+		BlockToParquet: func(block *ethereum.Block) interface{} {
+			return blocks.narrowRow(ethereum.BlockToParquet(block))
+		},
+		TransactionToParquet: func(tx *ethereum.Transaction, receipt *ethereum.TransactionReceipt) (interface{}, error) {
+			row, err := ethereum.TransactionToParquet(tx, receipt)
+			if err != nil {
+				return nil, err
+			}
+			return transactions.narrowRow(row), nil
+		},
+		LogToParquet: func(log *ethereum.Log) interface{} {
+			return ethereum.LogToParquet(log)
+		},
+		TraceToParquet: func(frame trace.Frame[*ethereum.CallTrace], tx *ethereum.Transaction) interface{} {
+			return ethereum.TraceToParquet(frame, tx)
+		},
+		Roots:           ethereum.BlockRoots,
+		RootTransaction: ethereum.TransactionToRoots,
+		RootReceipt:     ethereum.ReceiptToRoots,
+
+		Entities: []evm.Entity[*Data]{
+			{Name: evm.EntityBlocks, Model: blocks.model(), ModelV2: blocksV2.model(), ToV2: typedProjection(blocks, blocksV2, ethereum.ParquetBlockToV2)},
+			{Name: evm.EntityTransactions, Model: transactions.model(), ModelV2: transactionsV2.model(), ToV2: typedProjection(transactions, transactionsV2, ethereum.ParquetTransactionToV2)},
+			{Name: evm.EntityLogs, Model: new(model.ParquetLog), ModelV2: new(model.ParquetLogV2), ToV2: evm.Typed(ethereum.ParquetLogToV2)},
+		},
+	}
+	if !chain.NoTraces {
+		chain.Entities = append(chain.Entities, evm.Entity[*Data]{Name: evm.EntityTraces, Model: new(model.ParquetTrace), ModelV2: new(model.ParquetTraceV2), ToV2: evm.Typed(ethereum.ParquetTraceToV2)})
+	}
+	if descriptor.Withdrawals {
+		chain.Entities = append(chain.Entities, evm.Entity[*Data]{Name: entityWithdrawals, Model: new(model.ParquetWithdrawal), ModelV2: new(model.ParquetWithdrawalV2), ToV2: evm.Typed(ethereum.ParquetWithdrawalToV2), Rows: withdrawalRows})
+		chain.Withdrawals = ethereum.NativeWithdrawals
+	}
+	if descriptor.Blobs {
+		chain.Entities = append(chain.Entities, evm.Entity[*Data]{Name: entityBlobs, Model: new(model.ParquetBlob), ModelV2: new(model.ParquetBlobV2), ToV2: evm.Typed(ethereum.ParquetBlobToV2), Rows: blobRows})
+	}
+
+	return chain
+}
+
+// typedProjection converts a narrowed row of the hex schema to a narrowed row of the typed schema, by way of the full
+// models the codec works with
+func typedProjection[In any, Out any](hex *projection, typed *projection, convert func(In) (Out, error)) func(row interface{}) (interface{}, error) {
+	return func(row interface{}) (interface{}, error) {
+		in, ok := hex.widenRow(row).(In)
+		if !ok {
+			return nil, errors.Errorf("no typed schema for %T", row)
+		}
+		out, err := convert(in)
+		if err != nil {
+			return nil, err
+		}
+		return typed.narrowRow(out), nil
+	}
+}
+
+// withdrawalRows converts a block's beacon chain withdrawals to parquet
+func withdrawalRows(block *Data) ([]interface{}, error) {
+	var outputs []interface{}
+	for _, withdrawal := range block.Block.Withdrawals {
+		outputs = append(outputs, ethereum.WithdrawalToParquet(withdrawal, block.Block.Number))
+	}
+	return outputs, nil
+}
+
+// blobRows converts the versioned hashes of a block's blob transactions to parquet
+func blobRows(block *Data) ([]interface{}, error) {
+	var outputs []interface{}
+	for _, tx := range block.Block.Transactions {
+		for i, versionedHash := range tx.BlobVersionedHashes {
+			outputs = append(outputs, ethereum.BlobToParquet(tx, i, versionedHash))
+		}
+	}
+	return outputs, nil
+}
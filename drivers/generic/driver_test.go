@@ -0,0 +1,171 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/ethereum"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+// writeHeight runs a height through the driver as the poller would: fetch, accumulate, then every writer
+func writeHeight(ctx context.Context, d *Driver, height uint64) error {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		res, err := runner(ctx)
+		if err != nil {
+			return err
+		}
+		set[stage] = res
+	}
+	data, err := d.Accumulate(set)(ctx)
+	if err != nil {
+		return err
+	}
+	for _, writer := range d.Writers() {
+		if _, err := writer(data)(ctx); err != nil {
+			return err
+		}
+	}
+	return d.Flush(ctx)
+}
+
+// readEntity reads back every row written for an entity
+func readEntity(ctx context.Context, store *storage.MemoryConnector, entity string, mapToStruct interface{}) ([]interface{}, error) {
+	var rows []interface{}
+	for _, file := range store.FilesWithPrefix(entity + "/") {
+		fileRows, err := storage.ReadAll(ctx, store, file, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fileRows...)
+	}
+	return rows, nil
+}
+
+func TestDriverWritesFixtures(t *testing.T) {
+	tests := []struct {
+		name       string
+		descriptor string
+		wantRows   map[string]int
+		// wantMissingBlockFields and wantMissingTransactionFields are the model fields the descriptor leaves out
+		wantMissingBlockFields       []string
+		wantMissingTransactionFields []string
+	}{
+		{
+			name: "post-shapella chain",
+			descriptor: `{
+				"name": "gnosis",
+				"chain_id": 100,
+				"receipt_method": "eth_getBlockReceipts",
+				"trace_method": "debug_traceBlockByNumber",
+				"block_fields": ["base_fee_per_gas", "withdrawals_root"],
+				"transaction_fields": ["type", "v", "r", "s", "max_fee_per_gas", "max_priority_fee_per_gas"],
+				"withdrawals": true,
+				"finality": "finalized"
+			}`,
+			wantRows: map[string]int{
+				evm.EntityBlocks:       1,
+				evm.EntityTransactions: 1,
+				evm.EntityLogs:         1,
+				evm.EntityTraces:       1,
+				entityWithdrawals:      1,
+			},
+			wantMissingBlockFields:       []string{"Nonce", "SHA3Uncles", "Difficulty", "TotalDifficulty", "Uncles", "MixHash", "BlobGasUsed", "ExcessBlobGas", "ParentBeaconBlockRoot"},
+			wantMissingTransactionFields: []string{"EffectiveGasPrice", "AccessList", "MaxFeePerBlobGas", "BlobVersionedHashes", "BlobGasPrice"},
+		},
+		{
+			//	Receipts fetched one transaction at a time, without traces, and every optional field kept
+			name: "node without tracing",
+			descriptor: `{
+				"name": "gnosis",
+				"chain_id": 100,
+				"receipt_method": "eth_getTransactionReceipt",
+				"trace_method": "none",
+				"block_fields": ["nonce", "sha3_uncles", "difficulty", "total_difficulty", "uncles", "base_fee_per_gas", "mix_hash", "withdrawals_root", "blob_gas_used", "excess_blob_gas", "parent_beacon_block_root"],
+				"transaction_fields": ["type", "v", "r", "s", "effective_gas_price", "max_fee_per_gas", "max_priority_fee_per_gas", "access_list", "max_fee_per_blob_gas", "blob_versioned_hashes", "blob_gas_price"]
+			}`,
+			wantRows: map[string]int{
+				evm.EntityBlocks:       1,
+				evm.EntityTransactions: 1,
+				evm.EntityLogs:         1,
+				evm.EntityTraces:       0,
+				entityWithdrawals:      0,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			descriptorPath := filepath.Join(t.TempDir(), "gnosis.json")
+			if err := os.WriteFile(descriptorPath, []byte(tt.descriptor), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			descriptor, err := LoadDescriptor(descriptorPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			store := storage.NewMemoryConnector(10000)
+			cfg := Config{Config: evm.Config{MaxRetries: 1, DirectoryRange: 10000, WriteMode: storage.WriteModeBlock, SchemaVersion: util.SchemaVersionHex}, DescriptorPath: descriptorPath}
+			d := New(&cfg, fixture.NewClient(filepath.Join("testdata", "gnosis")), store, testLogger{})
+			if err := writeHeight(ctx, d, 32000000); err != nil {
+				t.Fatal(err)
+			}
+
+			models := Models(descriptor, util.SchemaVersionHex)
+			for entity, want := range tt.wantRows {
+				rows, err := readEntity(ctx, store, entity, models[entity])
+				if err != nil {
+					t.Fatalf("read %s: %v", entity, err)
+				}
+				if len(rows) != want {
+					t.Errorf("%s has %d rows, want %d", entity, len(rows), want)
+				}
+			}
+
+			for _, c := range []struct {
+				entity string
+				model  interface{}
+				want   []string
+			}{
+				{evm.EntityBlocks, new(model.ParquetBlock), tt.wantMissingBlockFields},
+				{evm.EntityTransactions, new(model.ParquetTransaction), tt.wantMissingTransactionFields},
+			} {
+				files := store.FilesWithPrefix(c.entity + "/")
+				if len(files) != 1 {
+					t.Fatalf("%s has files %v, want one", c.entity, files)
+				}
+				missing, err := storage.MissingFields(ctx, store, files[0], c.model)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(missing, c.want) {
+					t.Errorf("%s lack %v, want %v", c.entity, missing, c.want)
+				}
+			}
+		})
+	}
+}
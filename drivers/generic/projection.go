@@ -0,0 +1,72 @@
+package generic
+
+import (
+	"reflect"
+	"strings"
+)
+
+// projection narrows a parquet model down to the columns a descriptor asks for, by building a struct type without the
+// dropped fields
+type projection struct {
+	full   reflect.Type
+	narrow reflect.Type
+}
+
+// newProjection constructs a projection of a model that leaves out the given parquet columns
+func newProjection(mapToStruct interface{}, dropped map[string]bool) *projection {
+	full := reflect.TypeOf(mapToStruct).Elem()
+	var fields []reflect.StructField
+	for i := 0; i < full.NumField(); i++ {
+		if !dropped[columnName(full.Field(i))] {
+			fields = append(fields, full.Field(i))
+		}
+	}
+
+	narrow := full
+	if len(fields) != full.NumField() {
+		narrow = reflect.StructOf(fields)
+	}
+	return &projection{full: full, narrow: narrow}
+}
+
+// model returns the narrowed model, for writing and reading files
+func (p *projection) model() interface{} {
+	return reflect.New(p.narrow).Interface()
+}
+
+// narrowRow copies a row of the full model into the narrowed one
+func (p *projection) narrowRow(row interface{}) interface{} {
+	return convertRow(row, p.narrow)
+}
+
+// widenRow copies a row of the narrowed model back into the full one, leaving the dropped fields empty
+func (p *projection) widenRow(row interface{}) interface{} {
+	return convertRow(row, p.full)
+}
+
+// convertRow copies the fields of a row into a new row of another struct type by name; a row already of that type is
+// returned as is
+func convertRow(row interface{}, to reflect.Type) interface{} {
+	src := reflect.ValueOf(row).Elem()
+	if src.Type() == to {
+		return row
+	}
+
+	dst := reflect.New(to)
+	for i := 0; i < to.NumField(); i++ {
+		if field := src.FieldByName(to.Field(i).Name); field.IsValid() {
+			dst.Elem().Field(i).Set(field)
+		}
+	}
+	return dst.Interface()
+}
+
+// columnName returns the parquet column name of a model field
+func columnName(field reflect.StructField) string {
+	for _, part := range strings.Split(field.Tag.Get("parquet"), ",") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(part), "="); ok && key == "name" {
+			return value
+		}
+	}
+	return ""
+}
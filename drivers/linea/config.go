@@ -0,0 +1,14 @@
+package linea
+
+import (
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/coherentopensource/go-service-framework/util"
+)
+
+// Config stores configurable properties of the driver
+type Config = evm.Config
+
+// MustParseConfig uses env.Parse to initialize config with environment variables
+func MustParseConfig(logger util.Logger) *Config {
+	return evm.MustParseConfig(logger, "Linea")
+}
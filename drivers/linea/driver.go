@@ -0,0 +1,70 @@
+// Package linea indexes Linea, one of the zk-rollup drivers along with zksync and scroll. Linea nodes serve the
+// standard Ethereum JSON-RPC formats, so the driver reads them with the Ethereum protos, into a model without the
+// beacon chain columns Linea lacks.
+package linea
+
+import (
+	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	protos "github.com/coherentopensource/chain-interactor/protos/go/protos/chains/ethereum"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/linea"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/constants"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
+)
+
+// Linea is the blockchain name of Linea, which the service framework has no constant for
+const Linea constants.Blockchain = "linea"
+
+// Driver is the container for all ETL business logic
+type Driver = evm.Driver[*protos.Block, *protos.Transaction, *protos.TransactionReceipt, *protos.Log, *protos.CallTrace]
+
+// Data is a block together with its receipts and call traces, as handed to the writers
+type Data = evm.Data[*protos.Block, *protos.TransactionReceipt, *protos.CallTrace]
+
+// chain plugs the Ethereum protos and the Linea codec into the shared EVM driver
+var chain = &evm.Chain[*protos.Block, *protos.Transaction, *protos.TransactionReceipt, *protos.Log, *protos.CallTrace]{
+	Blockchain: Linea,
+	//	Blocks finalize once proven on L1, hours behind the latest block
+	Finality: evm.FinalityLatest,
+	//	Linea nodes lack eth_getBlockReceipts
+	ReceiptsPerTransaction: true,
+	DiscardUnknownFields:   true,
+
+	BlockToParquet: func(block *protos.Block) interface{} {
+		return ProtoBlockToParquet(block)
+	},
+	TransactionToParquet: func(tx *protos.Transaction, receipt *protos.TransactionReceipt) (interface{}, error) {
+		return ProtoTransactionToParquet(tx, receipt)
+	},
+	LogToParquet: func(log *protos.Log) interface{} {
+		return ProtoLogToParquet(log)
+	},
+	TraceToParquet: func(frame trace.Frame[*protos.CallTrace], tx *protos.Transaction) interface{} {
+		return ProtoTraceToParquet(frame, tx)
+	},
+	Roots:           ProtoBlockRoots,
+	RootTransaction: ProtoTransactionToRoots,
+	RootReceipt:     ProtoReceiptToRoots,
+
+	Entities: []evm.Entity[*Data]{
+		{Name: evm.EntityBlocks, Model: new(model.ParquetBlock), ModelV2: new(model.ParquetBlockV2), ToV2: evm.Typed(ParquetBlockToV2)},
+		{Name: evm.EntityTransactions, Model: new(model.ParquetTransaction), ModelV2: new(model.ParquetTransactionV2), ToV2: evm.Typed(ParquetTransactionToV2)},
+		{Name: evm.EntityLogs, Model: new(model.ParquetLog), ModelV2: new(model.ParquetLogV2), ToV2: evm.Typed(ParquetLogToV2)},
+		{Name: evm.EntityTraces, Model: new(model.ParquetTrace), ModelV2: new(model.ParquetTraceV2), ToV2: evm.Typed(ParquetTraceToV2)},
+	},
+}
+
+// Entities maps each entity directory to the parquet model its files are written with, for tools that read the output
+// back generically
+var Entities = chain.Models(util.SchemaVersionHex)
+
+// EntitiesV2 is Entities for the typed parquet schema
+var EntitiesV2 = chain.Models(util.SchemaVersionTyped)
+
+// New constructs a new Driver
+func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	return evm.New(chain, cfg, nodeClient, innerStore, logger)
+}
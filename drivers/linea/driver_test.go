@@ -0,0 +1,145 @@
+package linea
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/linea"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"path/filepath"
+	"testing"
+)
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+// writeHeight runs a height through the driver as the poller would: fetch, accumulate, then every writer
+func writeHeight(ctx context.Context, d *Driver, height uint64) error {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		res, err := runner(ctx)
+		if err != nil {
+			return err
+		}
+		set[stage] = res
+	}
+	data, err := d.Accumulate(set)(ctx)
+	if err != nil {
+		return err
+	}
+	for _, writer := range d.Writers() {
+		if _, err := writer(data)(ctx); err != nil {
+			return err
+		}
+	}
+	return d.Flush(ctx)
+}
+
+// readEntity reads back every row written for an entity
+func readEntity(ctx context.Context, store *storage.MemoryConnector, entity string, mapToStruct interface{}) ([]interface{}, error) {
+	var rows []interface{}
+	for _, file := range store.FilesWithPrefix(entity + "/") {
+		fileRows, err := storage.ReadAll(ctx, store, file, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fileRows...)
+	}
+	return rows, nil
+}
+
+func TestDriverWritesFixtures(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryConnector(10000)
+	cfg := Config{MaxRetries: 1, DirectoryRange: 10000, WriteMode: storage.WriteModeBlock, SchemaVersion: util.SchemaVersionHex}
+	d := New(&cfg, fixture.NewClient(filepath.Join("testdata", "transfer")), store, testLogger{})
+
+	//	The node adds fields the Ethereum protos lack, such as yParity, which are dropped rather than failing the block
+	if err := writeHeight(ctx, d, 3000000); err != nil {
+		t.Fatal(err)
+	}
+
+	for entity, want := range map[string]int{
+		evm.EntityBlocks:       1,
+		evm.EntityTransactions: 2,
+		evm.EntityLogs:         1,
+		evm.EntityTraces:       2,
+	} {
+		rows, err := readEntity(ctx, store, entity, Entities[entity])
+		if err != nil {
+			t.Fatalf("read %s: %v", entity, err)
+		}
+		if len(rows) != want {
+			t.Errorf("%s has %d rows, want %d", entity, len(rows), want)
+		}
+	}
+
+	rows, err := readEntity(ctx, store, evm.EntityTransactions, new(model.ParquetTransaction))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := make(map[string]*model.ParquetTransaction)
+	for _, row := range rows {
+		tx := row.(*model.ParquetTransaction)
+		txs[tx.Hash] = tx
+	}
+
+	tests := []struct {
+		name            string
+		hash            string
+		wantType        string
+		wantMaxFee      string
+		wantPriorityFee string
+		wantStatus      string
+	}{
+		{
+			name:            "dynamic fee",
+			hash:            "0x6666666666666666666666666666666666666666666666666666666666666666",
+			wantType:        "0x2",
+			wantMaxFee:      "0x77359400",
+			wantPriorityFee: "0x5f5e100",
+			wantStatus:      "0x1",
+		},
+		{
+			name:       "legacy",
+			hash:       "0x7777777777777777777777777777777777777777777777777777777777777777",
+			wantType:   "0x0",
+			wantStatus: "0x1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, ok := txs[tt.hash]
+			if !ok {
+				t.Fatalf("transaction %s was not written", tt.hash)
+			}
+			if tx.Type != tt.wantType {
+				t.Errorf("type = %q, want %q", tx.Type, tt.wantType)
+			}
+			if tx.MaxFeePerGas != tt.wantMaxFee {
+				t.Errorf("max_fee_per_gas = %q, want %q", tx.MaxFeePerGas, tt.wantMaxFee)
+			}
+			if tx.MaxPriorityFeePerGas != tt.wantPriorityFee {
+				t.Errorf("max_priority_fee_per_gas = %q, want %q", tx.MaxPriorityFeePerGas, tt.wantPriorityFee)
+			}
+			if tx.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", tx.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package optimism
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"fmt"
 	protos "github.com/coherentopensource/chain-interactor/protos/go/protos/chains/optimism"
 	model "github.com/coherentopensource/evm-etl/model/optimism"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
 )
 
 // ProtoBlockToParquet converts a block proto to parquet
@@ -94,15 +94,19 @@ func ProtoLogToParquet(in *protos.Log) *model.ParquetLog {
 	return &out
 }
 
-// ProtoTraceToParquet converts a trace proto to parquet, given a trace, a transaction, and other supplemental data
-func ProtoTraceToParquet(inTrace *protos.CallTrace, inTransaction *protos.Transaction, hash string, parentHash string, index int64) *model.ParquetTrace {
+// ProtoTraceToParquet converts a flattened trace frame to parquet, given the transaction it belongs to
+func ProtoTraceToParquet(frame trace.Frame[*protos.CallTrace], inTransaction *protos.Transaction) *model.ParquetTrace {
+	inTrace := frame.Call
 	return &model.ParquetTrace{
 		BlockNumber:     inTransaction.BlockNumber,
 		BlockHash:       inTransaction.BlockHash,
 		TransactionHash: inTransaction.Hash,
-		Hash:            hash,
-		ParentHash:      parentHash,
-		Index:           index,
+		Hash:            frame.Hash,
+		ParentHash:      frame.ParentHash,
+		Index:           frame.Index,
+		TraceAddress:    frame.TraceAddress,
+		Depth:           frame.Depth,
+		Subtraces:       frame.Subtraces,
 		Type:            inTrace.Type,
 		From:            inTrace.From,
 		To:              inTrace.To,
@@ -116,10 +120,126 @@ func ProtoTraceToParquet(inTrace *protos.CallTrace, inTransaction *protos.Transa
 	}
 }
 
-// hashCallTrace hashes a trace proto
-func hashCallTrace(callTrace *protos.CallTrace) string {
-	hasher := sha256.New()
-	hasher.Write([]byte(fmt.Sprintf("%v", callTrace)))
+// ParquetBlockToV2 converts a block from the hex schema to the typed schema
+func ParquetBlockToV2(in *model.ParquetBlock) (*model.ParquetBlockV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetBlockV2{
+		Number:           c.Int64("block_number", in.Number),
+		Hash:             in.Hash,
+		ParentHash:       in.ParentHash,
+		Nonce:            in.Nonce,
+		SHA3Uncles:       in.SHA3Uncles,
+		LogsBloom:        in.LogsBloom,
+		TransactionsRoot: in.TransactionsRoot,
+		StateRoot:        in.StateRoot,
+		ReceiptsRoot:     in.ReceiptsRoot,
+		Miner:            in.Miner,
+		Difficulty:       c.OptionalDecimal38("difficulty", in.Difficulty),
+		TotalDifficulty:  c.OptionalDecimal38("total_difficulty", in.TotalDifficulty),
+		ExtraData:        in.ExtraData,
+		Size:             c.Int64("size", in.Size),
+		GasLimit:         c.Int64("gas_limit", in.GasLimit),
+		GasUsed:          c.Int64("gas_used", in.GasUsed),
+		Timestamp:        c.TimestampMillis("timestamp", in.Timestamp),
+		Uncles:           in.Uncles,
+		MixHash:          in.MixHash,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert block to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetTransactionToV2 converts a transaction from the hex schema to the typed schema
+func ParquetTransactionToV2(in *model.ParquetTransaction) (*model.ParquetTransactionV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTransactionV2{
+		BlockNumber:       c.Int64("block_number", in.BlockNumber),
+		BlockHash:         in.BlockHash,
+		Hash:              in.Hash,
+		From:              in.From,
+		To:                in.To,
+		Value:             c.Decimal38("value", in.Value),
+		Gas:               c.Int64("gas", in.Gas),
+		GasPrice:          c.OptionalDecimal38("gas_price", in.GasPrice),
+		Input:             in.Input,
+		Nonce:             c.Int64("nonce", in.Nonce),
+		TransactionIndex:  c.Int64("transaction_index", in.TransactionIndex),
+		V:                 in.V,
+		R:                 in.R,
+		S:                 in.S,
+		CumulativeGasUsed: c.Int64("cumulative_gas_used", in.CumulativeGasUsed),
+		GasUsed:           c.Int64("gas_used", in.GasUsed),
+		LogsBloom:         in.LogsBloom,
+		Status:            c.OptionalInt64("status", in.Status),
+		QueueOrigin:       in.QueueOrigin,
+		L1TxOrigin:        in.L1TxOrigin,
+		L1BlockNumber:     c.OptionalInt64("l1_block_number", in.L1BlockNumber),
+		L1Timestamp:       c.OptionalTimestampMillis("l1_timestamp", in.L1Timestamp),
+		Index:             c.OptionalInt64("index", in.Index),
+		QueueIndex:        c.OptionalInt64("queue_index", in.QueueIndex),
+		RawTransaction:    in.RawTransaction,
+		L1Fee:             c.OptionalDecimal38("l1_fee", in.L1Fee),
+		L1FeeScalar:       in.L1FeeScalar,
+		L1GasPrice:        c.OptionalDecimal38("l1_gas_price", in.L1GasPrice),
+		L1GasUsed:         c.OptionalInt64("l1_gas_used", in.L1GasUsed),
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert transaction to typed schema")
+	}
 
-	return hex.EncodeToString(hasher.Sum(nil))
+	return &out, nil
+}
+
+// ParquetLogToV2 converts a log from the hex schema to the typed schema
+func ParquetLogToV2(in *model.ParquetLog) (*model.ParquetLogV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetLogV2{
+		BlockNumber:      c.Int64("block_number", in.BlockNumber),
+		BlockHash:        in.BlockHash,
+		TransactionHash:  in.TransactionHash,
+		TransactionIndex: c.Int64("transaction_index", in.TransactionIndex),
+		LogIndex:         c.Int64("log_index", in.LogIndex),
+		Address:          in.Address,
+		Data:             in.Data,
+		Topics:           in.Topics,
+		Removed:          in.Removed,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert log to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetTraceToV2 converts a trace from the hex schema to the typed schema
+func ParquetTraceToV2(in *model.ParquetTrace) (*model.ParquetTraceV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTraceV2{
+		BlockNumber:     c.Int64("block_number", in.BlockNumber),
+		BlockHash:       in.BlockHash,
+		TransactionHash: in.TransactionHash,
+		Hash:            in.Hash,
+		ParentHash:      in.ParentHash,
+		Index:           in.Index,
+		TraceAddress:    in.TraceAddress,
+		Depth:           in.Depth,
+		Subtraces:       in.Subtraces,
+		Type:            in.Type,
+		From:            in.From,
+		To:              in.To,
+		Value:           c.OptionalDecimal38("value", in.Value),
+		Gas:             c.OptionalInt64("gas", in.Gas),
+		GasUsed:         c.OptionalInt64("gas_used", in.GasUsed),
+		Input:           in.Input,
+		Output:          in.Output,
+		Error:           in.Error,
+		RevertReason:    in.RevertReason,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert trace to typed schema")
+	}
+
+	return &out, nil
 }
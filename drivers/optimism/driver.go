@@ -2,35 +2,70 @@ package optimism
 
 import (
 	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	protos "github.com/coherentopensource/chain-interactor/protos/go/protos/chains/optimism"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/optimism"
 	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
 	"github.com/coherentopensource/go-service-framework/constants"
-	"github.com/coherentopensource/go-service-framework/util"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
 )
 
 const (
-	stageFetchBlock  = "fetch.block"
-	stageFetchTraces = "fetch.traces"
+	traceKnownError = "TypeError: cannot read property 'toString' of undefined    in server-side tracer function 'result'"
 )
 
-// OptimismDriver is the container for all ETL business logic
-type OptimismDriver struct {
-	store      *store
-	nodeClient *client
-	logger     util.Logger
-	config     *Config
-}
+// Driver is the container for all ETL business logic
+type Driver = evm.Driver[*protos.Block, *protos.Transaction, *protos.TransactionReceipt, *protos.Log, *protos.CallTrace]
+
+// Data is a block together with its receipts and call traces, as handed to the writers
+type Data = evm.Data[*protos.Block, *protos.TransactionReceipt, *protos.CallTrace]
+
+// OptimismDriver is the name Driver went by before the drivers shared a core
+//
+// Deprecated: use Driver
+type OptimismDriver = Driver
+
+// chain plugs the Optimism protos and codec into the shared EVM driver
+var chain = &evm.Chain[*protos.Block, *protos.Transaction, *protos.TransactionReceipt, *protos.Log, *protos.CallTrace]{
+	Blockchain: constants.Optimism,
+	//	OP-stack blocks are safe once their batch is on L1; they only finalize with L1, well over ten minutes later
+	Finality: evm.FinalitySafe,
+	//	OP-stack nodes lack eth_getBlockReceipts, and every block opens with the L1 attributes deposit
+	ReceiptsPerTransaction: true,
+	RequireTransactions:    true,
+	IgnoredTraceErrors:     []string{traceKnownError},
 
-// New constructs a new OptimismDriver
-func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger util.Logger) *OptimismDriver {
-	return &OptimismDriver{
-		nodeClient: &client{innerClient: nodeClient, logger: logger},
-		store:      &store{innerStore: innerStore},
-		logger:     logger,
-		config:     cfg,
-	}
+	BlockToParquet: func(block *protos.Block) interface{} {
+		return ProtoBlockToParquet(block)
+	},
+	TransactionToParquet: func(tx *protos.Transaction, receipt *protos.TransactionReceipt) (interface{}, error) {
+		return ProtoTransactionToParquet(tx, receipt)
+	},
+	LogToParquet: func(log *protos.Log) interface{} {
+		return ProtoLogToParquet(log)
+	},
+	TraceToParquet: func(frame trace.Frame[*protos.CallTrace], tx *protos.Transaction) interface{} {
+		return ProtoTraceToParquet(frame, tx)
+	},
+
+	Entities: []evm.Entity[*Data]{
+		{Name: evm.EntityBlocks, Model: new(model.ParquetBlock), ModelV2: new(model.ParquetBlockV2), ToV2: evm.Typed(ParquetBlockToV2)},
+		{Name: evm.EntityTransactions, Model: new(model.ParquetTransaction), ModelV2: new(model.ParquetTransactionV2), ToV2: evm.Typed(ParquetTransactionToV2)},
+		{Name: evm.EntityLogs, Model: new(model.ParquetLog), ModelV2: new(model.ParquetLogV2), ToV2: evm.Typed(ParquetLogToV2)},
+		{Name: evm.EntityTraces, Model: new(model.ParquetTrace), ModelV2: new(model.ParquetTraceV2), ToV2: evm.Typed(ParquetTraceToV2)},
+	},
 }
 
-// Blockchain returns the name of the blockchain
-func (d *OptimismDriver) Blockchain() string {
-	return string(constants.Optimism)
+// Entities maps each entity directory to the parquet model its files are written with, for tools that read the output
+// back generically
+var Entities = chain.Models(util.SchemaVersionHex)
+
+// EntitiesV2 is Entities for the typed parquet schema
+var EntitiesV2 = chain.Models(util.SchemaVersionTyped)
+
+// New constructs a new Driver
+func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	return evm.New(chain, cfg, nodeClient, innerStore, logger)
 }
@@ -0,0 +1,252 @@
+package scroll
+
+import (
+	"encoding/json"
+	model "github.com/coherentopensource/evm-etl/model/scroll"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
+)
+
+// accessEntry is an access list entry as written to parquet, in the same form as the chains that marshal their protos
+type accessEntry struct {
+	Address     string   `json:"address,omitempty"`
+	StorageKeys []string `json:"storage_keys,omitempty"`
+}
+
+// BlockToParquet converts a block to parquet
+func BlockToParquet(in *Block) *model.ParquetBlock {
+	out := model.ParquetBlock{
+		Number:           in.Number,
+		Hash:             in.Hash,
+		ParentHash:       in.ParentHash,
+		Nonce:            in.Nonce,
+		SHA3Uncles:       in.Sha3Uncles,
+		LogsBloom:        in.LogsBloom,
+		TransactionsRoot: in.TransactionsRoot,
+		StateRoot:        in.StateRoot,
+		ReceiptsRoot:     in.ReceiptsRoot,
+		Miner:            in.Miner,
+		Difficulty:       in.Difficulty,
+		TotalDifficulty:  in.TotalDifficulty,
+		ExtraData:        in.ExtraData,
+		Size:             in.Size,
+		GasLimit:         in.GasLimit,
+		GasUsed:          in.GasUsed,
+		Timestamp:        in.Timestamp,
+		BaseFeePerGas:    in.BaseFeePerGas,
+		MixHash:          in.MixHash,
+	}
+
+	for _, uncle := range in.Uncles {
+		out.Uncles = append(out.Uncles, uncle)
+	}
+
+	return &out
+}
+
+// TransactionToParquet converts a transaction to parquet, given a transaction and receipt
+func TransactionToParquet(inTx *Transaction, inReceipt *TransactionReceipt) (*model.ParquetTransaction, error) {
+	out := model.ParquetTransaction{
+		BlockNumber:          inTx.BlockNumber,
+		BlockHash:            inTx.BlockHash,
+		Hash:                 inTx.Hash,
+		From:                 inTx.From,
+		To:                   inTx.To,
+		Value:                inTx.Value,
+		Gas:                  inTx.Gas,
+		GasPrice:             inTx.GasPrice,
+		Input:                inTx.Input,
+		Type:                 inTx.Type,
+		Nonce:                inTx.Nonce,
+		TransactionIndex:     inTx.TransactionIndex,
+		V:                    inTx.V,
+		R:                    inTx.R,
+		S:                    inTx.S,
+		MaxFeePerGas:         inTx.MaxFeePerGas,
+		MaxPriorityFeePerGas: inTx.MaxPriorityFeePerGas,
+		CumulativeGasUsed:    inReceipt.CumulativeGasUsed,
+		EffectiveGasPrice:    inReceipt.EffectiveGasPrice,
+		GasUsed:              inReceipt.GasUsed,
+		LogsBloom:            inReceipt.LogsBloom,
+		Status:               inReceipt.Status,
+		QueueIndex:           inTx.QueueIndex,
+		L1Fee:                inReceipt.L1Fee,
+	}
+
+	for _, access := range inTx.AccessList {
+		accessJSON, err := json.Marshal(accessEntry{Address: access.Address, StorageKeys: access.StorageKeys})
+		if err != nil {
+			return nil, errors.Errorf("failed to convert struct to json: %v", err)
+		}
+		out.AccessList = append(out.AccessList, string(accessJSON))
+	}
+
+	return &out, nil
+}
+
+// LogToParquet converts a log to parquet
+func LogToParquet(in *Log) *model.ParquetLog {
+	out := model.ParquetLog{
+		BlockNumber:      in.BlockNumber,
+		BlockHash:        in.BlockHash,
+		TransactionHash:  in.TransactionHash,
+		TransactionIndex: in.TransactionIndex,
+		LogIndex:         in.LogIndex,
+		Address:          in.Address,
+		Data:             in.Data,
+		Removed:          in.Removed,
+	}
+	for _, topic := range in.Topics {
+		out.Topics = append(out.Topics, topic)
+	}
+
+	return &out
+}
+
+// TraceToParquet converts a flattened trace frame to parquet, given the transaction it belongs to
+func TraceToParquet(frame trace.Frame[*CallTrace], inTransaction *Transaction) *model.ParquetTrace {
+	inTrace := frame.Call
+	return &model.ParquetTrace{
+		BlockNumber:     inTransaction.BlockNumber,
+		BlockHash:       inTransaction.BlockHash,
+		TransactionHash: inTransaction.Hash,
+		Hash:            frame.Hash,
+		ParentHash:      frame.ParentHash,
+		Index:           frame.Index,
+		TraceAddress:    frame.TraceAddress,
+		Depth:           frame.Depth,
+		Subtraces:       frame.Subtraces,
+		Type:            inTrace.Type,
+		From:            inTrace.From,
+		To:              inTrace.To,
+		Value:           inTrace.Value,
+		Gas:             inTrace.Gas,
+		GasUsed:         inTrace.GasUsed,
+		Input:           inTrace.Input,
+		Output:          inTrace.Output,
+		Error:           inTrace.Error,
+		RevertReason:    inTrace.RevertReason,
+	}
+}
+
+// ParquetBlockToV2 converts a block from the hex schema to the typed schema
+func ParquetBlockToV2(in *model.ParquetBlock) (*model.ParquetBlockV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetBlockV2{
+		Number:           c.Int64("block_number", in.Number),
+		Hash:             in.Hash,
+		ParentHash:       in.ParentHash,
+		Nonce:            in.Nonce,
+		SHA3Uncles:       in.SHA3Uncles,
+		LogsBloom:        in.LogsBloom,
+		TransactionsRoot: in.TransactionsRoot,
+		StateRoot:        in.StateRoot,
+		ReceiptsRoot:     in.ReceiptsRoot,
+		Miner:            in.Miner,
+		Difficulty:       c.OptionalDecimal38("difficulty", in.Difficulty),
+		TotalDifficulty:  c.OptionalDecimal38("total_difficulty", in.TotalDifficulty),
+		ExtraData:        in.ExtraData,
+		Size:             c.Int64("size", in.Size),
+		GasLimit:         c.Int64("gas_limit", in.GasLimit),
+		GasUsed:          c.Int64("gas_used", in.GasUsed),
+		Timestamp:        c.TimestampMillis("timestamp", in.Timestamp),
+		Uncles:           in.Uncles,
+		BaseFeePerGas:    c.OptionalDecimal38("base_fee_per_gas", in.BaseFeePerGas),
+		MixHash:          in.MixHash,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert block to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetTransactionToV2 converts a transaction from the hex schema to the typed schema
+func ParquetTransactionToV2(in *model.ParquetTransaction) (*model.ParquetTransactionV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTransactionV2{
+		BlockNumber:          c.Int64("block_number", in.BlockNumber),
+		BlockHash:            in.BlockHash,
+		Hash:                 in.Hash,
+		From:                 in.From,
+		To:                   in.To,
+		Value:                c.Decimal38("value", in.Value),
+		Gas:                  c.Int64("gas", in.Gas),
+		GasPrice:             c.OptionalDecimal38("gas_price", in.GasPrice),
+		Input:                in.Input,
+		Type:                 c.OptionalInt64("type", in.Type),
+		Nonce:                c.Int64("nonce", in.Nonce),
+		TransactionIndex:     c.Int64("transaction_index", in.TransactionIndex),
+		V:                    in.V,
+		R:                    in.R,
+		S:                    in.S,
+		CumulativeGasUsed:    c.Int64("cumulative_gas_used", in.CumulativeGasUsed),
+		EffectiveGasPrice:    c.OptionalDecimal38("effective_gas_price", in.EffectiveGasPrice),
+		MaxFeePerGas:         c.OptionalDecimal38("max_fee_per_gas", in.MaxFeePerGas),
+		MaxPriorityFeePerGas: c.OptionalDecimal38("max_priority_fee_per_gas", in.MaxPriorityFeePerGas),
+		GasUsed:              c.Int64("gas_used", in.GasUsed),
+		LogsBloom:            in.LogsBloom,
+		Status:               c.OptionalInt64("status", in.Status),
+		AccessList:           in.AccessList,
+		QueueIndex:           c.OptionalInt64("queue_index", in.QueueIndex),
+		L1Fee:                c.OptionalDecimal38("l1_fee", in.L1Fee),
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert transaction to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetLogToV2 converts a log from the hex schema to the typed schema
+func ParquetLogToV2(in *model.ParquetLog) (*model.ParquetLogV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetLogV2{
+		BlockNumber:      c.Int64("block_number", in.BlockNumber),
+		BlockHash:        in.BlockHash,
+		TransactionHash:  in.TransactionHash,
+		TransactionIndex: c.Int64("transaction_index", in.TransactionIndex),
+		LogIndex:         c.Int64("log_index", in.LogIndex),
+		Address:          in.Address,
+		Data:             in.Data,
+		Topics:           in.Topics,
+		Removed:          in.Removed,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert log to typed schema")
+	}
+
+	return &out, nil
+}
+
+// ParquetTraceToV2 converts a trace from the hex schema to the typed schema
+func ParquetTraceToV2(in *model.ParquetTrace) (*model.ParquetTraceV2, error) {
+	var c util.QuantityConverter
+	out := model.ParquetTraceV2{
+		BlockNumber:     c.Int64("block_number", in.BlockNumber),
+		BlockHash:       in.BlockHash,
+		TransactionHash: in.TransactionHash,
+		Hash:            in.Hash,
+		ParentHash:      in.ParentHash,
+		Index:           in.Index,
+		TraceAddress:    in.TraceAddress,
+		Depth:           in.Depth,
+		Subtraces:       in.Subtraces,
+		Type:            in.Type,
+		From:            in.From,
+		To:              in.To,
+		Value:           c.OptionalDecimal38("value", in.Value),
+		Gas:             c.OptionalInt64("gas", in.Gas),
+		GasUsed:         c.OptionalInt64("gas_used", in.GasUsed),
+		Input:           in.Input,
+		Output:          in.Output,
+		Error:           in.Error,
+		RevertReason:    in.RevertReason,
+	}
+	if err := c.Err(); err != nil {
+		return nil, errors.Wrap(err, "cannot convert trace to typed schema")
+	}
+
+	return &out, nil
+}
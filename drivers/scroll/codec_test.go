@@ -0,0 +1,49 @@
+package scroll
+
+import (
+	model "github.com/coherentopensource/evm-etl/model/scroll"
+	"testing"
+)
+
+func TestParquetTransactionToV2RollupFields(t *testing.T) {
+	tests := []struct {
+		name           string
+		tx             *model.ParquetTransaction
+		wantQueueIndex *int64
+		wantGasPrice   bool
+	}{
+		{
+			//	L1 messages carry no gas price, which the typed schema leaves null rather than zero
+			name:           "l1 message",
+			tx:             &model.ParquetTransaction{BlockNumber: "0x4c4b40", Value: "0x0", Gas: "0x2dc6c0", Nonce: "0x3e8", TransactionIndex: "0x0", CumulativeGasUsed: "0x5208", GasUsed: "0x5208", Type: TxTypeL1Message, QueueIndex: "0x3e8", L1Fee: "0x0"},
+			wantQueueIndex: int64Ptr(1000),
+		},
+		{
+			name:         "dynamic fee",
+			tx:           &model.ParquetTransaction{BlockNumber: "0x4c4b40", Value: "0x0", Gas: "0x5208", Nonce: "0x2a", TransactionIndex: "0x1", CumulativeGasUsed: "0xa410", GasUsed: "0x5208", Type: "0x2", GasPrice: "0x5f5e100", L1Fee: "0x1c6bf526"},
+			wantGasPrice: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ParquetTransactionToV2(tt.tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (out.QueueIndex == nil) != (tt.wantQueueIndex == nil) || (out.QueueIndex != nil && *out.QueueIndex != *tt.wantQueueIndex) {
+				t.Errorf("queue_index = %v, want %v", out.QueueIndex, tt.wantQueueIndex)
+			}
+			if (out.GasPrice != nil) != tt.wantGasPrice {
+				t.Errorf("gas_price = %v, want set %v", out.GasPrice, tt.wantGasPrice)
+			}
+			if out.L1Fee == nil {
+				t.Error("l1_fee is null")
+			}
+		})
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
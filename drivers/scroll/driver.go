@@ -0,0 +1,65 @@
+// Package scroll indexes Scroll, one of the zk-rollup drivers along with zksync and linea. Transactions relayed from the
+// L1 message queue (TxTypeL1Message) keep their queue index, and every transaction keeps the fee it paid for being
+// committed to L1. Lacking chain-interactor protos, l2geth's JSON-RPC responses are decoded into plain structs.
+package scroll
+
+import (
+	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/scroll"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/constants"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
+)
+
+// Scroll is the blockchain name of Scroll, which the service framework has no constant for
+const Scroll constants.Blockchain = "scroll"
+
+// Driver is the container for all ETL business logic
+type Driver = evm.Driver[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace]
+
+// Data is a block together with its receipts and call traces, as handed to the writers
+type Data = evm.Data[*Block, *TransactionReceipt, *CallTrace]
+
+// chain plugs the Scroll node types and codec into the shared EVM driver
+var chain = &evm.Chain[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace]{
+	Blockchain: Scroll,
+	//	Blocks finalize once proven on L1, hours behind the latest block
+	Finality: evm.FinalityLatest,
+	//	l2geth lacks eth_getBlockReceipts
+	ReceiptsPerTransaction: true,
+
+	BlockToParquet: func(block *Block) interface{} {
+		return BlockToParquet(block)
+	},
+	TransactionToParquet: func(tx *Transaction, receipt *TransactionReceipt) (interface{}, error) {
+		return TransactionToParquet(tx, receipt)
+	},
+	LogToParquet: func(log *Log) interface{} {
+		return LogToParquet(log)
+	},
+	TraceToParquet: func(frame trace.Frame[*CallTrace], tx *Transaction) interface{} {
+		return TraceToParquet(frame, tx)
+	},
+
+	Entities: []evm.Entity[*Data]{
+		{Name: evm.EntityBlocks, Model: new(model.ParquetBlock), ModelV2: new(model.ParquetBlockV2), ToV2: evm.Typed(ParquetBlockToV2)},
+		{Name: evm.EntityTransactions, Model: new(model.ParquetTransaction), ModelV2: new(model.ParquetTransactionV2), ToV2: evm.Typed(ParquetTransactionToV2)},
+		{Name: evm.EntityLogs, Model: new(model.ParquetLog), ModelV2: new(model.ParquetLogV2), ToV2: evm.Typed(ParquetLogToV2)},
+		{Name: evm.EntityTraces, Model: new(model.ParquetTrace), ModelV2: new(model.ParquetTraceV2), ToV2: evm.Typed(ParquetTraceToV2)},
+	},
+}
+
+// Entities maps each entity directory to the parquet model its files are written with, for tools that read the output
+// back generically
+var Entities = chain.Models(util.SchemaVersionHex)
+
+// EntitiesV2 is Entities for the typed parquet schema
+var EntitiesV2 = chain.Models(util.SchemaVersionTyped)
+
+// New constructs a new Driver
+func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	return evm.New(chain, cfg, nodeClient, innerStore, logger)
+}
@@ -0,0 +1,133 @@
+package scroll
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/scroll"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"path/filepath"
+	"testing"
+)
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+// writeHeight runs a height through the driver as the poller would: fetch, accumulate, then every writer
+func writeHeight(ctx context.Context, d *Driver, height uint64) error {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		res, err := runner(ctx)
+		if err != nil {
+			return err
+		}
+		set[stage] = res
+	}
+	data, err := d.Accumulate(set)(ctx)
+	if err != nil {
+		return err
+	}
+	for _, writer := range d.Writers() {
+		if _, err := writer(data)(ctx); err != nil {
+			return err
+		}
+	}
+	return d.Flush(ctx)
+}
+
+// readEntity reads back every row written for an entity
+func readEntity(ctx context.Context, store *storage.MemoryConnector, entity string, mapToStruct interface{}) ([]interface{}, error) {
+	var rows []interface{}
+	for _, file := range store.FilesWithPrefix(entity + "/") {
+		fileRows, err := storage.ReadAll(ctx, store, file, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fileRows...)
+	}
+	return rows, nil
+}
+
+func TestDriverWritesFixtures(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryConnector(10000)
+	cfg := Config{MaxRetries: 1, DirectoryRange: 10000, WriteMode: storage.WriteModeBlock, SchemaVersion: util.SchemaVersionHex}
+	d := New(&cfg, fixture.NewClient(filepath.Join("testdata", "l1message")), store, testLogger{})
+
+	if err := writeHeight(ctx, d, 5000000); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := readEntity(ctx, store, evm.EntityTransactions, new(model.ParquetTransaction))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := make(map[string]*model.ParquetTransaction)
+	for _, row := range rows {
+		tx := row.(*model.ParquetTransaction)
+		txs[tx.Hash] = tx
+	}
+
+	tests := []struct {
+		name           string
+		hash           string
+		wantType       string
+		wantQueueIndex string
+		wantGasPrice   string
+		wantL1Fee      string
+	}{
+		{
+			//	An L1 message is paid for on L1, so has neither a gas price nor an L1 fee of its own
+			name:           "l1 message",
+			hash:           "0x6666666666666666666666666666666666666666666666666666666666666666",
+			wantType:       TxTypeL1Message,
+			wantQueueIndex: "0x3e8",
+			wantL1Fee:      "0x0",
+		},
+		{
+			name:         "dynamic fee",
+			hash:         "0x7777777777777777777777777777777777777777777777777777777777777777",
+			wantType:     "0x2",
+			wantGasPrice: "0x5f5e100",
+			wantL1Fee:    "0x1c6bf526",
+		},
+	}
+
+	if len(txs) != len(tests) {
+		t.Fatalf("got %d transactions, want %d", len(txs), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, ok := txs[tt.hash]
+			if !ok {
+				t.Fatalf("transaction %s was not written", tt.hash)
+			}
+			if tx.Type != tt.wantType {
+				t.Errorf("type = %q, want %q", tx.Type, tt.wantType)
+			}
+			if tx.QueueIndex != tt.wantQueueIndex {
+				t.Errorf("queue_index = %q, want %q", tx.QueueIndex, tt.wantQueueIndex)
+			}
+			if tx.GasPrice != tt.wantGasPrice {
+				t.Errorf("gas_price = %q, want %q", tx.GasPrice, tt.wantGasPrice)
+			}
+			if tx.L1Fee != tt.wantL1Fee {
+				t.Errorf("l1_fee = %q, want %q", tx.L1Fee, tt.wantL1Fee)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package scroll
+
+import "github.com/coherentopensource/evm-etl/shared/rpc"
+
+// Scroll transaction types, on top of the standard legacy (0x0), access list (0x1) and dynamic fee (0x2) types
+const (
+	// TxTypeL1Message is a message relayed from the L1 message queue, sent by an L1 account or contract
+	TxTypeL1Message = "0x7e"
+)
+
+// Block is a Scroll block; BaseFeePerGas is empty on nodes that predate EIP-1559 support
+type Block struct {
+	Number           string         `json:"number"`
+	Hash             string         `json:"hash"`
+	ParentHash       string         `json:"parentHash"`
+	Nonce            string         `json:"nonce"`
+	Sha3Uncles       string         `json:"sha3Uncles"`
+	LogsBloom        string         `json:"logsBloom"`
+	TransactionsRoot string         `json:"transactionsRoot"`
+	StateRoot        string         `json:"stateRoot"`
+	ReceiptsRoot     string         `json:"receiptsRoot"`
+	Miner            string         `json:"miner"`
+	Difficulty       string         `json:"difficulty"`
+	TotalDifficulty  string         `json:"totalDifficulty"`
+	ExtraData        string         `json:"extraData"`
+	Size             string         `json:"size"`
+	GasLimit         string         `json:"gasLimit"`
+	GasUsed          string         `json:"gasUsed"`
+	Timestamp        string         `json:"timestamp"`
+	Transactions     []*Transaction `json:"transactions"`
+	Uncles           []string       `json:"uncles"`
+	BaseFeePerGas    string         `json:"baseFeePerGas"`
+	MixHash          string         `json:"mixHash"`
+}
+
+func (x *Block) GetNumber() string               { return x.Number }
+func (x *Block) GetHash() string                 { return x.Hash }
+func (x *Block) GetParentHash() string           { return x.ParentHash }
+func (x *Block) GetTransactions() []*Transaction { return x.Transactions }
+
+// Transaction is a Scroll transaction; QueueIndex is the position in the L1 message queue of an L1 message
+type Transaction struct {
+	BlockHash            string    `json:"blockHash"`
+	BlockNumber          string    `json:"blockNumber"`
+	From                 string    `json:"from"`
+	Gas                  string    `json:"gas"`
+	GasPrice             string    `json:"gasPrice"`
+	Hash                 string    `json:"hash"`
+	Input                string    `json:"input"`
+	Nonce                string    `json:"nonce"`
+	To                   string    `json:"to"`
+	TransactionIndex     string    `json:"transactionIndex"`
+	Value                string    `json:"value"`
+	V                    string    `json:"v"`
+	R                    string    `json:"r"`
+	S                    string    `json:"s"`
+	AccessList           []*Access `json:"accessList"`
+	ChainId              string    `json:"chainId"`
+	MaxFeePerGas         string    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string    `json:"maxPriorityFeePerGas"`
+	Type                 string    `json:"type"`
+	QueueIndex           string    `json:"queueIndex"`
+}
+
+func (x *Transaction) GetHash() string      { return x.Hash }
+func (x *Transaction) GetBlockHash() string { return x.BlockHash }
+func (x *Transaction) GetFrom() string      { return x.From }
+func (x *Transaction) GetTo() string        { return x.To }
+func (x *Transaction) GetValue() string     { return x.Value }
+
+// Access is an entry of a transaction's access list
+type Access = rpc.Access
+
+// TransactionReceipt is a Scroll transaction receipt, with the fee paid for committing the transaction to L1
+type TransactionReceipt struct {
+	TransactionHash   string `json:"transactionHash"`
+	TransactionIndex  string `json:"transactionIndex"`
+	BlockHash         string `json:"blockHash"`
+	BlockNumber       string `json:"blockNumber"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	CumulativeGasUsed string `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+	GasUsed           string `json:"gasUsed"`
+	L1Fee             string `json:"l1Fee"`
+	ContractAddress   string `json:"contractAddress"`
+	Logs              []*Log `json:"logs"`
+	LogsBloom         string `json:"logsBloom"`
+	Type              string `json:"type"`
+	Status            string `json:"status"`
+}
+
+func (x *TransactionReceipt) GetContractAddress() string { return x.ContractAddress }
+func (x *TransactionReceipt) GetStatus() string          { return x.Status }
+func (x *TransactionReceipt) GetLogs() []*Log            { return x.Logs }
+
+// Log is a Scroll log
+type Log = rpc.Log
+
+// CallTrace is a call frame as returned by l2geth's callTracer
+type CallTrace = rpc.CallTrace
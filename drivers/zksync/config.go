@@ -0,0 +1,25 @@
+package zksync
+
+import (
+	"github.com/caarlos0/env/v7"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	"github.com/coherentopensource/go-service-framework/util"
+)
+
+// Config stores configurable properties of the driver
+type Config struct {
+	evm.Config
+	// NodeHost is the node the zks_ namespace is called on, which node.Client has no methods for; it is the same
+	// variable the node client is configured with
+	NodeHost string `env:"NODE_HOST"`
+}
+
+// MustParseConfig uses env.Parse to initialize config with environment variables
+func MustParseConfig(logger util.Logger) *Config {
+	var cfg Config
+	if err := env.Parse(&cfg); err != nil {
+		logger.Fatalf("could not parse zkSync Era driver config: %v", err)
+	}
+
+	return &cfg
+}
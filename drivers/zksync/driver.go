@@ -0,0 +1,83 @@
+// Package zksync indexes zkSync Era, one of the zk-rollup drivers along with linea and scroll. Blocks and transactions
+// carry the L1 batch they were sealed into. Transactions also keep the paymaster of native account abstraction
+// transactions (TxTypeEIP712) and the priority queue fields of L1 to L2 transactions (TxTypePriority), which only the
+// node's own zks_getRawBlockTransactions returns, and receipts keep the messages sent to L1. Responses are decoded into
+// plain structs, as chain-interactor has no zkSync protos.
+package zksync
+
+import (
+	"context"
+	nodeClient "github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/zksync"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/constants"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ZkSyncEra is the blockchain name of zkSync Era, which the service framework has no constant for
+const ZkSyncEra constants.Blockchain = "zksync_era"
+
+// Driver is the container for all ETL business logic
+type Driver = evm.Driver[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace]
+
+// Data is a block together with its receipts and call traces, as handed to the writers
+type Data = evm.Data[*Block, *TransactionReceipt, *CallTrace]
+
+// Entities maps each entity directory to the parquet model its files are written with, for tools that read the output
+// back generically
+var Entities = newChain(nil).Models(util.SchemaVersionHex)
+
+// EntitiesV2 is Entities for the typed parquet schema
+var EntitiesV2 = newChain(nil).Models(util.SchemaVersionTyped)
+
+// New constructs a new Driver. The zks_ namespace is called on the node client itself if it can make raw calls, as a
+// fixture client can, or else on a connection of its own to NodeHost
+func New(cfg *Config, nodeClient nodeClient.Client, innerStore storage.Store, logger frameworkUtil.Logger) *Driver {
+	rpcCaller, ok := nodeClient.(caller)
+	if !ok {
+		rpcClient, err := rpc.DialContext(context.Background(), cfg.NodeHost)
+		if err != nil {
+			logger.Fatalf("could not connect to node: %v", err)
+		}
+		rpcCaller = rpcClient
+	}
+
+	return evm.New(newChain(rpcCaller), &cfg.Config, nodeClient, innerStore, logger)
+}
+
+// newChain plugs the zkSync Era node types and codec into the shared EVM driver
+func newChain(rpcCaller caller) *evm.Chain[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace] {
+	return &evm.Chain[*Block, *Transaction, *TransactionReceipt, *Log, *CallTrace]{
+		Blockchain: ZkSyncEra,
+		//	Batches finalize once proven on L1, hours behind the latest block
+		Finality: evm.FinalityLatest,
+
+		CompleteBlock: func(ctx context.Context, block *Block) error {
+			return completeBlock(ctx, rpcCaller, block)
+		},
+
+		BlockToParquet: func(block *Block) interface{} {
+			return BlockToParquet(block)
+		},
+		TransactionToParquet: func(tx *Transaction, receipt *TransactionReceipt) (interface{}, error) {
+			return TransactionToParquet(tx, receipt)
+		},
+		LogToParquet: func(log *Log) interface{} {
+			return LogToParquet(log)
+		},
+		TraceToParquet: func(frame trace.Frame[*CallTrace], tx *Transaction) interface{} {
+			return TraceToParquet(frame, tx)
+		},
+
+		Entities: []evm.Entity[*Data]{
+			{Name: evm.EntityBlocks, Model: new(model.ParquetBlock), ModelV2: new(model.ParquetBlockV2), ToV2: evm.Typed(ParquetBlockToV2)},
+			{Name: evm.EntityTransactions, Model: new(model.ParquetTransaction), ModelV2: new(model.ParquetTransactionV2), ToV2: evm.Typed(ParquetTransactionToV2)},
+			{Name: evm.EntityLogs, Model: new(model.ParquetLog), ModelV2: new(model.ParquetLogV2), ToV2: evm.Typed(ParquetLogToV2)},
+			{Name: evm.EntityTraces, Model: new(model.ParquetTrace), ModelV2: new(model.ParquetTraceV2), ToV2: evm.Typed(ParquetTraceToV2)},
+		},
+	}
+}
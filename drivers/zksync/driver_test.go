@@ -0,0 +1,184 @@
+package zksync
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/drivers/evm"
+	model "github.com/coherentopensource/evm-etl/model/zksync"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/coherentopensource/go-service-framework/pool"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+// writeHeight runs a height through the driver as the poller would: fetch, accumulate, then every writer
+func writeHeight(ctx context.Context, d *Driver, height uint64) error {
+	set := pool.ResultSet{}
+	for stage, runner := range d.FetchSequence(height) {
+		res, err := runner(ctx)
+		if err != nil {
+			return err
+		}
+		set[stage] = res
+	}
+	data, err := d.Accumulate(set)(ctx)
+	if err != nil {
+		return err
+	}
+	for _, writer := range d.Writers() {
+		if _, err := writer(data)(ctx); err != nil {
+			return err
+		}
+	}
+	return d.Flush(ctx)
+}
+
+// readEntity reads back every row written for an entity
+func readEntity(ctx context.Context, store *storage.MemoryConnector, entity string, mapToStruct interface{}) ([]interface{}, error) {
+	var rows []interface{}
+	for _, file := range store.FilesWithPrefix(entity + "/") {
+		fileRows, err := storage.ReadAll(ctx, store, file, mapToStruct)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fileRows...)
+	}
+	return rows, nil
+}
+
+// rollupFields are the transaction columns zkSync Era adds, for the types that set them
+type rollupFields struct {
+	Type            string
+	L1BatchNumber   string
+	L1BatchTxIndex  string
+	Paymaster       string
+	PaymasterInput  string
+	PriorityOpId    string
+	ToMint          string
+	RefundRecipient string
+	L2ToL1Logs      string
+}
+
+func TestDriverWritesFixtures(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryConnector(10000)
+	cfg := Config{Config: evm.Config{MaxRetries: 1, DirectoryRange: 10000, WriteMode: storage.WriteModeBlock, SchemaVersion: util.SchemaVersionHex}}
+	d := New(&cfg, fixture.NewClient(filepath.Join("testdata", "paymaster")), store, testLogger{})
+
+	if err := writeHeight(ctx, d, 30000000); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := readEntity(ctx, store, evm.EntityBlocks, new(model.ParquetBlock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 1 {
+		t.Fatalf("got %d blocks, want 1", len(blocks))
+	}
+	if block := blocks[0].(*model.ParquetBlock); block.L1BatchNumber != "0x7a120" || block.L1BatchTimestamp != "0x65f0c900" {
+		t.Errorf("block has l1_batch_number %q and l1_batch_timestamp %q", block.L1BatchNumber, block.L1BatchTimestamp)
+	}
+
+	rows, err := readEntity(ctx, store, evm.EntityTransactions, new(model.ParquetTransaction))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := make(map[string]*model.ParquetTransaction)
+	for _, row := range rows {
+		tx := row.(*model.ParquetTransaction)
+		txs[tx.Hash] = tx
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want rollupFields
+	}{
+		{
+			//	The priority queue fields only come from zks_getRawBlockTransactions
+			name: "priority",
+			hash: "0x6666666666666666666666666666666666666666666666666666666666666666",
+			want: rollupFields{
+				Type:            TxTypePriority,
+				L1BatchNumber:   "0x7a120",
+				L1BatchTxIndex:  "0x0",
+				PriorityOpId:    "0x69",
+				ToMint:          "0xde0b6b3a7640000",
+				RefundRecipient: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			},
+		},
+		{
+			//	The paymaster input is a byte array in the raw transaction, written as hex
+			name: "eip712 with paymaster",
+			hash: "0x7777777777777777777777777777777777777777777777777777777777777777",
+			want: rollupFields{
+				Type:           TxTypeEIP712,
+				L1BatchNumber:  "0x7a120",
+				L1BatchTxIndex: "0x1",
+				Paymaster:      "0x000000000000000000000000000000000000beef",
+				PaymasterInput: "0x8c5a3445",
+				L2ToL1Logs:     `{"blockNumber":"0x1c9c380","blockHash":"0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb","l1BatchNumber":"0x7a120","transactionIndex":"0x1","shardId":"0x0","isService":true,"sender":"0x0000000000000000000000000000000000008008","key":"0x0000000000000000000000000000000000000000000000000000000000000001","value":"0x0000000000000000000000000000000000000000000000000000000000000002","transactionHash":"0x7777777777777777777777777777777777777777777777777777777777777777","logIndex":"0x0"}`,
+			},
+		},
+	}
+
+	if len(txs) != len(tests) {
+		t.Fatalf("got %d transactions, want %d", len(txs), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, ok := txs[tt.hash]
+			if !ok {
+				t.Fatalf("transaction %s was not written", tt.hash)
+			}
+			got := rollupFields{
+				Type:            tx.Type,
+				L1BatchNumber:   tx.L1BatchNumber,
+				L1BatchTxIndex:  tx.L1BatchTxIndex,
+				Paymaster:       tx.Paymaster,
+				PaymasterInput:  tx.PaymasterInput,
+				PriorityOpId:    tx.PriorityOpId,
+				ToMint:          tx.ToMint,
+				RefundRecipient: tx.RefundRecipient,
+				L2ToL1Logs:      strings.Join(tx.L2ToL1Logs, "\n"),
+			}
+			if got != tt.want {
+				t.Errorf("transaction has %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	//	The node names call types in title case, which are written upper-cased as on the other chains
+	traces, err := readEntity(ctx, store, evm.EntityTraces, new(model.ParquetTrace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, row := range traces {
+		types = append(types, row.(*model.ParquetTrace).Type)
+	}
+	sort.Strings(types)
+	if want := []string{"CALL", "CALL", "STATICCALL"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("trace types = %v, want %v", types, want)
+	}
+}
@@ -0,0 +1,206 @@
+package zksync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/rpc"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// zkSync transaction types, on top of the standard legacy (0x0), access list (0x1) and dynamic fee (0x2) types
+const (
+	// TxTypeEIP712 is a native account abstraction transaction, which may have its fees paid by a paymaster
+	TxTypeEIP712 = "0x71"
+	// TxTypePriority is an L1 to L2 transaction, taken from the priority queue of the L1 contract
+	TxTypePriority = "0xff"
+)
+
+const (
+	nullAddress = "0x0000000000000000000000000000000000000000"
+	// methodGetRawBlockTransactions returns a block's transactions as the server stores them, including the paymaster
+	// and priority queue fields that eth_getBlockByNumber leaves out
+	methodGetRawBlockTransactions = "zks_getRawBlockTransactions"
+)
+
+// BlockCalls are the further methods the driver calls with every block number, for recording fixtures
+var BlockCalls = []string{methodGetRawBlockTransactions}
+
+// Block is a zkSync Era block (an L2 block, or miniblock), with the L1 batch it was sealed into; both batch fields are
+// empty until the batch is sealed
+type Block struct {
+	Number           string         `json:"number"`
+	Hash             string         `json:"hash"`
+	ParentHash       string         `json:"parentHash"`
+	Nonce            string         `json:"nonce"`
+	Sha3Uncles       string         `json:"sha3Uncles"`
+	LogsBloom        string         `json:"logsBloom"`
+	TransactionsRoot string         `json:"transactionsRoot"`
+	StateRoot        string         `json:"stateRoot"`
+	ReceiptsRoot     string         `json:"receiptsRoot"`
+	Miner            string         `json:"miner"`
+	Difficulty       string         `json:"difficulty"`
+	TotalDifficulty  string         `json:"totalDifficulty"`
+	ExtraData        string         `json:"extraData"`
+	Size             string         `json:"size"`
+	GasLimit         string         `json:"gasLimit"`
+	GasUsed          string         `json:"gasUsed"`
+	Timestamp        string         `json:"timestamp"`
+	Transactions     []*Transaction `json:"transactions"`
+	Uncles           []string       `json:"uncles"`
+	BaseFeePerGas    string         `json:"baseFeePerGas"`
+	MixHash          string         `json:"mixHash"`
+	L1BatchNumber    string         `json:"l1BatchNumber"`
+	L1BatchTimestamp string         `json:"l1BatchTimestamp"`
+}
+
+func (x *Block) GetNumber() string               { return x.Number }
+func (x *Block) GetHash() string                 { return x.Hash }
+func (x *Block) GetParentHash() string           { return x.ParentHash }
+func (x *Block) GetTransactions() []*Transaction { return x.Transactions }
+
+// Transaction is a zkSync Era transaction. The fields after L1BatchTxIndex are not part of eth_getBlockByNumber, and
+// are filled in by completeBlock
+type Transaction struct {
+	BlockHash            string    `json:"blockHash"`
+	BlockNumber          string    `json:"blockNumber"`
+	From                 string    `json:"from"`
+	Gas                  string    `json:"gas"`
+	GasPrice             string    `json:"gasPrice"`
+	Hash                 string    `json:"hash"`
+	Input                string    `json:"input"`
+	Nonce                string    `json:"nonce"`
+	To                   string    `json:"to"`
+	TransactionIndex     string    `json:"transactionIndex"`
+	Value                string    `json:"value"`
+	V                    string    `json:"v"`
+	R                    string    `json:"r"`
+	S                    string    `json:"s"`
+	AccessList           []*Access `json:"accessList"`
+	ChainId              string    `json:"chainId"`
+	MaxFeePerGas         string    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string    `json:"maxPriorityFeePerGas"`
+	Type                 string    `json:"type"`
+	L1BatchNumber        string    `json:"l1BatchNumber"`
+	L1BatchTxIndex       string    `json:"l1BatchTxIndex"`
+	// Paymaster and PaymasterInput are set for L2 transactions whose fees a paymaster paid
+	Paymaster      string `json:"-"`
+	PaymasterInput string `json:"-"`
+	// PriorityOpId, ToMint and RefundRecipient are set for priority transactions
+	PriorityOpId    string `json:"-"`
+	ToMint          string `json:"-"`
+	RefundRecipient string `json:"-"`
+}
+
+func (x *Transaction) GetHash() string      { return x.Hash }
+func (x *Transaction) GetBlockHash() string { return x.BlockHash }
+func (x *Transaction) GetFrom() string      { return x.From }
+func (x *Transaction) GetTo() string        { return x.To }
+func (x *Transaction) GetValue() string     { return x.Value }
+
+// Access is an entry of a transaction's access list
+type Access = rpc.Access
+
+// TransactionReceipt is a zkSync Era transaction receipt, with the messages the transaction sent to L1
+type TransactionReceipt struct {
+	TransactionHash   string            `json:"transactionHash"`
+	TransactionIndex  string            `json:"transactionIndex"`
+	BlockHash         string            `json:"blockHash"`
+	BlockNumber       string            `json:"blockNumber"`
+	From              string            `json:"from"`
+	To                string            `json:"to"`
+	CumulativeGasUsed string            `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string            `json:"effectiveGasPrice"`
+	GasUsed           string            `json:"gasUsed"`
+	ContractAddress   string            `json:"contractAddress"`
+	Logs              []*Log            `json:"logs"`
+	L2ToL1Logs        []json.RawMessage `json:"l2ToL1Logs"`
+	LogsBloom         string            `json:"logsBloom"`
+	Type              string            `json:"type"`
+	Root              string            `json:"root"`
+	Status            string            `json:"status"`
+	L1BatchNumber     string            `json:"l1BatchNumber"`
+	L1BatchTxIndex    string            `json:"l1BatchTxIndex"`
+}
+
+func (x *TransactionReceipt) GetContractAddress() string { return x.ContractAddress }
+func (x *TransactionReceipt) GetStatus() string          { return x.Status }
+func (x *TransactionReceipt) GetLogs() []*Log            { return x.Logs }
+
+// Log is a zkSync Era log
+type Log = rpc.Log
+
+// CallTrace is a call frame as returned by debug_traceBlockByNumber; unlike geth, the node names call types in title
+// case, e.g. "Call"
+type CallTrace = rpc.CallTrace
+
+// caller makes JSON-RPC calls outside of node.Client's fixed set of methods, as *rpc.Client and fixture.Client do
+type caller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// rawTransaction is a transaction as returned by zks_getRawBlockTransactions, reduced to the fields the driver keeps
+type rawTransaction struct {
+	CommonData struct {
+		L1 *struct {
+			SerialId        uint64 `json:"serialId"`
+			ToMint          string `json:"toMint"`
+			RefundRecipient string `json:"refundRecipient"`
+		} `json:"L1"`
+		L2 *struct {
+			PaymasterParams struct {
+				Paymaster      string    `json:"paymaster"`
+				PaymasterInput byteArray `json:"paymasterInput"`
+			} `json:"paymasterParams"`
+		} `json:"L2"`
+	} `json:"common_data"`
+}
+
+// byteArray decodes the server's byte vectors, which are JSON arrays of numbers, into hex
+type byteArray string
+
+func (b *byteArray) UnmarshalJSON(data []byte) error {
+	var raw []byte
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*b = byteArray(hexutil.Encode(raw))
+	return nil
+}
+
+// completeBlock fills in the paymaster and priority queue fields of a block's transactions from
+// zks_getRawBlockTransactions, which lists them in the same order as eth_getBlockByNumber
+func completeBlock(ctx context.Context, c caller, block *Block) error {
+	if len(block.Transactions) == 0 {
+		return nil
+	}
+
+	number, err := util.HexToInt64(block.Number)
+	if err != nil {
+		return errors.Wrap(err, "invalid block number")
+	}
+	var rawTransactions []rawTransaction
+	if err := c.CallContext(ctx, &rawTransactions, methodGetRawBlockTransactions, number); err != nil {
+		return err
+	}
+	if len(rawTransactions) != len(block.Transactions) {
+		return errors.Errorf("%s returned %d transactions for a block of %d", methodGetRawBlockTransactions, len(rawTransactions), len(block.Transactions))
+	}
+
+	for i, raw := range rawTransactions {
+		tx := block.Transactions[i]
+		if l1 := raw.CommonData.L1; l1 != nil {
+			tx.PriorityOpId = fmt.Sprintf("0x%x", l1.SerialId)
+			tx.ToMint = l1.ToMint
+			tx.RefundRecipient = l1.RefundRecipient
+		}
+		if l2 := raw.CommonData.L2; l2 != nil && l2.PaymasterParams.Paymaster != nullAddress {
+			tx.Paymaster = l2.PaymasterParams.Paymaster
+			tx.PaymasterInput = string(l2.PaymasterParams.PaymasterInput)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package zksync
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// rawCaller answers zks_getRawBlockTransactions with a fixed response, counting the calls made
+type rawCaller struct {
+	response string
+	calls    int
+}
+
+func (c *rawCaller) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	return json.Unmarshal([]byte(c.response), result)
+}
+
+func TestCompleteBlock(t *testing.T) {
+	tests := []struct {
+		name          string
+		transactions  int
+		response      string
+		wantErr       bool
+		wantCalls     int
+		wantPaymaster string
+		wantInput     string
+	}{
+		{
+			name:          "paymaster",
+			transactions:  1,
+			response:      `[{"common_data":{"L2":{"paymasterParams":{"paymaster":"0x000000000000000000000000000000000000beef","paymasterInput":[140,90,52,69]}}}}]`,
+			wantCalls:     1,
+			wantPaymaster: "0x000000000000000000000000000000000000beef",
+			wantInput:     "0x8c5a3445",
+		},
+		{
+			//	A transaction paying its own fees names the null address as its paymaster
+			name:         "no paymaster",
+			transactions: 1,
+			response:     `[{"common_data":{"L2":{"paymasterParams":{"paymaster":"0x0000000000000000000000000000000000000000","paymasterInput":[]}}}}]`,
+			wantCalls:    1,
+		},
+		{
+			name:         "transactions missing from the raw block",
+			transactions: 2,
+			response:     `[{"common_data":{"L2":{"paymasterParams":{"paymaster":"0x0000000000000000000000000000000000000000","paymasterInput":[]}}}}]`,
+			wantErr:      true,
+			wantCalls:    1,
+		},
+		{
+			name: "empty block",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := &Block{Number: "0x1c9c380"}
+			for i := 0; i < tt.transactions; i++ {
+				block.Transactions = append(block.Transactions, &Transaction{Type: TxTypeEIP712})
+			}
+			c := &rawCaller{response: tt.response}
+			err := completeBlock(context.Background(), c, block)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("completeBlock = %v, want error %v", err, tt.wantErr)
+			}
+			if c.calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", c.calls, tt.wantCalls)
+			}
+			if tt.wantErr || tt.transactions == 0 {
+				return
+			}
+			if tx := block.Transactions[0]; tx.Paymaster != tt.wantPaymaster || tx.PaymasterInput != tt.wantInput {
+				t.Errorf("paymaster = %q with input %q, want %q with input %q", tx.Paymaster, tx.PaymasterInput, tt.wantPaymaster, tt.wantInput)
+			}
+		})
+	}
+}
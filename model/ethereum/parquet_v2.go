@@ -0,0 +1,140 @@
+package ethereum
+
+// ParquetBlockV2 represents a block in the typed parquet schema
+type ParquetBlockV2 struct {
+	Number                int64    `parquet:"name=block_number, type=INT64"`
+	Hash                  string   `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ParentHash            string   `parquet:"name=parent_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Nonce                 string   `parquet:"name=nonce, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SHA3Uncles            string   `parquet:"name=sha3_uncles, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LogsBloom             string   `parquet:"name=logs_bloom, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionsRoot      string   `parquet:"name=transactions_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	StateRoot             string   `parquet:"name=state_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ReceiptsRoot          string   `parquet:"name=receipts_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Miner                 string   `parquet:"name=miner, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Difficulty            *string  `parquet:"name=difficulty, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	TotalDifficulty       *string  `parquet:"name=total_difficulty, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	ExtraData             string   `parquet:"name=extra_data, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Size                  int64    `parquet:"name=size, type=INT64"`
+	GasLimit              int64    `parquet:"name=gas_limit, type=INT64"`
+	GasUsed               int64    `parquet:"name=gas_used, type=INT64"`
+	Timestamp             int64    `parquet:"name=timestamp, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Uncles                []string `parquet:"name=uncles, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	BaseFeePerGas         *string  `parquet:"name=base_fee_per_gas, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	MixHash               string   `parquet:"name=mix_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	WithdrawalsRoot       string   `parquet:"name=withdrawals_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BlobGasUsed           *int64   `parquet:"name=blob_gas_used, type=INT64, repetitiontype=OPTIONAL"`
+	ExcessBlobGas         *int64   `parquet:"name=excess_blob_gas, type=INT64, repetitiontype=OPTIONAL"`
+	ParentBeaconBlockRoot string   `parquet:"name=parent_beacon_block_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetTransactionV2 represents a transaction in the typed parquet schema
+type ParquetTransactionV2 struct {
+	BlockNumber          int64    `parquet:"name=block_number, type=INT64"`
+	BlockHash            string   `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Hash                 string   `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	From                 string   `parquet:"name=from_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	To                   string   `parquet:"name=to_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Value                string   `parquet:"name=value, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38"`
+	Gas                  int64    `parquet:"name=gas, type=INT64"`
+	GasPrice             *string  `parquet:"name=gas_price, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	Input                string   `parquet:"name=input, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Type                 *int64   `parquet:"name=type, type=INT64, repetitiontype=OPTIONAL"`
+	Nonce                int64    `parquet:"name=nonce, type=INT64"`
+	TransactionIndex     int64    `parquet:"name=transaction_index, type=INT64"`
+	V                    string   `parquet:"name=v, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	R                    string   `parquet:"name=r, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	S                    string   `parquet:"name=s, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	CumulativeGasUsed    int64    `parquet:"name=cumulative_gas_used, type=INT64"`
+	EffectiveGasPrice    *string  `parquet:"name=effective_gas_price, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	MaxFeePerGas         *string  `parquet:"name=max_fee_per_gas, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	MaxPriorityFeePerGas *string  `parquet:"name=max_priority_fee_per_gas, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	GasUsed              int64    `parquet:"name=gas_used, type=INT64"`
+	LogsBloom            string   `parquet:"name=logs_bloom, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Status               *int64   `parquet:"name=status, type=INT64, repetitiontype=OPTIONAL"`
+	AccessList           []string `parquet:"name=access_list, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	MaxFeePerBlobGas     *string  `parquet:"name=max_fee_per_blob_gas, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	BlobVersionedHashes  []string `parquet:"name=blob_versioned_hashes, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	BlobGasPrice         *string  `parquet:"name=blob_gas_price, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+}
+
+// ParquetLogV2 represents a log in the typed parquet schema
+type ParquetLogV2 struct {
+	BlockNumber      int64    `parquet:"name=block_number, type=INT64"`
+	BlockHash        string   `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash  string   `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionIndex int64    `parquet:"name=transaction_index, type=INT64"`
+	LogIndex         int64    `parquet:"name=log_index, type=INT64"`
+	Address          string   `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Data             string   `parquet:"name=data, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Topics           []string `parquet:"name=topics, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	Removed          bool     `parquet:"name=removed, type=BOOLEAN"`
+}
+
+// ParquetTraceV2 represents a trace in the typed parquet schema
+type ParquetTraceV2 struct {
+	BlockNumber     int64   `parquet:"name=block_number, type=INT64"`
+	BlockHash       string  `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Hash            string  `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ParentHash      string  `parquet:"name=parent_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Index           int64   `parquet:"name=trace_index, type=INT64"`
+	TraceAddress    []int64 `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	Depth           int64   `parquet:"name=depth, type=INT64"`
+	Subtraces       int64   `parquet:"name=subtraces, type=INT64"`
+	Type            string  `parquet:"name=type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	From            string  `parquet:"name=from_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	To              string  `parquet:"name=to_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Value           *string `parquet:"name=value, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38, repetitiontype=OPTIONAL"`
+	Gas             *int64  `parquet:"name=gas, type=INT64, repetitiontype=OPTIONAL"`
+	GasUsed         *int64  `parquet:"name=gas_used, type=INT64, repetitiontype=OPTIONAL"`
+	Input           string  `parquet:"name=input, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Output          string  `parquet:"name=output, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Error           string  `parquet:"name=error, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	RevertReason    string  `parquet:"name=revert_reason, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetWithdrawalV2 represents a withdrawal in the typed parquet schema; amount is in gwei
+type ParquetWithdrawalV2 struct {
+	BlockNumber    int64  `parquet:"name=block_number, type=INT64"`
+	Index          int64  `parquet:"name=index, type=INT64"`
+	ValidatorIndex int64  `parquet:"name=validator_index, type=INT64"`
+	Address        string `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Amount         int64  `parquet:"name=amount, type=INT64"`
+}
+
+// ParquetBlobV2 represents a blob in the typed parquet schema
+type ParquetBlobV2 struct {
+	BlockNumber      int64  `parquet:"name=block_number, type=INT64"`
+	BlockHash        string `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash  string `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionIndex int64  `parquet:"name=transaction_index, type=INT64"`
+	Index            int64  `parquet:"name=blob_index, type=INT64"`
+	VersionedHash    string `parquet:"name=versioned_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetBeaconBlockV2 represents the beacon block that carried an execution block in the typed parquet schema
+type ParquetBeaconBlockV2 struct {
+	BlockNumber   int64  `parquet:"name=block_number, type=INT64"`
+	BlockHash     string `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Slot          int64  `parquet:"name=slot, type=INT64"`
+	Root          string `parquet:"name=beacon_block_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ParentRoot    string `parquet:"name=parent_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	StateRoot     string `parquet:"name=state_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ProposerIndex int64  `parquet:"name=proposer_index, type=INT64"`
+	Graffiti      string `parquet:"name=graffiti, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BlobCount     int64  `parquet:"name=blob_count, type=INT64"`
+}
+
+// ParquetBlobSidecarV2 represents a blob sidecar in the typed parquet schema
+type ParquetBlobSidecarV2 struct {
+	BlockNumber   int64  `parquet:"name=block_number, type=INT64"`
+	BlockHash     string `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Slot          int64  `parquet:"name=slot, type=INT64"`
+	Root          string `parquet:"name=beacon_block_root, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Index         int64  `parquet:"name=blob_index, type=INT64"`
+	KzgCommitment string `parquet:"name=kzg_commitment, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	KzgProof      string `parquet:"name=kzg_proof, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	VersionedHash string `parquet:"name=versioned_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Size          int64  `parquet:"name=blob_size, type=INT64"`
+}
@@ -0,0 +1,113 @@
+// Package evm holds the parquet models of entities the shared EVM driver core writes for every chain alike, as
+// opposed to the per-chain models of blocks, transactions, logs and traces.
+package evm
+
+// ParquetTokenTransfer represents a token transfer decoded from an ERC-20, ERC-721 or ERC-1155 event log in parquet
+// form. Token ID and amount are uint256s, written as DECIMAL(76, 0) in both schema versions; token ID is null for ERC-20
+// transfers, and amount is 1 for ERC-721 transfers. A value above 10^76-1, as token IDs derived from a hash often are,
+// does not fit, and is left null with its hex in DecodeError. Operator is only set for ERC-1155 transfers. Batch index
+// is the transfer's position within an ERC-1155 TransferBatch, and 0 for every other event
+type ParquetTokenTransfer struct {
+	BlockNumber      string  `parquet:"name=block_number, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BlockHash        string  `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash  string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionIndex string  `parquet:"name=transaction_index, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LogIndex         string  `parquet:"name=log_index, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BatchIndex       int64   `parquet:"name=batch_index, type=INT64"`
+	TokenStandard    string  `parquet:"name=token_standard, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TokenAddress     string  `parquet:"name=token_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Operator         string  `parquet:"name=operator, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FromAddress      string  `parquet:"name=from_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ToAddress        string  `parquet:"name=to_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TokenID          *string `parquet:"name=token_id, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=32, scale=0, precision=76, repetitiontype=OPTIONAL"`
+	Amount           *string `parquet:"name=amount, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=32, scale=0, precision=76, repetitiontype=OPTIONAL"`
+	DecodeError      string  `parquet:"name=decode_error, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetDecodedEvent represents a log decoded against a user-supplied ABI in parquet form. Args is a JSON object of
+// the event's named arguments; a log whose signature is known but whose topics or data do not decode has its reason
+// in DecodeError, and no args
+type ParquetDecodedEvent struct {
+	BlockNumber      string `parquet:"name=block_number, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BlockHash        string `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash  string `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionIndex string `parquet:"name=transaction_index, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LogIndex         string `parquet:"name=log_index, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Address          string `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	EventName        string `parquet:"name=event_name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	EventSignature   string `parquet:"name=event_signature, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Args             string `parquet:"name=args, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	DecodeError      string `parquet:"name=decode_error, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetDecodedCall represents a call frame decoded against a user-supplied ABI in parquet form; trace hash and
+// trace address locate the frame among the traces. Outputs is a JSON object of the function's named return values,
+// empty for calls that failed; DecodeError is as for ParquetDecodedEvent
+type ParquetDecodedCall struct {
+	BlockNumber       string  `parquet:"name=block_number, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BlockHash         string  `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash   string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceHash         string  `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceAddress      []int64 `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	FromAddress       string  `parquet:"name=from_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ToAddress         string  `parquet:"name=to_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FunctionName      string  `parquet:"name=function_name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FunctionSignature string  `parquet:"name=function_signature, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Args              string  `parquet:"name=args, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Outputs           string  `parquet:"name=outputs, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	DecodeError       string  `parquet:"name=decode_error, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetContract represents a contract deployment in parquet form. Deployer is the account that sent the creating
+// transaction, and factory the contract that executed the CREATE or CREATE2, which is empty when the transaction
+// deployed the contract itself. Bytecode hash is the keccak-256 of the deployed runtime code, and interfaces the token
+// standards whose functions the code dispatches on. Deployments only known from a receipt's contractAddress, on blocks
+// without traces, have no trace hash, bytecode hash or interfaces
+type ParquetContract struct {
+	BlockNumber     string   `parquet:"name=block_number, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BlockHash       string   `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash string   `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceHash       string   `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceAddress    []int64  `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	Address         string   `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Deployer        string   `parquet:"name=deployer_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Factory         string   `parquet:"name=factory_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	CreationType    string   `parquet:"name=creation_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BytecodeHash    string   `parquet:"name=bytecode_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Interfaces      []string `parquet:"name=interfaces, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+}
+
+// ParquetNativeTransfer represents a movement of the chain's native currency in parquet form: the value of a
+// transaction, of a call or creation made within one, of a selfdestruct sweeping a contract's balance to its
+// beneficiary, or of a beacon chain withdrawal. Trace hash and trace address place the frame that moved the value,
+// and are empty for withdrawals and for transfers of blocks without traces. Status is 1 if the value moved and 0 if
+// the transaction reverted; transfers within reverted frames are left out, as they never moved anything. Value is in
+// wei, withdrawal amounts included, and withdrawal index is only set for withdrawals
+type ParquetNativeTransfer struct {
+	BlockNumber     string  `parquet:"name=block_number, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BlockHash       string  `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceHash       string  `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceAddress    []int64 `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	TransferType    string  `parquet:"name=transfer_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	CallType        string  `parquet:"name=call_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FromAddress     string  `parquet:"name=from_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ToAddress       string  `parquet:"name=to_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Value           string  `parquet:"name=value, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Status          int64   `parquet:"name=status, type=INT64"`
+	WithdrawalIndex string  `parquet:"name=withdrawal_index, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetReorg represents the audit record of a chain reorganization the driver recovered from. Block number is the
+// block whose parent hash no longer matched the written files, and common ancestor the highest block both still agreed
+// on; the orphaned heights above it are listed lowest first, each with the hash that was overwritten and the hash that
+// replaced it. Reorg records are the same in either schema version
+type ParquetReorg struct {
+	DetectedAt     int64    `parquet:"name=detected_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	BlockNumber    int64    `parquet:"name=block_number, type=INT64"`
+	CommonAncestor int64    `parquet:"name=common_ancestor, type=INT64"`
+	Depth          int64    `parquet:"name=depth, type=INT64"`
+	Heights        []int64  `parquet:"name=heights, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	OldHashes      []string `parquet:"name=old_hashes, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	NewHashes      []string `parquet:"name=new_hashes, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+}
@@ -0,0 +1,82 @@
+package evm
+
+// ParquetTokenTransferV2 represents a token transfer in the typed parquet schema; token ID and amount are written as
+// in ParquetTokenTransfer
+type ParquetTokenTransferV2 struct {
+	BlockNumber      int64   `parquet:"name=block_number, type=INT64"`
+	BlockHash        string  `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash  string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionIndex int64   `parquet:"name=transaction_index, type=INT64"`
+	LogIndex         int64   `parquet:"name=log_index, type=INT64"`
+	BatchIndex       int64   `parquet:"name=batch_index, type=INT64"`
+	TokenStandard    string  `parquet:"name=token_standard, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TokenAddress     string  `parquet:"name=token_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Operator         string  `parquet:"name=operator, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FromAddress      string  `parquet:"name=from_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ToAddress        string  `parquet:"name=to_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TokenID          *string `parquet:"name=token_id, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=32, scale=0, precision=76, repetitiontype=OPTIONAL"`
+	Amount           *string `parquet:"name=amount, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=32, scale=0, precision=76, repetitiontype=OPTIONAL"`
+	DecodeError      string  `parquet:"name=decode_error, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetDecodedEventV2 represents a decoded log in the typed parquet schema
+type ParquetDecodedEventV2 struct {
+	BlockNumber      int64  `parquet:"name=block_number, type=INT64"`
+	BlockHash        string `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash  string `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionIndex int64  `parquet:"name=transaction_index, type=INT64"`
+	LogIndex         int64  `parquet:"name=log_index, type=INT64"`
+	Address          string `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	EventName        string `parquet:"name=event_name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	EventSignature   string `parquet:"name=event_signature, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Args             string `parquet:"name=args, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	DecodeError      string `parquet:"name=decode_error, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetDecodedCallV2 represents a decoded call frame in the typed parquet schema
+type ParquetDecodedCallV2 struct {
+	BlockNumber       int64   `parquet:"name=block_number, type=INT64"`
+	BlockHash         string  `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash   string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceHash         string  `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceAddress      []int64 `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	FromAddress       string  `parquet:"name=from_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ToAddress         string  `parquet:"name=to_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FunctionName      string  `parquet:"name=function_name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FunctionSignature string  `parquet:"name=function_signature, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Args              string  `parquet:"name=args, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Outputs           string  `parquet:"name=outputs, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	DecodeError       string  `parquet:"name=decode_error, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// ParquetContractV2 represents a contract deployment in the typed parquet schema
+type ParquetContractV2 struct {
+	BlockNumber     int64    `parquet:"name=block_number, type=INT64"`
+	BlockHash       string   `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash string   `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceHash       string   `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceAddress    []int64  `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	Address         string   `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Deployer        string   `parquet:"name=deployer_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Factory         string   `parquet:"name=factory_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	CreationType    string   `parquet:"name=creation_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BytecodeHash    string   `parquet:"name=bytecode_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Interfaces      []string `parquet:"name=interfaces, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+}
+
+// ParquetNativeTransferV2 represents a native currency transfer in the typed parquet schema. Withdrawal index is null
+// for every transfer but a withdrawal
+type ParquetNativeTransferV2 struct {
+	BlockNumber     int64   `parquet:"name=block_number, type=INT64"`
+	BlockHash       string  `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionHash string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceHash       string  `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TraceAddress    []int64 `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	TransferType    string  `parquet:"name=transfer_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	CallType        string  `parquet:"name=call_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FromAddress     string  `parquet:"name=from_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ToAddress       string  `parquet:"name=to_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Value           string  `parquet:"name=value, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, length=16, scale=0, precision=38"`
+	Status          int64   `parquet:"name=status, type=INT64"`
+	WithdrawalIndex *int64  `parquet:"name=withdrawal_index, type=INT64, repetitiontype=OPTIONAL"`
+}
@@ -0,0 +1,298 @@
+// Package audit walks the range directories written for a chain and checks their files against what the node says
+// each height should hold. It reports the heights an entity is missing at, transactions files whose row count differs
+// from the block's transaction count, logs files whose row count differs from the logs of the block's receipts, and
+// traces files left empty where the block should have traces, along with the heights to ingest again.
+//
+// Only the blocks, transactions, logs and traces entities are audited; the others are derived from these and may
+// legitimately be absent at any height.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
+	"github.com/pkg/errors"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Kinds of Issue
+const (
+	// IssueMissing is a height with rows expected but no file covering it
+	IssueMissing = "missing"
+	// IssueCountMismatch is a transactions or logs file whose row count differs from what the node says
+	IssueCountMismatch = "count_mismatch"
+	// IssueEmptyTraces is a traces file without rows for a height that should have traces
+	IssueEmptyTraces = "empty_traces"
+)
+
+// Audited entities; these match the directory names the EVM drivers write
+const (
+	entityBlocks       = "blocks"
+	entityTransactions = "transactions"
+	entityLogs         = "logs"
+	entityTraces       = "traces"
+)
+
+// Config controls which heights are audited and how
+type Config struct {
+	// DirectoryRange is the range directory size, as passed to util.RangeName
+	DirectoryRange uint64
+	// Concurrency is the number of heights whose expectations are fetched at once
+	Concurrency int
+}
+
+// Expectation is what the files of a height should hold
+type Expectation struct {
+	// Transactions is the number of transactions in the block
+	Transactions int64
+	// Logs is the number of logs across the block's receipts
+	Logs int64
+	// Traces is whether traces should have been written for the block
+	Traces bool
+}
+
+// Source knows what each height should hold, typically by fetching it from the node
+type Source interface {
+	// Expect returns the expectation for a height
+	Expect(ctx context.Context, height uint64) (*Expectation, error)
+	// WrittenEntities lists the entities written for every height; audited entities not among them are skipped
+	WrittenEntities() []string
+}
+
+// Issue is a problem found with an entity at a height
+type Issue struct {
+	Height   uint64 `json:"height"`
+	Entity   string `json:"entity"`
+	Kind     string `json:"kind"`
+	File     string `json:"file,omitempty"`
+	Expected int64  `json:"expected"`
+	Actual   int64  `json:"actual"`
+}
+
+// Report is the outcome of an audit
+type Report struct {
+	From   uint64  `json:"from"`
+	To     uint64  `json:"to"`
+	Issues []Issue `json:"issues"`
+	// Reingest lists, in order, every height with an issue
+	Reingest []uint64 `json:"reingest"`
+}
+
+// Auditor audits the files held in a Store against a Source
+type Auditor struct {
+	store  storage.Store
+	source Source
+	cfg    Config
+	logger frameworkUtil.Logger
+}
+
+// coverage is what the files of an entity hold for a height
+type coverage struct {
+	file string
+	rows int64
+}
+
+func New(store storage.Store, source Source, cfg Config, logger frameworkUtil.Logger) *Auditor {
+	return &Auditor{
+		store:  store,
+		source: source,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Audit checks heights from..to, one range directory at a time
+func (a *Auditor) Audit(ctx context.Context, from uint64, to uint64) (*Report, error) {
+	if to < from {
+		return nil, errors.Errorf("invalid range: %d-%d", from, to)
+	}
+
+	written := make(map[string]bool)
+	for _, entity := range a.source.WrittenEntities() {
+		written[entity] = true
+	}
+	var entities []string
+	for _, entity := range []string{entityBlocks, entityTransactions, entityLogs, entityTraces} {
+		if written[entity] {
+			entities = append(entities, entity)
+		}
+	}
+
+	report := &Report{From: from, To: to, Issues: []Issue{}, Reingest: []uint64{}}
+	for start := from; start <= to; start = (start/a.cfg.DirectoryRange + 1) * a.cfg.DirectoryRange {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := (start/a.cfg.DirectoryRange+1)*a.cfg.DirectoryRange - 1
+		if end > to {
+			end = to
+		}
+		if err := a.auditRange(ctx, report, entities, start, end); err != nil {
+			return nil, err
+		}
+	}
+
+	reingest := make(map[uint64]bool)
+	for _, issue := range report.Issues {
+		if !reingest[issue.Height] {
+			reingest[issue.Height] = true
+			report.Reingest = append(report.Reingest, issue.Height)
+		}
+	}
+	sort.Slice(report.Reingest, func(i, j int) bool { return report.Reingest[i] < report.Reingest[j] })
+
+	return report, nil
+}
+
+// auditRange checks heights from..to, which share a range directory
+func (a *Auditor) auditRange(ctx context.Context, report *Report, entities []string, from uint64, to uint64) error {
+	rangeName := util.RangeName(from, a.cfg.DirectoryRange)
+	covered := make(map[string]map[uint64]coverage, len(entities))
+	for _, entity := range entities {
+		entityCovered, err := a.listCoverage(ctx, entity, rangeName)
+		if err != nil {
+			return errors.Wrapf(err, "cannot list %s/%s", entity, rangeName)
+		}
+		covered[entity] = entityCovered
+	}
+
+	expectations, err := a.expect(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	for height := from; height <= to; height++ {
+		expected := expectations[height-from]
+		for _, entity := range entities {
+			if issue := check(entity, height, expected, covered[entity]); issue != nil {
+				report.Issues = append(report.Issues, *issue)
+			}
+		}
+	}
+	a.logger.Infof("audited %s for blocks %d to %d", rangeName, from, to)
+
+	return nil
+}
+
+// check compares what an entity's files hold for a height with what the height should hold, returning nil if they
+// agree
+func check(entity string, height uint64, expected *Expectation, covered map[uint64]coverage) *Issue {
+	var want int64
+	var required bool
+	switch entity {
+	case entityBlocks:
+		want, required = 1, true
+	case entityTransactions:
+		want, required = expected.Transactions, expected.Transactions > 0
+	case entityLogs:
+		//	A logs file is written for every block with transactions, even one whose transactions logged nothing
+		want, required = expected.Logs, expected.Transactions > 0
+	case entityTraces:
+		required = expected.Traces
+	}
+
+	got, ok := covered[height]
+	if !ok {
+		if !required {
+			return nil
+		}
+		return &Issue{Height: height, Entity: entity, Kind: IssueMissing, Expected: want}
+	}
+
+	switch entity {
+	case entityTraces:
+		if expected.Traces && got.rows == 0 {
+			return &Issue{Height: height, Entity: entity, Kind: IssueEmptyTraces, File: got.file}
+		}
+	case entityTransactions, entityLogs:
+		if got.rows != want {
+			return &Issue{Height: height, Entity: entity, Kind: IssueCountMismatch, File: got.file, Expected: want, Actual: got.rows}
+		}
+	}
+	return nil
+}
+
+// listCoverage returns what the files of an entity's range directory hold for each height they cover. A file of a
+// single height is counted from its footer, and a batched or compacted file from its manifest
+func (a *Auditor) listCoverage(ctx context.Context, entity string, rangeName string) (map[uint64]coverage, error) {
+	files, err := a.store.List(ctx, fmt.Sprintf("%s/%s/", entity, rangeName))
+	if err != nil {
+		return nil, err
+	}
+
+	covered := make(map[uint64]coverage)
+	for _, file := range files {
+		start, _, err := util.ParseFileHeights(file.Name)
+		if err != nil {
+			a.logger.Warnf("ignoring unexpected file %s: %v", file.Name, err)
+			continue
+		}
+
+		//	Files of a single height are named <height>.parquet, and batched or compacted files <first>-<last>.parquet
+		if !strings.Contains(path.Base(file.Name), "-") {
+			rows, err := storage.CountRows(ctx, a.store, file.Name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot count rows of %s", file.Name)
+			}
+			covered[start] = coverage{file: file.Name, rows: rows}
+			continue
+		}
+
+		manifest, err := storage.ReadManifest(ctx, a.store, file.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read manifest of %s", file.Name)
+		}
+		//	The batch writer treats heights without a manifest as unwritten, and so does the audit
+		if manifest == nil {
+			a.logger.Warnf("ignoring batched file %s, which has no manifest", file.Name)
+			continue
+		}
+		if len(manifest.Heights) != len(manifest.RowCounts) {
+			return nil, errors.Errorf("manifest of %s lists %d heights but %d row counts", file.Name, len(manifest.Heights), len(manifest.RowCounts))
+		}
+		for i, height := range manifest.Heights {
+			covered[uint64(height)] = coverage{file: file.Name, rows: manifest.RowCounts[i]}
+		}
+	}
+
+	return covered, nil
+}
+
+// expect fetches the expectations of heights from..to, several at a time
+func (a *Auditor) expect(ctx context.Context, from uint64, to uint64) ([]*Expectation, error) {
+	concurrency := a.cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	expectations := make([]*Expectation, to-from+1)
+	errs := make([]error, len(expectations))
+	heights := make(chan uint64)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for height := range heights {
+				expectations[height-from], errs[height-from] = a.source.Expect(ctx, height)
+			}
+		}()
+	}
+	for height := from; height <= to; height++ {
+		heights <- height
+	}
+	close(heights)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot fetch expectation for block %d", from+uint64(i))
+		}
+	}
+	return expectations, nil
+}
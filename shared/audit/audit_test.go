@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/compaction"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"reflect"
+	"testing"
+)
+
+const testDirectoryRange = 10
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+type testRow struct {
+	Height int64 `parquet:"name=height, type=INT64"`
+}
+
+// testSource expects every height to hold a block of two transactions, which are not traced
+type testSource struct{}
+
+func (testSource) Expect(context.Context, uint64) (*Expectation, error) {
+	return &Expectation{Transactions: 2}, nil
+}
+
+func (testSource) WrittenEntities() []string {
+	return []string{entityBlocks, entityTransactions}
+}
+
+// testRows returns an entity's rows for heights 0-9 as written with two holes: transactions are missing at height 3
+// and short a row at height 5
+func testRows(entity string) map[uint64][]interface{} {
+	rows := make(map[uint64][]interface{})
+	for height := uint64(0); height < testDirectoryRange; height++ {
+		n := 1
+		if entity == entityTransactions {
+			n = 2
+			if height == 3 {
+				continue
+			}
+			if height == 5 {
+				n = 1
+			}
+		}
+		for i := 0; i < n; i++ {
+			rows[height] = append(rows[height], &testRow{Height: int64(height)})
+		}
+	}
+	return rows
+}
+
+// writeBlocks writes a file per height, as in block mode
+func writeBlocks(ctx context.Context, store storage.Store, entity string, rows map[uint64][]interface{}) error {
+	for height, heightRows := range rows {
+		filename := fmt.Sprintf("%s/blocks_0-9/%d.parquet", entity, height)
+		if err := store.WriteMany(ctx, heightRows, new(testRow), filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBatches writes heights 0-4 and 5-9 as a batched file each, along with their manifests
+func writeBatches(ctx context.Context, store storage.Store, entity string, rows map[uint64][]interface{}) error {
+	batches := []map[uint64][]interface{}{{}, {}}
+	for height, heightRows := range rows {
+		batches[height/5][height] = heightRows
+	}
+	for _, batch := range batches {
+		if _, err := storage.WriteBatch(ctx, store, entity, batch, new(testRow), testDirectoryRange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, store storage.Store, entity string, rows map[uint64][]interface{}) error
+		// withoutManifest names a batched file whose manifest is deleted after writing
+		withoutManifest string
+		wantIssues      []Issue
+		wantReingest    []uint64
+	}{
+		{
+			name:  "block mode",
+			write: writeBlocks,
+			wantIssues: []Issue{
+				{Height: 3, Entity: entityTransactions, Kind: IssueMissing, Expected: 2},
+				{Height: 5, Entity: entityTransactions, Kind: IssueCountMismatch, File: "transactions/blocks_0-9/5.parquet", Expected: 2, Actual: 1},
+			},
+			wantReingest: []uint64{3, 5},
+		},
+		{
+			name:  "batched",
+			write: writeBatches,
+			wantIssues: []Issue{
+				{Height: 3, Entity: entityTransactions, Kind: IssueMissing, Expected: 2},
+				{Height: 5, Entity: entityTransactions, Kind: IssueCountMismatch, File: "transactions/blocks_0-9/5-9.parquet", Expected: 2, Actual: 1},
+			},
+			wantReingest: []uint64{3, 5},
+		},
+		{
+			name: "compacted",
+			write: func(ctx context.Context, store storage.Store, entity string, rows map[uint64][]interface{}) error {
+				if err := writeBlocks(ctx, store, entity, rows); err != nil {
+					return err
+				}
+				compactor := compaction.New(store, compaction.Config{DirectoryRange: testDirectoryRange, Before: testDirectoryRange}, testLogger{})
+				return compactor.CompactRange(ctx, entity, "blocks_0-9", new(testRow))
+			},
+			wantIssues: []Issue{
+				{Height: 3, Entity: entityTransactions, Kind: IssueMissing, Expected: 2},
+				{Height: 5, Entity: entityTransactions, Kind: IssueCountMismatch, File: "transactions/blocks_0-9/0-9.parquet", Expected: 2, Actual: 1},
+			},
+			wantReingest: []uint64{3, 5},
+		},
+		{
+			//	Heights of a batched file without a manifest count as unwritten, as they do to the batch writer
+			name:            "batched file without a manifest",
+			write:           writeBatches,
+			withoutManifest: "blocks/blocks_0-9/0-4.parquet",
+			wantIssues: []Issue{
+				{Height: 0, Entity: entityBlocks, Kind: IssueMissing, Expected: 1},
+				{Height: 1, Entity: entityBlocks, Kind: IssueMissing, Expected: 1},
+				{Height: 2, Entity: entityBlocks, Kind: IssueMissing, Expected: 1},
+				{Height: 3, Entity: entityBlocks, Kind: IssueMissing, Expected: 1},
+				{Height: 3, Entity: entityTransactions, Kind: IssueMissing, Expected: 2},
+				{Height: 4, Entity: entityBlocks, Kind: IssueMissing, Expected: 1},
+				{Height: 5, Entity: entityTransactions, Kind: IssueCountMismatch, File: "transactions/blocks_0-9/5-9.parquet", Expected: 2, Actual: 1},
+			},
+			wantReingest: []uint64{0, 1, 2, 3, 4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := storage.NewMemoryConnector(testDirectoryRange)
+			for _, entity := range []string{entityBlocks, entityTransactions} {
+				if err := tt.write(ctx, store, entity, testRows(entity)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.withoutManifest != "" {
+				if err := store.Delete(ctx, storage.ManifestFilename(tt.withoutManifest)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			auditor := New(store, testSource{}, Config{DirectoryRange: testDirectoryRange}, testLogger{})
+			report, err := auditor.Audit(ctx, 0, testDirectoryRange-1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(report.Issues, tt.wantIssues) {
+				t.Errorf("issues = %+v, want %+v", report.Issues, tt.wantIssues)
+			}
+			if !reflect.DeepEqual(report.Reingest, tt.wantReingest) {
+				t.Errorf("reingest = %v, want %v", report.Reingest, tt.wantReingest)
+			}
+		})
+	}
+}
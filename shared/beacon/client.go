@@ -0,0 +1,125 @@
+// Package beacon reads consensus layer data from a beacon node's REST API, to join post-Merge execution blocks to the
+// beacon blocks that carried them.
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Beacon API paths the client reads
+const (
+	pathGenesis      = "/eth/v1/beacon/genesis"
+	pathHeader       = "/eth/v1/beacon/headers/%s"
+	pathBlock        = "/eth/v2/beacon/blocks/%s"
+	pathBlobSidecars = "/eth/v1/beacon/blob_sidecars/%s"
+)
+
+// Transport fetches the body of a beacon API response by path. HTTPTransport fetches it from a beacon node, and
+// fixture.BeaconTransport from recorded files
+type Transport interface {
+	Get(ctx context.Context, path string) ([]byte, error)
+}
+
+// HTTPTransport is a Transport to a beacon node's REST API
+type HTTPTransport struct {
+	host   string
+	client *http.Client
+}
+
+// NewHTTPTransport constructs a Transport to the configured beacon node
+func NewHTTPTransport(cfg *Config) *HTTPTransport {
+	return &HTTPTransport{
+		host:   strings.TrimSuffix(cfg.Host, "/"),
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Get requests a path from the beacon node, failing on any status but 200 OK
+func (t *HTTPTransport) Get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.host+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("beacon API %s returned %d: %s", path, res.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+// Client reads consensus layer data through a Transport
+type Client struct {
+	transport      Transport
+	secondsPerSlot uint64
+
+	genesisMu sync.Mutex
+	genesis   *Genesis
+}
+
+// NewClient constructs a Client
+func NewClient(cfg *Config, transport Transport) *Client {
+	return &Client{transport: transport, secondsPerSlot: cfg.SecondsPerSlot}
+}
+
+// GetGenesis gets the beacon chain's genesis, which is only requested once
+func (c *Client) GetGenesis(ctx context.Context) (*Genesis, error) {
+	c.genesisMu.Lock()
+	defer c.genesisMu.Unlock()
+
+	if c.genesis == nil {
+		genesis, err := get[*Genesis](ctx, c.transport, pathGenesis)
+		if err != nil {
+			return nil, err
+		}
+		c.genesis = genesis
+	}
+	return c.genesis, nil
+}
+
+// GetHeader gets a block header by block ID: a slot, a block root, or one of the named IDs such as "head"
+func (c *Client) GetHeader(ctx context.Context, blockID string) (*Header, error) {
+	return get[*Header](ctx, c.transport, fmt.Sprintf(pathHeader, blockID))
+}
+
+// GetBlock gets a block by block ID
+func (c *Client) GetBlock(ctx context.Context, blockID string) (*Block, error) {
+	return get[*Block](ctx, c.transport, fmt.Sprintf(pathBlock, blockID))
+}
+
+// GetBlobSidecars gets the blob sidecars of a block by block ID; nodes prune them after about 18 days, after which
+// the list is empty
+func (c *Client) GetBlobSidecars(ctx context.Context, blockID string) ([]*BlobSidecar, error) {
+	return get[[]*BlobSidecar](ctx, c.transport, fmt.Sprintf(pathBlobSidecars, blockID))
+}
+
+// get fetches a path and unwraps the data of its response
+func get[T any](ctx context.Context, transport Transport, path string) (T, error) {
+	var res response[T]
+	body, err := transport.Get(ctx, path)
+	if err != nil {
+		return res.Data, err
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return res.Data, errors.Wrapf(err, "cannot decode beacon API %s", path)
+	}
+	return res.Data, nil
+}
@@ -0,0 +1,26 @@
+package beacon
+
+import (
+	"github.com/caarlos0/env/v7"
+	"github.com/coherentopensource/go-service-framework/util"
+	"time"
+)
+
+// Config stores configurable properties of the beacon API client
+type Config struct {
+	// Host is the beacon node's REST API, e.g. http://localhost:5052; without one no consensus layer data is fetched
+	Host    string        `env:"BEACON_HOST"`
+	Timeout time.Duration `env:"BEACON_TIMEOUT" envDefault:"30s"`
+	// SecondsPerSlot maps execution block timestamps to slots; 12 on Ethereum mainnet and its testnets
+	SecondsPerSlot uint64 `env:"BEACON_SECONDS_PER_SLOT" envDefault:"12"`
+}
+
+// MustParseConfig uses env.Parse to initialize config with environment variables
+func MustParseConfig(logger util.Logger) *Config {
+	var cfg Config
+	if err := env.Parse(&cfg); err != nil {
+		logger.Fatalf("could not parse beacon API config: %v", err)
+	}
+
+	return &cfg
+}
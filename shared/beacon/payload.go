@@ -0,0 +1,79 @@
+package beacon
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// PayloadBlock is the beacon block that carried an execution payload, with the sidecars of the blobs it committed to
+type PayloadBlock struct {
+	Root               string
+	Header             HeaderMessage
+	Graffiti           string
+	BlobKzgCommitments []string
+	// Sidecars are empty if the block committed to no blobs, or the node has pruned them
+	Sidecars []*BlobSidecar
+}
+
+// GetPayloadBlock gets the beacon block that carried an execution payload. The block is looked up at the slot of the
+// payload's timestamp, then checked against the payload's hash and, for payloads since Dencun, which name it, their
+// parent beacon block root
+func (c *Client) GetPayloadBlock(ctx context.Context, timestamp uint64, blockHash string, parentBeaconBlockRoot string) (*PayloadBlock, error) {
+	genesis, err := c.GetGenesis(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp < genesis.GenesisTime {
+		return nil, errors.Errorf("execution block %s predates the beacon chain", blockHash)
+	}
+	slot := strconv.FormatUint((timestamp-genesis.GenesisTime)/c.secondsPerSlot, 10)
+
+	header, err := c.GetHeader(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	message := header.Header.Message
+	if parentBeaconBlockRoot != "" && !strings.EqualFold(message.ParentRoot, parentBeaconBlockRoot) {
+		return nil, errors.Errorf("beacon block at slot %s has parent root %s, but execution block %s expects %s", slot, message.ParentRoot, blockHash, parentBeaconBlockRoot)
+	}
+
+	block, err := c.GetBlock(ctx, header.Root)
+	if err != nil {
+		return nil, err
+	}
+	body := block.Message.Body
+	if !strings.EqualFold(body.ExecutionPayload.BlockHash, blockHash) {
+		return nil, errors.Errorf("beacon block %s at slot %s carries execution block %s, not %s", header.Root, slot, body.ExecutionPayload.BlockHash, blockHash)
+	}
+
+	out := PayloadBlock{
+		Root:               header.Root,
+		Header:             message,
+		Graffiti:           body.Graffiti,
+		BlobKzgCommitments: body.BlobKzgCommitments,
+	}
+	if len(body.BlobKzgCommitments) == 0 {
+		return &out, nil
+	}
+
+	sidecars, err := c.GetBlobSidecars(ctx, header.Root)
+	if err != nil {
+		return nil, err
+	}
+	//	Pruned sidecars come back empty; any that do come back must match the block's commitments
+	if len(sidecars) > 0 {
+		if len(sidecars) != len(body.BlobKzgCommitments) {
+			return nil, errors.Errorf("beacon block %s has %d blob sidecars for %d commitments", header.Root, len(sidecars), len(body.BlobKzgCommitments))
+		}
+		for _, sidecar := range sidecars {
+			if sidecar.Index >= uint64(len(body.BlobKzgCommitments)) || !strings.EqualFold(sidecar.KzgCommitment, body.BlobKzgCommitments[sidecar.Index]) {
+				return nil, errors.Errorf("blob sidecar %d of beacon block %s does not match the block's commitments", sidecar.Index, header.Root)
+			}
+		}
+	}
+	out.Sidecars = sidecars
+
+	return &out, nil
+}
@@ -0,0 +1,175 @@
+package beacon_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/coherentopensource/evm-etl/shared/fixture"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	// testTimestamp, testBlockHash and testParentRoot are those of the execution block recorded in testdata/dencun
+	testTimestamp  = 1710338135
+	testBlockHash  = "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	testParentRoot = "0x7777777777777777777777777777777777777777777777777777777777777777"
+	testRoot       = "0x8888888888888888888888888888888888888888888888888888888888888888"
+
+	pathSidecars = "/eth/v1/beacon/blob_sidecars/" + testRoot
+	pathBlock    = "/eth/v2/beacon/blocks/" + testRoot
+)
+
+// overridingTransport replays recorded responses, except for the paths it has a body of its own for, and records the
+// paths requested of it
+type overridingTransport struct {
+	inner     beacon.Transport
+	overrides map[string]string
+	paths     []string
+}
+
+func (t *overridingTransport) Get(ctx context.Context, path string) ([]byte, error) {
+	t.paths = append(t.paths, path)
+	if body, ok := t.overrides[path]; ok {
+		return []byte(body), nil
+	}
+	return t.inner.Get(ctx, path)
+}
+
+// sidecarsBody is a blob sidecars response with a sidecar for each commitment, indexed in order
+func sidecarsBody(commitments ...string) string {
+	var sidecars []string
+	for i, commitment := range commitments {
+		sidecars = append(sidecars, fmt.Sprintf(`{"index":"%d","blob":"0x00","kzg_commitment":"%s","kzg_proof":"0x"}`, i, commitment))
+	}
+	return `{"data":[` + strings.Join(sidecars, ",") + `]}`
+}
+
+func TestGetPayloadBlock(t *testing.T) {
+	commitmentA := "0x" + strings.Repeat("aa", 48)
+	commitmentB := "0x" + strings.Repeat("bb", 48)
+	commitmentC := "0x" + strings.Repeat("cc", 48)
+
+	tests := []struct {
+		name                  string
+		overrides             map[string]string
+		timestamp             uint64
+		blockHash             string
+		parentBeaconBlockRoot string
+		wantErr               bool
+		// wantSidecars are the versioned hashes of the sidecars joined to the block
+		wantSidecars []string
+		wantPaths    []string
+	}{
+		{
+			name:                  "blobs",
+			parentBeaconBlockRoot: testParentRoot,
+			wantSidecars: []string{
+				"0x01659f8a49133759d495ee5d15262cdc0050f9027e20c7bed3e0599e27adec4b",
+				"0x018fc9d98c32189fe8232b46db86446b16895b4e2a911803d4b9c1d229838914",
+			},
+		},
+		{
+			//	Payloads before Dencun do not name their parent beacon block root, so only the block hash is checked
+			name: "no parent root to check",
+			wantSidecars: []string{
+				"0x01659f8a49133759d495ee5d15262cdc0050f9027e20c7bed3e0599e27adec4b",
+				"0x018fc9d98c32189fe8232b46db86446b16895b4e2a911803d4b9c1d229838914",
+			},
+		},
+		{
+			name:                  "pruned sidecars",
+			overrides:             map[string]string{pathSidecars: `{"data":[]}`},
+			parentBeaconBlockRoot: testParentRoot,
+		},
+		{
+			//	A block committing to no blobs has no sidecars to request
+			name: "no blobs",
+			overrides: map[string]string{
+				pathBlock: `{"data":{"message":{"slot":"8626176","body":{"execution_payload":{"block_hash":"` + testBlockHash + `"},"blob_kzg_commitments":[]}}}}`,
+			},
+			parentBeaconBlockRoot: testParentRoot,
+			wantPaths: []string{
+				"/eth/v1/beacon/genesis",
+				"/eth/v1/beacon/headers/8626176",
+				pathBlock,
+			},
+		},
+		{
+			name:                  "sidecar of another commitment",
+			overrides:             map[string]string{pathSidecars: sidecarsBody(commitmentA, commitmentC)},
+			parentBeaconBlockRoot: testParentRoot,
+			wantErr:               true,
+		},
+		{
+			name:                  "sidecars in another order",
+			overrides:             map[string]string{pathSidecars: sidecarsBody(commitmentB, commitmentA)},
+			parentBeaconBlockRoot: testParentRoot,
+			wantErr:               true,
+		},
+		{
+			name:                  "sidecar missing",
+			overrides:             map[string]string{pathSidecars: sidecarsBody(commitmentA)},
+			parentBeaconBlockRoot: testParentRoot,
+			wantErr:               true,
+		},
+		{
+			//	The slot was missed, so the beacon block found there builds on another parent
+			name:                  "other parent root",
+			parentBeaconBlockRoot: "0x6666666666666666666666666666666666666666666666666666666666666666",
+			wantErr:               true,
+		},
+		{
+			name:      "other execution block",
+			blockHash: "0xcccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc",
+			wantErr:   true,
+		},
+		{
+			name:      "before genesis",
+			timestamp: 1606824022,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &overridingTransport{inner: fixture.NewBeaconTransport(filepath.Join("testdata", "dencun")), overrides: tt.overrides}
+			client := beacon.NewClient(&beacon.Config{SecondsPerSlot: 12}, transport)
+			timestamp, blockHash := tt.timestamp, tt.blockHash
+			if timestamp == 0 {
+				timestamp = testTimestamp
+			}
+			if blockHash == "" {
+				blockHash = testBlockHash
+			}
+
+			block, err := client.GetPayloadBlock(context.Background(), timestamp, blockHash, tt.parentBeaconBlockRoot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetPayloadBlock = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantPaths != nil && !reflect.DeepEqual(transport.paths, tt.wantPaths) {
+				t.Errorf("beacon requests = %v, want %v", transport.paths, tt.wantPaths)
+			}
+			if err != nil {
+				return
+			}
+
+			if block.Root != testRoot || block.Header.Slot != 8626176 {
+				t.Errorf("joined beacon block %s at slot %d, want %s at slot 8626176", block.Root, block.Header.Slot, testRoot)
+			}
+			var sidecars []string
+			for _, sidecar := range block.Sidecars {
+				versionedHash, err := sidecar.VersionedHash()
+				if err != nil {
+					t.Fatal(err)
+				}
+				sidecars = append(sidecars, versionedHash)
+			}
+			if !reflect.DeepEqual(sidecars, tt.wantSidecars) {
+				t.Errorf("sidecars = %v, want %v", sidecars, tt.wantSidecars)
+			}
+		})
+	}
+}
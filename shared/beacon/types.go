@@ -0,0 +1,95 @@
+package beacon
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// The types below decode the beacon API's JSON, reduced to the fields the ETL keeps. Unlike the execution layer's hex
+// quantities, the beacon API gives integers as decimal strings.
+
+// response is the envelope every beacon API response comes in
+type response[T any] struct {
+	Data T `json:"data"`
+}
+
+// Genesis is the beacon chain's genesis
+type Genesis struct {
+	GenesisTime uint64 `json:"genesis_time,string"`
+}
+
+// Header is a beacon block header, together with the root it hashes to
+type Header struct {
+	Root   string `json:"root"`
+	Header struct {
+		Message HeaderMessage `json:"message"`
+	} `json:"header"`
+}
+
+// HeaderMessage is the unsigned part of a beacon block header
+type HeaderMessage struct {
+	Slot          uint64 `json:"slot,string"`
+	ProposerIndex uint64 `json:"proposer_index,string"`
+	ParentRoot    string `json:"parent_root"`
+	StateRoot     string `json:"state_root"`
+	BodyRoot      string `json:"body_root"`
+}
+
+// Block is a signed beacon block, reduced to the fields of its body that tie it to the execution layer
+type Block struct {
+	Message struct {
+		Slot          uint64 `json:"slot,string"`
+		ProposerIndex uint64 `json:"proposer_index,string"`
+		ParentRoot    string `json:"parent_root"`
+		StateRoot     string `json:"state_root"`
+		Body          struct {
+			Graffiti         string `json:"graffiti"`
+			ExecutionPayload struct {
+				BlockHash   string `json:"block_hash"`
+				BlockNumber uint64 `json:"block_number,string"`
+			} `json:"execution_payload"`
+			BlobKzgCommitments []string `json:"blob_kzg_commitments"`
+		} `json:"body"`
+	} `json:"message"`
+}
+
+// BlobSidecar is a blob together with the KZG commitment and proof it is checked against. The blob itself is not
+// kept, only its size
+type BlobSidecar struct {
+	Index         uint64   `json:"index,string"`
+	Size          blobSize `json:"blob"`
+	KzgCommitment string   `json:"kzg_commitment"`
+	KzgProof      string   `json:"kzg_proof"`
+}
+
+// VersionedHash returns the hash execution layer transactions refer to the blob by: the SHA-256 of its commitment,
+// with the first byte replaced by the version
+func (s *BlobSidecar) VersionedHash() (string, error) {
+	commitment, err := hexutil.Decode(s.KzgCommitment)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid KZG commitment of blob %d", s.Index)
+	}
+	hash := sha256.Sum256(commitment)
+	hash[0] = versionedHashVersionKzg
+	return hexutil.Encode(hash[:]), nil
+}
+
+// versionedHashVersionKzg is the version byte of versioned hashes of KZG commitments
+const versionedHashVersionKzg = 0x01
+
+// blobSize decodes a hex blob into its size in bytes, so that the 128 KiB of data is never held on to
+type blobSize int64
+
+func (s *blobSize) UnmarshalJSON(data []byte) error {
+	var blob string
+	if err := json.Unmarshal(data, &blob); err != nil {
+		return err
+	}
+	if len(blob) < 2 || blob[:2] != "0x" {
+		return errors.New("blob is not hex")
+	}
+	*s = blobSize((len(blob) - 2) / 2)
+	return nil
+}
@@ -0,0 +1,192 @@
+// Package checkpoint records which entities have been written for each height, so that a crash between writers shows
+// up as a gap rather than going unnoticed. Completions are persisted by a pluggable Backend, partitioned by the same
+// range directories as the written files, and queried through a Tracker for the next height to process and the gaps
+// in a range.
+package checkpoint
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/pkg/errors"
+	"strings"
+	"sync"
+)
+
+// Supported values for a driver's CHECKPOINT_BACKEND
+const (
+	// BackendFile appends completions to files in a directory on local disk, and suits a single writer
+	BackendFile = "file"
+	// BackendManifest writes completions as parquet manifests to the store the entities are written to
+	BackendManifest = "manifest"
+)
+
+// Config selects and configures a Backend
+type Config struct {
+	// Backend is BackendFile or BackendManifest
+	Backend string
+	// Path is the directory completions are kept in: on local disk for BackendFile, and in the store for
+	// BackendManifest
+	Path string
+	// FlushSize is the number of completions BackendManifest buffers before writing a manifest
+	FlushSize int
+	// DirectoryRange is the range directory size, as passed to util.RangeName
+	DirectoryRange uint64
+}
+
+// NewBackend constructs the configured Backend; store is only used by BackendManifest
+func NewBackend(cfg Config, store storage.Store) (Backend, error) {
+	switch cfg.Backend {
+	case BackendFile:
+		return NewFileBackend(cfg.Path, cfg.DirectoryRange)
+	case BackendManifest:
+		return NewManifestBackend(store, cfg.Path, cfg.FlushSize, cfg.DirectoryRange)
+	default:
+		return nil, errors.Errorf("unsupported checkpoint backend %q; expected %s or %s", cfg.Backend, BackendFile, BackendManifest)
+	}
+}
+
+// Record is the completion of an entity at a height
+type Record struct {
+	Height int64  `parquet:"name=height, type=INT64"`
+	Entity string `parquet:"name=entity, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// Backend persists completions
+type Backend interface {
+	// Record persists completions; a backend may buffer them until Flush
+	Record(ctx context.Context, records []Record) error
+	// Load returns the completions recorded for heights from..to, including any still buffered
+	Load(ctx context.Context, from uint64, to uint64) ([]Record, error)
+	// Flush persists any buffered completions
+	Flush(ctx context.Context) error
+}
+
+// Gap is a run of consecutive heights that lack the same entities
+type Gap struct {
+	From    uint64   `json:"from"`
+	To      uint64   `json:"to"`
+	Missing []string `json:"missing"`
+}
+
+// Tracker records completions and answers queries over them
+type Tracker struct {
+	backend        Backend
+	directoryRange uint64
+	// mu orders completions against Flush, so that a flush persists every completion recorded before it began
+	mu sync.RWMutex
+}
+
+// New constructs a Tracker over a Backend; queries load one range directory at a time
+func New(backend Backend, directoryRange uint64) *Tracker {
+	return &Tracker{
+		backend:        backend,
+		directoryRange: directoryRange,
+	}
+}
+
+// Complete records that an entity has been written for heights
+func (t *Tracker) Complete(ctx context.Context, entity string, heights ...uint64) error {
+	records := make([]Record, len(heights))
+	for i, height := range heights {
+		records[i] = Record{Height: int64(height), Entity: entity}
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if err := t.backend.Record(ctx, records); err != nil {
+		return errors.Wrapf(err, "cannot record completion of %s", entity)
+	}
+	return nil
+}
+
+// Flush persists any completions the backend has buffered
+func (t *Tracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.backend.Flush(ctx)
+}
+
+// NextHeight returns the lowest height from a height on for which any of the entities has not been written
+func (t *Tracker) NextHeight(ctx context.Context, from uint64, entities []string) (uint64, error) {
+	for start := from; ; start = t.rangeEnd(start) + 1 {
+		end := t.rangeEnd(start)
+		done, err := t.load(ctx, start, end)
+		if err != nil {
+			return 0, err
+		}
+		for height := start; height <= end; height++ {
+			if len(missing(done[height], entities)) > 0 {
+				return height, nil
+			}
+		}
+	}
+}
+
+// Gaps lists the heights from..to for which any of the entities has not been written, along with the entities each
+// lacks
+func (t *Tracker) Gaps(ctx context.Context, from uint64, to uint64, entities []string) ([]Gap, error) {
+	var gaps []Gap
+	for start := from; start <= to; start = t.rangeEnd(start) + 1 {
+		end := t.rangeEnd(start)
+		if end > to {
+			end = to
+		}
+		done, err := t.load(ctx, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		for height := start; height <= end; height++ {
+			lacking := missing(done[height], entities)
+			if len(lacking) == 0 {
+				continue
+			}
+			if n := len(gaps); n > 0 && gaps[n-1].To == height-1 && sameEntities(gaps[n-1].Missing, lacking) {
+				gaps[n-1].To = height
+				continue
+			}
+			gaps = append(gaps, Gap{From: height, To: height, Missing: lacking})
+		}
+	}
+
+	return gaps, nil
+}
+
+// load returns the entities recorded for each of the heights from..to
+func (t *Tracker) load(ctx context.Context, from uint64, to uint64) (map[uint64]map[string]bool, error) {
+	records, err := t.backend.Load(ctx, from, to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load completions of blocks %d to %d", from, to)
+	}
+
+	done := make(map[uint64]map[string]bool)
+	for _, record := range records {
+		height := uint64(record.Height)
+		if done[height] == nil {
+			done[height] = make(map[string]bool)
+		}
+		done[height][record.Entity] = true
+	}
+	return done, nil
+}
+
+// rangeEnd returns the last height of the range directory holding a height
+func (t *Tracker) rangeEnd(height uint64) uint64 {
+	return (height/t.directoryRange+1)*t.directoryRange - 1
+}
+
+// missing returns the entities not among those done, in the order given
+func missing(done map[string]bool, entities []string) []string {
+	var out []string
+	for _, entity := range entities {
+		if !done[entity] {
+			out = append(out, entity)
+		}
+	}
+	return out
+}
+
+// sameEntities reports whether two lists of missing entities, in the same order, match
+func sameEntities(a []string, b []string) bool {
+	return strings.Join(a, ",") == strings.Join(b, ",")
+}
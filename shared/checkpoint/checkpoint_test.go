@@ -0,0 +1,271 @@
+package checkpoint
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const testDirectoryRange = 10
+
+var testEntities = []string{"blocks", "transactions"}
+
+// failingStore fails every write while fail is set
+type failingStore struct {
+	*storage.MemoryConnector
+	fail bool
+}
+
+func (f *failingStore) WriteMany(ctx context.Context, input []interface{}, mapToStruct interface{}, filename string) error {
+	if f.fail {
+		return errors.Errorf("cannot write %s", filename)
+	}
+	return f.MemoryConnector.WriteMany(ctx, input, mapToStruct, filename)
+}
+
+// records returns a completion of each entity for every height from..to
+func records(from uint64, to uint64, entities ...string) []Record {
+	var out []Record
+	for height := from; height <= to; height++ {
+		for _, entity := range entities {
+			out = append(out, Record{Height: int64(height), Entity: entity})
+		}
+	}
+	return out
+}
+
+func sortRecords(records []Record) []Record {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Height != records[j].Height {
+			return records[i].Height < records[j].Height
+		}
+		return records[i].Entity < records[j].Entity
+	})
+	return records
+}
+
+func TestTracker(t *testing.T) {
+	backends := []struct {
+		name string
+		new  func(t *testing.T) Backend
+	}{
+		{
+			name: "file",
+			new: func(t *testing.T) Backend {
+				backend, err := NewFileBackend(t.TempDir(), testDirectoryRange)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return backend
+			},
+		},
+		{
+			//	A flush size of 3 leaves some completions buffered and writes others out
+			name: "manifest",
+			new: func(t *testing.T) Backend {
+				backend, err := NewManifestBackend(storage.NewMemoryConnector(testDirectoryRange), "checkpoints", 3, testDirectoryRange)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return backend
+			},
+		},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			tracker := New(backend.new(t), testDirectoryRange)
+			//	Heights 0-24 have their blocks written, and all but 3, 4, 10 and 20 their transactions
+			for _, c := range []struct {
+				entity  string
+				heights []uint64
+			}{
+				{"blocks", []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24}},
+				{"transactions", []uint64{0, 1, 2, 5, 6, 7, 8, 9}},
+				{"transactions", []uint64{11, 12, 13, 14, 15, 16, 17, 18, 19, 21, 22, 23, 24}},
+			} {
+				if err := tracker.Complete(ctx, c.entity, c.heights...); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			gaps, err := tracker.Gaps(ctx, 2, 22, testEntities)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantGaps := []Gap{
+				{From: 3, To: 4, Missing: []string{"transactions"}},
+				{From: 10, To: 10, Missing: []string{"transactions"}},
+				{From: 20, To: 20, Missing: []string{"transactions"}},
+			}
+			if !reflect.DeepEqual(gaps, wantGaps) {
+				t.Errorf("gaps = %+v, want %+v", gaps, wantGaps)
+			}
+
+			//	A gap runs on across range directories
+			gaps, err = tracker.Gaps(ctx, 23, 31, testEntities)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantGaps = []Gap{{From: 25, To: 31, Missing: testEntities}}
+			if !reflect.DeepEqual(gaps, wantGaps) {
+				t.Errorf("gaps = %+v, want %+v", gaps, wantGaps)
+			}
+
+			for _, c := range []struct {
+				from uint64
+				want uint64
+			}{
+				{from: 0, want: 3},
+				{from: 5, want: 10},
+				{from: 11, want: 20},
+				{from: 21, want: 25},
+			} {
+				got, err := tracker.NextHeight(ctx, c.from, testEntities)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != c.want {
+					t.Errorf("next height from %d = %d, want %d", c.from, got, c.want)
+				}
+			}
+
+			//	Flushing leaves the answers as they were
+			if err := tracker.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := tracker.NextHeight(ctx, 0, testEntities); err != nil || got != 3 {
+				t.Errorf("next height after flush = %d, %v; want 3", got, err)
+			}
+		})
+	}
+}
+
+func TestManifestBackendRequeuesFailedWrites(t *testing.T) {
+	ctx := context.Background()
+	store := &failingStore{MemoryConnector: storage.NewMemoryConnector(testDirectoryRange), fail: true}
+	backend, err := NewManifestBackend(store, "checkpoints", 4, testDirectoryRange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.Record(ctx, records(8, 11, "blocks")); err == nil {
+		t.Fatal("recorded despite a failed write")
+	}
+	if err := backend.Record(ctx, records(12, 12, "blocks")); err == nil {
+		t.Fatal("recorded despite a failed write")
+	}
+	//	The completions are still buffered, so they are loaded all the same
+	got, err := backend.Load(ctx, 0, 19)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := records(8, 12, "blocks"); !reflect.DeepEqual(sortRecords(got), want) {
+		t.Errorf("records = %+v, want %+v", got, want)
+	}
+
+	store.fail = false
+	if err := backend.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.pending) != 0 {
+		t.Errorf("%d completions still buffered after flush", len(backend.pending))
+	}
+	files := store.FilesWithPrefix("checkpoints/")
+	if len(files) != 2 {
+		t.Fatalf("manifests = %v, want one per range directory", files)
+	}
+	got, err = backend.Load(ctx, 0, 19)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := records(8, 12, "blocks"); !reflect.DeepEqual(sortRecords(got), want) {
+		t.Errorf("records = %+v, want %+v", got, want)
+	}
+}
+
+func TestManifestBackendLoad(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryConnector(testDirectoryRange)
+	backend, err := NewManifestBackend(store, "checkpoints", 100, testDirectoryRange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//	Heights 5-24 are written in two flushes, each spanning several range directories, and 25-26 left buffered
+	for _, heights := range [][2]uint64{{5, 14}, {15, 24}} {
+		if err := backend.Record(ctx, records(heights[0], heights[1], testEntities...)); err != nil {
+			t.Fatal(err)
+		}
+		if err := backend.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := backend.Record(ctx, records(25, 26, testEntities...)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		from uint64
+		to   uint64
+		want []Record
+	}{
+		{name: "within a manifest", from: 6, to: 8, want: records(6, 8, testEntities...)},
+		{name: "across manifests and ranges", from: 8, to: 21, want: records(8, 21, testEntities...)},
+		{name: "including buffered", from: 23, to: 29, want: records(23, 26, testEntities...)},
+		{name: "before any", from: 0, to: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := backend.Load(ctx, tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sortRecords(got), tt.want) {
+				t.Errorf("records = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileBackendSkipsTruncatedLines(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	backend, err := NewFileBackend(dir, testDirectoryRange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Record(ctx, records(1, 2, "blocks")); err != nil {
+		t.Fatal(err)
+	}
+
+	//	A crash cut the last append short
+	file, err := os.OpenFile(filepath.Join(dir, "blocks_0-9.log"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString("3 "); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.Record(ctx, records(4, 4, "blocks")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := backend.Load(ctx, 0, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{{Height: 1, Entity: "blocks"}, {Height: 2, Entity: "blocks"}, {Height: 4, Entity: "blocks"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FileBackend appends completions to a log file per range directory, one "<height> <entity>" line per completion, in
+// a directory on local disk
+type FileBackend struct {
+	dir            string
+	directoryRange uint64
+	mu             sync.Mutex
+}
+
+// NewFileBackend constructs a FileBackend keeping its logs in a directory, which is created if need be
+func NewFileBackend(dir string, directoryRange uint64) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Errorf("cannot create checkpoint directory %s: %v", dir, err)
+	}
+
+	return &FileBackend{
+		dir:            dir,
+		directoryRange: directoryRange,
+	}, nil
+}
+
+// Record appends completions to the logs of their range directories, and syncs them to disk
+func (f *FileBackend) Record(ctx context.Context, records []Record) error {
+	lines := make(map[string]*strings.Builder)
+	for _, record := range records {
+		name := f.filename(uint64(record.Height))
+		if lines[name] == nil {
+			lines[name] = new(strings.Builder)
+		}
+		fmt.Fprintf(lines[name], "%d %s\n", record.Height, record.Entity)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for name, builder := range lines {
+		if err := appendSync(name, builder.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads back the completions of heights from..to from the logs of their range directories
+func (f *FileBackend) Load(ctx context.Context, from uint64, to uint64) ([]Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var out []Record
+	for start := from - from%f.directoryRange; start <= to; start += f.directoryRange {
+		name := f.filename(start)
+		raw, err := os.ReadFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read checkpoint log %s", name)
+		}
+
+		for _, line := range strings.Split(string(raw), "\n") {
+			height, entity, ok := strings.Cut(line, " ")
+			n, err := strconv.ParseUint(height, 10, 64)
+			//	Lines cut short by a crash mid-append are left unrecorded
+			if !ok || err != nil || entity == "" {
+				continue
+			}
+			if n >= from && n <= to {
+				out = append(out, Record{Height: int64(n), Entity: entity})
+			}
+		}
+	}
+	return out, nil
+}
+
+// Flush does nothing, as every completion is synced to disk as it is recorded
+func (f *FileBackend) Flush(ctx context.Context) error {
+	return nil
+}
+
+// filename names the log of the range directory holding a height
+func (f *FileBackend) filename(height uint64) string {
+	return filepath.Join(f.dir, util.RangeName(height, f.directoryRange)+".log")
+}
+
+// appendSync appends lines to a file, creating it if need be, and syncs it to disk. A line cut short by a crash is
+// ended first, so that it is not run into the next
+func appendSync(name string, data string) error {
+	file, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return errors.Wrapf(err, "cannot open checkpoint log %s", name)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrapf(err, "cannot stat checkpoint log %s", name)
+	}
+	if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+			file.Close()
+			return errors.Wrapf(err, "cannot read checkpoint log %s", name)
+		}
+		if last[0] != '\n' {
+			data = "\n" + data
+		}
+	}
+	if _, err := file.WriteString(data); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "cannot append to checkpoint log %s", name)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "cannot sync checkpoint log %s", name)
+	}
+	return file.Close()
+}
@@ -0,0 +1,167 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const parquetSuffix = ".parquet"
+
+// ManifestBackend buffers completions and writes them to a store as parquet manifests, named
+// <prefix>/<range>/<first>-<last>_<written at>.parquet after the heights they cover. Object stores cannot append, so
+// each flush adds manifests rather than extending one; completions still buffered at a crash are lost, and their
+// heights show up as gaps
+type ManifestBackend struct {
+	store          storage.Store
+	prefix         string
+	flushSize      int
+	directoryRange uint64
+	mu             sync.Mutex
+	pending        []Record
+}
+
+// NewManifestBackend constructs a ManifestBackend writing manifests beneath a prefix of a store, once flushSize
+// completions are buffered
+func NewManifestBackend(store storage.Store, prefix string, flushSize int, directoryRange uint64) (*ManifestBackend, error) {
+	if store == nil {
+		return nil, errors.New("manifest checkpoints need a store")
+	}
+	if flushSize <= 0 {
+		return nil, errors.New("checkpoint flush size must be positive")
+	}
+
+	return &ManifestBackend{
+		store:          store,
+		prefix:         prefix,
+		flushSize:      flushSize,
+		directoryRange: directoryRange,
+	}, nil
+}
+
+// Record buffers completions, writing them out once enough have been buffered
+func (m *ManifestBackend) Record(ctx context.Context, records []Record) error {
+	m.mu.Lock()
+	m.pending = append(m.pending, records...)
+	var full []Record
+	if len(m.pending) >= m.flushSize {
+		full = m.pending
+		m.pending = nil
+	}
+	m.mu.Unlock()
+
+	if full == nil {
+		return nil
+	}
+	return m.writeOrRequeue(ctx, full)
+}
+
+// Load reads back the completions of heights from..to from the manifests of their range directories, along with any
+// still buffered
+func (m *ManifestBackend) Load(ctx context.Context, from uint64, to uint64) ([]Record, error) {
+	var out []Record
+	for start := from - from%m.directoryRange; start <= to; start += m.directoryRange {
+		files, err := m.store.List(ctx, path.Join(m.prefix, util.RangeName(start, m.directoryRange))+"/")
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			first, last, err := manifestHeights(file.Name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unexpected checkpoint manifest %s", file.Name)
+			}
+			if last < from || first > to {
+				continue
+			}
+
+			var records []Record
+			if err := m.store.ReadMany(ctx, file.Name, new(Record), &records); err != nil {
+				return nil, errors.Wrapf(err, "cannot read checkpoint manifest %s", file.Name)
+			}
+			out = appendRange(out, records, from, to)
+		}
+	}
+
+	m.mu.Lock()
+	out = appendRange(out, m.pending, from, to)
+	m.mu.Unlock()
+
+	return out, nil
+}
+
+// Flush writes out every buffered completion
+func (m *ManifestBackend) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = nil
+	m.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	return m.writeOrRequeue(ctx, pending)
+}
+
+// writeOrRequeue writes completions out, buffering them again if they cannot be, so that the next flush retries them
+func (m *ManifestBackend) writeOrRequeue(ctx context.Context, records []Record) error {
+	err := m.write(ctx, records)
+	if err != nil {
+		m.mu.Lock()
+		m.pending = append(records, m.pending...)
+		m.mu.Unlock()
+	}
+	return err
+}
+
+// write outputs completions as a manifest per range directory
+func (m *ManifestBackend) write(ctx context.Context, records []Record) error {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Height != records[j].Height {
+			return records[i].Height < records[j].Height
+		}
+		return records[i].Entity < records[j].Entity
+	})
+
+	writtenAt := time.Now().UnixNano()
+	for len(records) > 0 {
+		rangeName := util.RangeName(uint64(records[0].Height), m.directoryRange)
+		n := sort.Search(len(records), func(i int) bool {
+			return util.RangeName(uint64(records[i].Height), m.directoryRange) != rangeName
+		})
+
+		rows := make([]interface{}, n)
+		for i := range records[:n] {
+			rows[i] = &records[i]
+		}
+		filename := path.Join(m.prefix, rangeName, fmt.Sprintf("%d-%d_%d%s", records[0].Height, records[n-1].Height, writtenAt, parquetSuffix))
+		if err := m.store.WriteMany(ctx, rows, new(Record), filename); err != nil {
+			return errors.Wrapf(err, "cannot write checkpoint manifest %s", filename)
+		}
+		records = records[n:]
+	}
+
+	return nil
+}
+
+// manifestHeights recovers the first and last height a manifest covers from its name
+func manifestHeights(filename string) (uint64, uint64, error) {
+	span, _, _ := strings.Cut(strings.TrimSuffix(path.Base(filename), parquetSuffix), "_")
+	return util.ParseFileHeights(span + parquetSuffix)
+}
+
+// appendRange appends the records of heights from..to
+func appendRange(out []Record, records []Record, from uint64, to uint64) []Record {
+	for _, record := range records {
+		if height := uint64(record.Height); height >= from && height <= to {
+			out = append(out, record)
+		}
+	}
+	return out
+}
@@ -0,0 +1,449 @@
+// Package compaction merges the many small parquet files written for a range directory (one per block, or one per
+// batch) into a few large, height-sorted files.
+//
+// Outputs are written with a batch manifest, as the batch writer's files are, so that the heights they hold can still
+// be read singly.
+//
+// Each range is compacted in phases recorded in a journal file, so an interrupted run picks up where it left off:
+//
+//	planned  the source files and output names are fixed and the expected row count is recorded
+//	written  every output has been written and its row count verified against the sources
+//	done     the sources have been archived and/or deleted
+//
+// Only ranges that end below Config.Before are touched, so compaction can run while a writer is still appending to
+// later ranges.
+//
+// Sources written before some of the model's columns were added are merged with those columns left empty, except
+// trace files that predate trace_address: a range holding one is refused with trace.ErrUnmigrated, as its tree
+// positions can only be rebuilt by cmd/migrate-trace-ids, which must run over the range first.
+package compaction
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	frameworkUtil "github.com/coherentopensource/go-service-framework/util"
+	"github.com/pkg/errors"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// journalPrefix is the top-level directory holding compaction journals
+	journalPrefix = "compactions"
+
+	phasePlanned = "planned"
+	phaseWritten = "written"
+	phaseDone    = "done"
+)
+
+// ErrNewerFiles is returned when files have appeared in a range after it was compacted, e.g. from a re-ingest
+var ErrNewerFiles = errors.New("range has files written after compaction")
+
+// Config controls which ranges are compacted and how
+type Config struct {
+	// DirectoryRange is the range directory size, as passed to util.RangeName
+	DirectoryRange uint64
+	// Before is the lowest height a writer may still be working on; only ranges ending below it are compacted
+	Before uint64
+	// TargetRows splits a range into several files of about this many rows, at file boundaries; zero writes a single
+	// file per range
+	TargetRows int64
+	// MinAge skips ranges containing any file modified more recently than this, as a guard against in-flight rewrites
+	MinAge time.Duration
+	// ArchivePrefix, if set, is a directory the originals are copied beneath before they are deleted
+	ArchivePrefix string
+}
+
+// Journal records the progress of compacting one range of one entity
+type Journal struct {
+	Entity   string   `parquet:"name=entity, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Range    string   `parquet:"name=range, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Phase    string   `parquet:"name=phase, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Sources  []string `parquet:"name=sources, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	Outputs  []string `parquet:"name=outputs, type=MAP, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	RowCount int64    `parquet:"name=row_count, type=INT64"`
+}
+
+// Compactor compacts range directories held in a Store
+type Compactor struct {
+	store  storage.Store
+	cfg    Config
+	logger frameworkUtil.Logger
+}
+
+// sourceFile is a data file found in a range directory, along with the heights it covers
+type sourceFile struct {
+	name  string
+	start uint64
+	end   uint64
+	rows  int64
+}
+
+func New(store storage.Store, cfg Config, logger frameworkUtil.Logger) *Compactor {
+	return &Compactor{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Compact compacts every completed range of an entity from the range containing height from up to Config.Before;
+// mapToStruct is the parquet model the entity's files were written with
+func (c *Compactor) Compact(ctx context.Context, entity string, mapToStruct interface{}, from uint64) error {
+	for bottom := (from / c.cfg.DirectoryRange) * c.cfg.DirectoryRange; bottom+c.cfg.DirectoryRange <= c.cfg.Before; bottom += c.cfg.DirectoryRange {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rangeName := util.RangeName(bottom, c.cfg.DirectoryRange)
+		err := c.CompactRange(ctx, entity, rangeName, mapToStruct)
+		if errors.Is(err, ErrNewerFiles) {
+			c.logger.Warnf("skipping %s/%s: %v", entity, rangeName, err)
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "cannot compact %s/%s", entity, rangeName)
+		}
+	}
+
+	return nil
+}
+
+// CompactRange compacts a single range directory of an entity, resuming from its journal if a previous run was
+// interrupted
+func (c *Compactor) CompactRange(ctx context.Context, entity string, rangeName string, mapToStruct interface{}) error {
+	_, top, err := util.ParseRangeName(rangeName)
+	if err != nil {
+		return err
+	}
+	if top >= c.cfg.Before {
+		return errors.Errorf("range %s is not complete below height %d", rangeName, c.cfg.Before)
+	}
+
+	journal, err := c.readJournal(ctx, entity, rangeName)
+	if err != nil {
+		return err
+	}
+
+	if journal == nil {
+		journal, err = c.plan(ctx, entity, rangeName, mapToStruct)
+		if err != nil || journal == nil {
+			return err
+		}
+		if err := c.writeJournal(ctx, journal); err != nil {
+			return err
+		}
+	}
+
+	switch journal.Phase {
+	case phasePlanned:
+		if err := c.merge(ctx, journal, mapToStruct); err != nil {
+			return err
+		}
+		journal.Phase = phaseWritten
+		if err := c.writeJournal(ctx, journal); err != nil {
+			return err
+		}
+		fallthrough
+	case phaseWritten:
+		if err := c.removeSources(ctx, journal); err != nil {
+			return err
+		}
+		journal.Phase = phaseDone
+		if err := c.writeJournal(ctx, journal); err != nil {
+			return err
+		}
+		c.logger.Infof("compacted %d files of %s/%s into %d (%d rows)", len(journal.Sources), entity, rangeName, len(journal.Outputs), journal.RowCount)
+		return nil
+	case phaseDone:
+		return c.checkDone(ctx, journal)
+	default:
+		return errors.Errorf("unknown phase %q in journal for %s/%s", journal.Phase, entity, rangeName)
+	}
+}
+
+// plan lists a range's files and decides how they are grouped into outputs; it returns nil if there is nothing to do
+func (c *Compactor) plan(ctx context.Context, entity string, rangeName string, mapToStruct interface{}) (*Journal, error) {
+	sources, err := c.listSources(ctx, entity, rangeName, mapToStruct)
+	if err != nil || len(sources) == 0 {
+		return nil, err
+	}
+
+	journal := &Journal{Entity: entity, Range: rangeName, Phase: phasePlanned}
+	var chunk []sourceFile
+	var chunkRows int64
+	closeChunk := func() {
+		if len(chunk) == 0 {
+			return
+		}
+		for _, source := range chunk {
+			journal.Sources = append(journal.Sources, source.name)
+		}
+		journal.Outputs = append(journal.Outputs, outputName(entity, rangeName, chunk))
+		journal.RowCount += chunkRows
+		chunk, chunkRows = nil, 0
+	}
+	for _, source := range sources {
+		if c.cfg.TargetRows > 0 && len(chunk) > 0 && chunkRows+source.rows > c.cfg.TargetRows {
+			closeChunk()
+		}
+		chunk = append(chunk, source)
+		chunkRows += source.rows
+	}
+	closeChunk()
+
+	//	A range whose every output is one of its existing files is already compact
+	if len(journal.Outputs) == len(journal.Sources) {
+		return nil, nil
+	}
+	return journal, nil
+}
+
+// listSources returns the data files of a range in height order, along with their row counts; it refuses a range
+// holding trace files that have not been migrated
+func (c *Compactor) listSources(ctx context.Context, entity string, rangeName string, mapToStruct interface{}) ([]sourceFile, error) {
+	files, err := c.store.List(ctx, fmt.Sprintf("%s/%s/", entity, rangeName))
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []sourceFile
+	for _, file := range files {
+		start, end, err := util.ParseFileHeights(file.Name)
+		if err != nil {
+			c.logger.Warnf("ignoring unexpected file %s: %v", file.Name, err)
+			continue
+		}
+		if c.cfg.MinAge > 0 && time.Since(file.Updated) < c.cfg.MinAge {
+			c.logger.Infof("skipping %s/%s: %s was modified %s ago", entity, rangeName, file.Name, time.Since(file.Updated).Round(time.Second))
+			return nil, nil
+		}
+
+		missing, err := storage.MissingFields(ctx, c.store, file.Name, mapToStruct)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read schema of %s", file.Name)
+		}
+		if trace.Unmigrated(missing) {
+			return nil, errors.Wrap(trace.ErrUnmigrated, file.Name)
+		}
+
+		rows, err := storage.CountRows(ctx, c.store, file.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot count rows of %s", file.Name)
+		}
+		sources = append(sources, sourceFile{name: file.Name, start: start, end: end, rows: rows})
+	}
+
+	sort.Slice(sources, func(i, j int) bool { return sources[i].start < sources[j].start })
+	for i := 1; i < len(sources); i++ {
+		if sources[i].start <= sources[i-1].end {
+			return nil, errors.Errorf("files %s and %s cover overlapping heights", sources[i-1].name, sources[i].name)
+		}
+	}
+	return sources, nil
+}
+
+// merge writes each output from its sources, then checks that the outputs hold exactly the planned number of rows
+func (c *Compactor) merge(ctx context.Context, journal *Journal, mapToStruct interface{}) error {
+	chunks, err := chunkSources(journal)
+	if err != nil {
+		return err
+	}
+
+	for i, output := range journal.Outputs {
+		if len(chunks[i]) == 1 && chunks[i][0] == output {
+			continue
+		}
+
+		heights := make(map[uint64][]interface{})
+		for _, source := range chunks[i] {
+			sourceHeights, err := c.readSource(ctx, source, mapToStruct)
+			if err != nil {
+				return errors.Wrapf(err, "cannot read %s", source)
+			}
+			for height, rows := range sourceHeights {
+				heights[height] = rows
+			}
+		}
+
+		manifest, err := storage.WriteBatch(ctx, c.store, journal.Entity, heights, mapToStruct, c.cfg.DirectoryRange)
+		if err != nil {
+			return err
+		}
+		if manifest.Filename != output {
+			return errors.Errorf("output %s was written as %s", output, manifest.Filename)
+		}
+	}
+
+	var written int64
+	for _, output := range journal.Outputs {
+		rows, err := storage.CountRows(ctx, c.store, output)
+		if err != nil {
+			return errors.Wrapf(err, "cannot verify %s", output)
+		}
+		written += rows
+	}
+	if written != journal.RowCount {
+		return errors.Errorf("row count mismatch for %s/%s: sources hold %d rows, outputs hold %d", journal.Entity, journal.Range, journal.RowCount, written)
+	}
+
+	return nil
+}
+
+// readSource reads the rows of a source file by height: a file of a single height holds only that height, and a
+// batched file is divided between heights by its manifest; columns a file predates are left empty
+func (c *Compactor) readSource(ctx context.Context, source string, mapToStruct interface{}) (map[uint64][]interface{}, error) {
+	manifest, err := storage.ReadManifest(ctx, c.store, source)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil {
+		return storage.ReadBatch(ctx, c.store, manifest, mapToStruct)
+	}
+
+	if strings.Contains(path.Base(source), "-") {
+		return nil, errors.Errorf("batched file %s has no manifest", source)
+	}
+	height, _, err := util.ParseFileHeights(source)
+	if err != nil {
+		return nil, err
+	}
+	rows, _, err := storage.ReadCompatible(ctx, c.store, source, mapToStruct)
+	if err != nil {
+		return nil, err
+	}
+	return map[uint64][]interface{}{height: rows}, nil
+}
+
+// removeSources archives and deletes every source that is not itself an output, along with any batch manifest
+// describing it
+func (c *Compactor) removeSources(ctx context.Context, journal *Journal) error {
+	outputs := make(map[string]bool, len(journal.Outputs))
+	for _, output := range journal.Outputs {
+		outputs[output] = true
+	}
+
+	for _, source := range journal.Sources {
+		if outputs[source] {
+			continue
+		}
+
+		for _, name := range []string{source, storage.ManifestFilename(source)} {
+			exists, err := c.store.Exists(ctx, name)
+			if err != nil {
+				return err
+			}
+			//	Already removed by an earlier, interrupted run
+			if !exists {
+				continue
+			}
+
+			if c.cfg.ArchivePrefix != "" {
+				if err := c.store.Copy(ctx, name, fmt.Sprintf("%s/%s", c.cfg.ArchivePrefix, name)); err != nil {
+					return err
+				}
+			}
+			if err := c.store.Delete(ctx, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkDone confirms that nothing has been written to an already-compacted range since
+func (c *Compactor) checkDone(ctx context.Context, journal *Journal) error {
+	files, err := c.store.List(ctx, fmt.Sprintf("%s/%s/", journal.Entity, journal.Range))
+	if err != nil {
+		return err
+	}
+
+	outputs := make(map[string]bool, len(journal.Outputs))
+	for _, output := range journal.Outputs {
+		outputs[output] = true
+	}
+	var newer []string
+	for _, file := range files {
+		if !outputs[file.Name] {
+			newer = append(newer, file.Name)
+		}
+	}
+	if len(newer) > 0 {
+		return errors.Wrapf(ErrNewerFiles, "%v", newer)
+	}
+
+	return nil
+}
+
+// chunkSources regroups a journal's sources by the output they belong to; sources are recorded in height order, so
+// each output's sources are those falling within its span
+func chunkSources(journal *Journal) ([][]string, error) {
+	chunks := make([][]string, len(journal.Outputs))
+	i := 0
+	for _, source := range journal.Sources {
+		start, _, err := util.ParseFileHeights(source)
+		if err != nil {
+			return nil, err
+		}
+		for i < len(journal.Outputs) {
+			_, outputEnd, err := util.ParseFileHeights(journal.Outputs[i])
+			if err != nil {
+				return nil, err
+			}
+			if start <= outputEnd {
+				break
+			}
+			i++
+		}
+		if i == len(journal.Outputs) {
+			return nil, errors.Errorf("source %s is not covered by any output", source)
+		}
+		chunks[i] = append(chunks[i], source)
+	}
+
+	return chunks, nil
+}
+
+// outputName names the file holding a group of consecutive sources
+func outputName(entity string, rangeName string, chunk []sourceFile) string {
+	if len(chunk) == 1 {
+		return chunk[0].name
+	}
+	return fmt.Sprintf("%s/%s/%d-%d.parquet", entity, rangeName, chunk[0].start, chunk[len(chunk)-1].end)
+}
+
+func journalFilename(entity string, rangeName string) string {
+	return fmt.Sprintf("%s/%s/%s.parquet", journalPrefix, entity, rangeName)
+}
+
+// readJournal loads the journal for a range, or nil if the range has never been compacted
+func (c *Compactor) readJournal(ctx context.Context, entity string, rangeName string) (*Journal, error) {
+	filename := journalFilename(entity, rangeName)
+	exists, err := c.store.Exists(ctx, filename)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var journals []Journal
+	if err := c.store.ReadMany(ctx, filename, new(Journal), &journals); err != nil {
+		return nil, errors.Wrapf(err, "cannot read journal %s", filename)
+	}
+	if len(journals) != 1 {
+		return nil, errors.Errorf("journal %s holds %d rows", filename, len(journals))
+	}
+	return &journals[0], nil
+}
+
+func (c *Compactor) writeJournal(ctx context.Context, journal *Journal) error {
+	filename := journalFilename(journal.Entity, journal.Range)
+	if err := c.store.WriteOne(ctx, journal, new(Journal), filename); err != nil {
+		return errors.Wrapf(err, "cannot write journal %s", filename)
+	}
+	return nil
+}
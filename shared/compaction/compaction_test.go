@@ -0,0 +1,299 @@
+package compaction
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/coherentopensource/evm-etl/shared/trace"
+	"github.com/pkg/errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	testDirectoryRange = 10
+	testEntity         = "rows"
+	testRange          = "blocks_0-9"
+	testOutput         = "rows/blocks_0-9/0-4.parquet"
+)
+
+// testLogger discards everything logged
+type testLogger struct{}
+
+func (testLogger) Error(...interface{})                 {}
+func (testLogger) Info(...interface{})                  {}
+func (testLogger) Fatal(...interface{})                 { panic("fatal") }
+func (testLogger) Panic(...interface{})                 { panic("panic") }
+func (testLogger) Warn(...interface{})                  {}
+func (testLogger) Errorf(string, ...interface{})        {}
+func (testLogger) Infof(string, ...interface{})         {}
+func (testLogger) Fatalf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Panicf(f string, args ...interface{}) { panic(fmt.Sprintf(f, args...)) }
+func (testLogger) Warnf(string, ...interface{})         {}
+
+type testRow struct {
+	Height int64  `parquet:"name=height, type=INT64"`
+	Value  string `parquet:"name=value, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// legacyRow is testRow as written before its value column was added
+type legacyRow struct {
+	Height int64 `parquet:"name=height, type=INT64"`
+}
+
+type testTrace struct {
+	Hash         string  `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	TraceAddress []int64 `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+}
+
+// legacyTrace is testTrace as written before trace_address
+type legacyTrace struct {
+	Hash string `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+func sourceName(height uint64) string {
+	return fmt.Sprintf("%s/%s/%d.parquet", testEntity, testRange, height)
+}
+
+// writeSources writes a file of one row for each of heights 0-4, as in block mode
+func writeSources(ctx context.Context, store storage.Store) error {
+	for height := uint64(0); height < 5; height++ {
+		row := &testRow{Height: int64(height), Value: fmt.Sprint(height)}
+		if err := store.WriteOne(ctx, row, new(testRow), sourceName(height)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readOutput reads the rows of the compacted file back by height
+func readOutput(ctx context.Context, store storage.Store) (map[uint64][]interface{}, error) {
+	manifest, err := storage.ReadManifest(ctx, store, testOutput)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, errors.Errorf("%s has no manifest", testOutput)
+	}
+	return storage.ReadBatch(ctx, store, manifest, new(testRow))
+}
+
+func wantOutput() map[uint64][]interface{} {
+	want := make(map[uint64][]interface{})
+	for height := uint64(0); height < 5; height++ {
+		want[height] = []interface{}{&testRow{Height: int64(height), Value: fmt.Sprint(height)}}
+	}
+	return want
+}
+
+func TestCompactRangeResumes(t *testing.T) {
+	tests := []struct {
+		name string
+		// interrupt leaves the range as a run interrupted at some phase would
+		interrupt func(ctx context.Context, c *Compactor) error
+	}{
+		{
+			name:      "not started",
+			interrupt: func(context.Context, *Compactor) error { return nil },
+		},
+		{
+			name: "planned",
+			interrupt: func(ctx context.Context, c *Compactor) error {
+				journal, err := c.plan(ctx, testEntity, testRange, new(testRow))
+				if err != nil {
+					return err
+				}
+				return c.writeJournal(ctx, journal)
+			},
+		},
+		{
+			//	The output was written, but the run stopped before the journal recorded it
+			name: "planned with the output written",
+			interrupt: func(ctx context.Context, c *Compactor) error {
+				journal, err := c.plan(ctx, testEntity, testRange, new(testRow))
+				if err != nil {
+					return err
+				}
+				if err := c.writeJournal(ctx, journal); err != nil {
+					return err
+				}
+				return c.merge(ctx, journal, new(testRow))
+			},
+		},
+		{
+			//	Some sources were deleted before the run stopped
+			name: "written",
+			interrupt: func(ctx context.Context, c *Compactor) error {
+				journal, err := c.plan(ctx, testEntity, testRange, new(testRow))
+				if err != nil {
+					return err
+				}
+				if err := c.merge(ctx, journal, new(testRow)); err != nil {
+					return err
+				}
+				journal.Phase = phaseWritten
+				if err := c.writeJournal(ctx, journal); err != nil {
+					return err
+				}
+				return c.store.Delete(ctx, sourceName(0))
+			},
+		},
+		{
+			name: "done",
+			interrupt: func(ctx context.Context, c *Compactor) error {
+				return c.CompactRange(ctx, testEntity, testRange, new(testRow))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := storage.NewMemoryConnector(testDirectoryRange)
+			if err := writeSources(ctx, store); err != nil {
+				t.Fatal(err)
+			}
+			c := New(store, Config{DirectoryRange: testDirectoryRange, Before: testDirectoryRange}, testLogger{})
+			if err := tt.interrupt(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.CompactRange(ctx, testEntity, testRange, new(testRow)); err != nil {
+				t.Fatal(err)
+			}
+			want := []string{testOutput}
+			if got := store.FilesWithPrefix(testEntity + "/"); !reflect.DeepEqual(got, want) {
+				t.Fatalf("files = %v, want %v", got, want)
+			}
+			got, err := readOutput(ctx, store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, wantOutput()) {
+				t.Errorf("rows = %+v, want %+v", got, wantOutput())
+			}
+
+			journal, err := c.readJournal(ctx, testEntity, testRange)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if journal.Phase != phaseDone || journal.RowCount != 5 {
+				t.Errorf("journal = %+v, want phase %s with 5 rows", journal, phaseDone)
+			}
+		})
+	}
+}
+
+func TestCompactRangeKeepsSourcesUntilVerified(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryConnector(testDirectoryRange)
+	if err := writeSources(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	c := New(store, Config{DirectoryRange: testDirectoryRange, Before: testDirectoryRange}, testLogger{})
+	journal, err := c.plan(ctx, testEntity, testRange, new(testRow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.writeJournal(ctx, journal); err != nil {
+		t.Fatal(err)
+	}
+
+	//	A source gains a row after planning, so the output holds more rows than the journal expects
+	rows := []interface{}{&testRow{Height: 2, Value: "2"}, &testRow{Height: 2, Value: "2"}}
+	if err := store.WriteMany(ctx, rows, new(testRow), sourceName(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.CompactRange(ctx, testEntity, testRange, new(testRow)); err == nil {
+		t.Fatal("compacted despite a row count mismatch")
+	}
+	for height := uint64(0); height < 5; height++ {
+		if exists, err := store.Exists(ctx, sourceName(height)); err != nil || !exists {
+			t.Errorf("source %d exists = %v, %v", height, exists, err)
+		}
+	}
+	journal, err = c.readJournal(ctx, testEntity, testRange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if journal.Phase != phasePlanned {
+		t.Errorf("phase = %s, want %s", journal.Phase, phasePlanned)
+	}
+}
+
+func TestCompactRangeNewerFiles(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryConnector(testDirectoryRange)
+	if err := writeSources(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	c := New(store, Config{DirectoryRange: testDirectoryRange, Before: testDirectoryRange}, testLogger{})
+	if err := c.CompactRange(ctx, testEntity, testRange, new(testRow)); err != nil {
+		t.Fatal(err)
+	}
+
+	//	A live writer re-ingests a height of the compacted range
+	if err := store.WriteOne(ctx, &testRow{Height: 3, Value: "3"}, new(testRow), sourceName(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.CompactRange(ctx, testEntity, testRange, new(testRow)); !errors.Is(err, ErrNewerFiles) {
+		t.Errorf("compact range = %v, want %v", err, ErrNewerFiles)
+	}
+	//	Compact skips the range rather than failing
+	if err := c.Compact(ctx, testEntity, new(testRow), 0); err != nil {
+		t.Errorf("compact = %v", err)
+	}
+	if exists, err := store.Exists(ctx, sourceName(3)); err != nil || !exists {
+		t.Errorf("newer file exists = %v, %v", exists, err)
+	}
+}
+
+func TestCompactRangeLegacySources(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing columns are left empty", func(t *testing.T) {
+		store := storage.NewMemoryConnector(testDirectoryRange)
+		for height := uint64(0); height < 5; height++ {
+			if err := store.WriteOne(ctx, &legacyRow{Height: int64(height)}, new(legacyRow), sourceName(height)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c := New(store, Config{DirectoryRange: testDirectoryRange, Before: testDirectoryRange}, testLogger{})
+		if err := c.CompactRange(ctx, testEntity, testRange, new(testRow)); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := readOutput(ctx, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for height := uint64(0); height < 5; height++ {
+			want := []interface{}{&testRow{Height: int64(height)}}
+			if !reflect.DeepEqual(got[height], want) {
+				t.Errorf("rows of %d = %+v, want %+v", height, got[height], want)
+			}
+		}
+	})
+
+	t.Run("unmigrated traces are refused", func(t *testing.T) {
+		store := storage.NewMemoryConnector(testDirectoryRange)
+		for height := uint64(0); height < 2; height++ {
+			filename := fmt.Sprintf("traces/%s/%d.parquet", testRange, height)
+			if err := store.WriteOne(ctx, &legacyTrace{Hash: fmt.Sprint(height)}, new(legacyTrace), filename); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c := New(store, Config{DirectoryRange: testDirectoryRange, Before: testDirectoryRange}, testLogger{})
+		if err := c.CompactRange(ctx, "traces", testRange, new(testTrace)); !errors.Is(err, trace.ErrUnmigrated) {
+			t.Fatalf("compact range = %v, want %v", err, trace.ErrUnmigrated)
+		}
+		if got := store.FilesWithPrefix("traces/"); len(got) != 2 {
+			t.Errorf("files = %v, want the two sources", got)
+		}
+		if got := store.FilesWithPrefix(journalPrefix + "/"); len(got) != 0 {
+			t.Errorf("journals = %v, want none", got)
+		}
+	})
+}
@@ -0,0 +1,257 @@
+package decoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decoded is a log or call decoded against an ABI, with JSON arguments and outputs, or Error if its data did not decode
+type Decoded struct {
+	Name      string
+	Signature string
+	Args      string
+	Outputs   string
+	Error     string
+}
+
+// DecodeLog decodes a log against the ABIs, reporting whether any ABI has an event for its first topic at all
+func (r *Registry) DecodeLog(address string, topics []string, data string) (*Decoded, bool) {
+	if len(topics) == 0 {
+		return nil, false
+	}
+	candidates := r.eventCandidates(common.HexToAddress(address), common.HexToHash(topics[0]))
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	//	Prefer the event whose indexed arguments account for the log's topics
+	event := candidates[0]
+	for _, candidate := range candidates {
+		if countIndexed(candidate.Inputs) == len(topics)-1 {
+			event = candidate
+			break
+		}
+	}
+
+	out := &Decoded{Name: event.RawName, Signature: event.Sig}
+	args, err := decodeEvent(event, topics[1:], data)
+	if err != nil {
+		out.Error = err.Error()
+		return out, true
+	}
+	out.Args = args
+	return out, true
+}
+
+// DecodeCall decodes a call's input and any output against the ABIs, reporting whether any ABI has its selector at all
+func (r *Registry) DecodeCall(address string, input string, output string) (*Decoded, bool) {
+	data, err := hexutil.Decode(input)
+	if err != nil || len(data) < 4 {
+		return nil, false
+	}
+	var selector [4]byte
+	copy(selector[:], data)
+	candidates := r.methodCandidates(common.HexToAddress(address), selector)
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	//	Selectors can collide, so take the first function the input decodes as
+	var out *Decoded
+	for _, method := range candidates {
+		decoded := decodeCall(method, data[4:], output)
+		if decoded.Error == "" {
+			return decoded, true
+		}
+		if out == nil {
+			out = decoded
+		}
+	}
+	return out, true
+}
+
+// decodeEvent decodes an event's arguments from a log's topics and data, giving hashed indexed arguments as their hash
+func decodeEvent(event abi.Event, topics []string, data string) (string, error) {
+	if indexed := countIndexed(event.Inputs); indexed != len(topics) {
+		return "", errors.Errorf("%s indexes %d arguments, but the log has %d topics after the signature", event.Sig, indexed, len(topics))
+	}
+	b, err := decodeData(data)
+	if err != nil {
+		return "", err
+	}
+	values, err := event.Inputs.Unpack(b)
+	if err != nil {
+		return "", err
+	}
+
+	args := make(map[string]interface{}, len(event.Inputs))
+	var topicIndex, valueIndex int
+	for i, input := range event.Inputs {
+		name := argName(input, i)
+		if !input.Indexed {
+			args[name] = jsonValue(values[valueIndex])
+			valueIndex++
+			continue
+		}
+
+		topic := topics[topicIndex]
+		topicIndex++
+		if hashedWhenIndexed(input.Type) {
+			args[name] = strings.ToLower(topic)
+			continue
+		}
+		word, err := hexutil.Decode(topic)
+		if err != nil || len(word) != common.HashLength {
+			return "", errors.Errorf("invalid topic %s for argument %s", topic, name)
+		}
+		value, err := abi.Arguments{{Type: input.Type}}.Unpack(word)
+		if err != nil {
+			return "", errors.Wrapf(err, "cannot decode argument %s", name)
+		}
+		args[name] = jsonValue(value[0])
+	}
+
+	return marshal(args)
+}
+
+// decodeCall decodes a call's arguments, and its return values if it has output
+func decodeCall(method abi.Method, input []byte, output string) *Decoded {
+	out := &Decoded{Name: method.RawName, Signature: method.Sig}
+
+	args, err := decodeArguments(method.Inputs, input)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	out.Args = args
+
+	if output != "" && output != "0x" && len(method.Outputs) > 0 {
+		outputData, err := decodeData(output)
+		if err == nil {
+			out.Outputs, err = decodeArguments(method.Outputs, outputData)
+		}
+		if err != nil {
+			out.Error = errors.Wrap(err, "cannot decode output").Error()
+		}
+	}
+	return out
+}
+
+// decodeArguments decodes ABI-encoded values into a JSON object of the arguments they belong to
+func decodeArguments(arguments abi.Arguments, data []byte) (string, error) {
+	values, err := arguments.Unpack(data)
+	if err != nil {
+		return "", err
+	}
+	args := make(map[string]interface{}, len(arguments))
+	for i, argument := range arguments {
+		args[argName(argument, i)] = jsonValue(values[i])
+	}
+	return marshal(args)
+}
+
+// jsonValue converts a decoded ABI value to lossless JSON: integers as decimal strings, bytes as hex, tuples as objects
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return strings.ToLower(v.Hex())
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case string, bool:
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return jsonValue(rv.Elem().Interface())
+	case reflect.Array, reflect.Slice:
+		//	Fixed-size bytes, e.g. bytes32, decode to byte arrays
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			for i := range b {
+				b[i] = byte(rv.Index(i).Uint())
+			}
+			return hexutil.Encode(b)
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = jsonValue(rv.Index(i).Interface())
+		}
+		return list
+	case reflect.Struct:
+		object := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			name := field.Tag.Get("json")
+			if name == "" {
+				name = field.Name
+			}
+			object[name] = jsonValue(rv.Field(i).Interface())
+		}
+		return object
+	}
+	return fmt.Sprint(value)
+}
+
+// hashedWhenIndexed reports whether an indexed argument is stored in its topic as a hash, rather than its encoding
+func hashedWhenIndexed(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// argName names an argument, falling back to its position for the unnamed
+func argName(argument abi.Argument, i int) string {
+	if argument.Name != "" {
+		return argument.Name
+	}
+	return fmt.Sprintf("arg%d", i)
+}
+
+func countIndexed(arguments abi.Arguments) int {
+	var n int
+	for _, argument := range arguments {
+		if argument.Indexed {
+			n++
+		}
+	}
+	return n
+}
+
+// decodeData decodes hex data, which nodes give as "0x", or occasionally as an empty string, when there is none
+func decodeData(data string) ([]byte, error) {
+	if data == "" {
+		return nil, nil
+	}
+	return hexutil.Decode(data)
+}
+
+func marshal(args map[string]interface{}) (string, error) {
+	out, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
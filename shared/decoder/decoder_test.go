@@ -0,0 +1,296 @@
+package decoder
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/crypto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testFrom = "0x2222222222222222222222222222222222222222"
+	testTo   = "0x3333333333333333333333333333333333333333"
+	// testWETH has its own ABI, which names the arguments of Transfer as WETH does
+	testWETH = "0x00000000000000000000000000000000000000aa"
+	// testToken has no ABI of its own, so is decoded by signature
+	testToken = "0x00000000000000000000000000000000000000bb"
+)
+
+// testABIs are written to a directory the registry loads; flag714064(bool) and amount3510(uint256) share the selector
+// 0x4aa858e3
+var testABIs = map[string]string{
+	"erc20.json": `[
+		{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+	]`,
+	"erc721.json": `{"contractName":"ERC721","abi":[
+		{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]}
+	]}`,
+	"registry.json": `[
+		{"type":"event","name":"Registered","inputs":[{"name":"name","type":"string","indexed":true},{"name":"owner","type":"address","indexed":false}]}
+	]`,
+	"collision_a.json": `[{"type":"function","name":"flag714064","inputs":[{"name":"on","type":"bool"}],"outputs":[]}]`,
+	"collision_b.json": `[{"type":"function","name":"amount3510","inputs":[{"name":"amount","type":"uint256"}],"outputs":[]}]`,
+	testWETH + ".json": `[
+		{"type":"event","name":"Transfer","inputs":[{"name":"src","type":"address","indexed":true},{"name":"dst","type":"address","indexed":true},{"name":"wad","type":"uint256","indexed":false}]}
+	]`,
+}
+
+func loadTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range testABIs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry, err := LoadRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+// word ABI-encodes a hex quantity, given without its 0x prefix, as a single word
+func word(quantity string) string {
+	return fmt.Sprintf("%064s", quantity)
+}
+
+// addressTopic pads an address to a word, as indexed address arguments are
+func addressTopic(address string) string {
+	return "0x" + word(address[2:])
+}
+
+func signatureTopic(signature string) string {
+	return crypto.Keccak256Hash([]byte(signature)).Hex()
+}
+
+func TestDecodeLog(t *testing.T) {
+	registry := loadTestRegistry(t)
+	transfer := signatureTopic("Transfer(address,address,uint256)")
+	aliceHash := crypto.Keccak256Hash([]byte("alice")).Hex()
+
+	tests := []struct {
+		name      string
+		address   string
+		topics    []string
+		data      string
+		wantFound bool
+		want      Decoded
+	}{
+		{
+			//	ERC-20 indexes the sender and recipient, leaving the value in data
+			name:      "erc20 transfer",
+			address:   testToken,
+			topics:    []string{transfer, addressTopic(testFrom), addressTopic(testTo)},
+			data:      "0x" + word("2a"),
+			wantFound: true,
+			want: Decoded{
+				Name:      "Transfer",
+				Signature: "Transfer(address,address,uint256)",
+				Args:      `{"from":"` + testFrom + `","to":"` + testTo + `","value":"42"}`,
+			},
+		},
+		{
+			//	ERC-721 indexes the token ID as well, so its logs carry a topic more
+			name:      "erc721 transfer",
+			address:   testToken,
+			topics:    []string{transfer, addressTopic(testFrom), addressTopic(testTo), "0x" + word("7")},
+			data:      "0x",
+			wantFound: true,
+			want: Decoded{
+				Name:      "Transfer",
+				Signature: "Transfer(address,address,uint256)",
+				Args:      `{"from":"` + testFrom + `","to":"` + testTo + `","tokenId":"7"}`,
+			},
+		},
+		{
+			name:      "contract's own abi",
+			address:   testWETH,
+			topics:    []string{transfer, addressTopic(testFrom), addressTopic(testTo)},
+			data:      "0x" + word("2a"),
+			wantFound: true,
+			want: Decoded{
+				Name:      "Transfer",
+				Signature: "Transfer(address,address,uint256)",
+				Args:      `{"dst":"` + testTo + `","src":"` + testFrom + `","wad":"42"}`,
+			},
+		},
+		{
+			//	A contract's ABI without the event falls back to the events known by signature
+			name:      "contract's own abi lacking the event",
+			address:   testWETH,
+			topics:    []string{signatureTopic("Registered(string,address)"), aliceHash},
+			data:      "0x" + word(testTo[2:]),
+			wantFound: true,
+			want: Decoded{
+				Name:      "Registered",
+				Signature: "Registered(string,address)",
+				Args:      `{"name":"` + aliceHash + `","owner":"` + testTo + `"}`,
+			},
+		},
+		{
+			//	An indexed string is only in its topic as the hash of its value, which is given in lower case
+			name:      "indexed string",
+			address:   testToken,
+			topics:    []string{signatureTopic("Registered(string,address)"), "0x" + strings.ToUpper(aliceHash[2:])},
+			data:      "0x" + word(testTo[2:]),
+			wantFound: true,
+			want: Decoded{
+				Name:      "Registered",
+				Signature: "Registered(string,address)",
+				Args:      `{"name":"` + aliceHash + `","owner":"` + testTo + `"}`,
+			},
+		},
+		{
+			//	Data too short for the value is reported rather than failing
+			name:      "malformed data",
+			address:   testToken,
+			topics:    []string{transfer, addressTopic(testFrom), addressTopic(testTo)},
+			data:      "0x2a",
+			wantFound: true,
+			want: Decoded{
+				Name:      "Transfer",
+				Signature: "Transfer(address,address,uint256)",
+				Error:     "abi: cannot marshal in to go type: length insufficient 1 require 32",
+			},
+		},
+		{
+			name:      "topics matching no layout",
+			address:   testToken,
+			topics:    []string{transfer, addressTopic(testFrom)},
+			data:      "0x" + word("2a"),
+			wantFound: true,
+			want: Decoded{
+				Name:      "Transfer",
+				Signature: "Transfer(address,address,uint256)",
+				Error:     "Transfer(address,address,uint256) indexes 2 arguments, but the log has 1 topics after the signature",
+			},
+		},
+		{
+			name:    "unknown event",
+			address: testToken,
+			topics:  []string{signatureTopic("Approval(address,address,uint256)")},
+		},
+		{
+			name:    "anonymous log",
+			address: testToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := registry.DecodeLog(tt.address, tt.topics, tt.data)
+			if found != tt.wantFound {
+				t.Fatalf("found = %v, want %v", found, tt.wantFound)
+			}
+			if !found {
+				return
+			}
+			if *got != tt.want {
+				t.Errorf("decoded = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCall(t *testing.T) {
+	registry := loadTestRegistry(t)
+
+	tests := []struct {
+		name      string
+		address   string
+		input     string
+		output    string
+		wantFound bool
+		want      Decoded
+	}{
+		{
+			name:      "call with output",
+			address:   testToken,
+			input:     "0xa9059cbb" + word(testTo[2:]) + word("2a"),
+			output:    "0x" + word("1"),
+			wantFound: true,
+			want: Decoded{
+				Name:      "transfer",
+				Signature: "transfer(address,uint256)",
+				Args:      `{"to":"` + testTo + `","value":"42"}`,
+				Outputs:   `{"arg0":true}`,
+			},
+		},
+		{
+			//	A failed call has no output to decode
+			name:      "failed call",
+			address:   testToken,
+			input:     "0xa9059cbb" + word(testTo[2:]) + word("2a"),
+			wantFound: true,
+			want: Decoded{
+				Name:      "transfer",
+				Signature: "transfer(address,uint256)",
+				Args:      `{"to":"` + testTo + `","value":"42"}`,
+			},
+		},
+		{
+			//	Both functions of a colliding selector decode the input, so the one loaded first is taken
+			name:      "selector collision decoding as both",
+			address:   testToken,
+			input:     "0x4aa858e3" + word("1"),
+			wantFound: true,
+			want: Decoded{
+				Name:      "flag714064",
+				Signature: "flag714064(bool)",
+				Args:      `{"on":true}`,
+			},
+		},
+		{
+			name:      "selector collision decoding as one",
+			address:   testToken,
+			input:     "0x4aa858e3" + word("2"),
+			wantFound: true,
+			want: Decoded{
+				Name:      "amount3510",
+				Signature: "amount3510(uint256)",
+				Args:      `{"amount":"2"}`,
+			},
+		},
+		{
+			//	Input decoding as neither reports the first function's error
+			name:      "selector collision decoding as neither",
+			address:   testToken,
+			input:     "0x4aa858e3" + "2a",
+			wantFound: true,
+			want: Decoded{
+				Name:      "flag714064",
+				Signature: "flag714064(bool)",
+				Error:     "abi: cannot marshal in to go type: length insufficient 1 require 32",
+			},
+		},
+		{
+			name:    "unknown selector",
+			address: testToken,
+			input:   "0xdeadbeef",
+		},
+		{
+			name:    "input shorter than a selector",
+			address: testToken,
+			input:   "0xdead",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := registry.DecodeCall(tt.address, tt.input, tt.output)
+			if found != tt.wantFound {
+				t.Fatalf("found = %v, want %v", found, tt.wantFound)
+			}
+			if !found {
+				return
+			}
+			if *got != tt.want {
+				t.Errorf("decoded = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Package decoder decodes logs and call inputs against user-supplied contract ABIs into names and JSON arguments.
+package decoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Registry holds the events and functions of a directory of ABIs, by contract address or by signature
+type Registry struct {
+	contracts map[common.Address]*abi.ABI
+	events    map[common.Hash][]abi.Event
+	methods   map[[4]byte][]abi.Method
+}
+
+// LoadRegistry reads every ABI or artifact .json in a directory; one named after an address decodes only its contract
+func LoadRegistry(dir string) (*Registry, error) {
+	r := &Registry{
+		contracts: make(map[common.Address]*abi.ABI),
+		events:    make(map[common.Hash][]abi.Event),
+		methods:   make(map[[4]byte][]abi.Method),
+	}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		contract, err := readABI(path)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read ABI %s", path)
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		if strings.HasPrefix(name, "0x") && common.IsHexAddress(name) {
+			r.contracts[common.HexToAddress(name)] = contract
+			return nil
+		}
+		//	Events and functions are registered in name order, so that colliding candidates are tried in the same order
+		for _, name := range sortedKeys(contract.Events) {
+			r.addEvent(contract.Events[name])
+		}
+		for _, name := range sortedKeys(contract.Methods) {
+			r.addMethod(contract.Methods[name])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// readABI parses a file as a bare ABI array, or failing that as an artifact carrying one
+func readABI(path string) (*abi.ABI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal(data, &artifact); err != nil {
+			return nil, err
+		}
+		if len(artifact.ABI) == 0 {
+			return nil, errors.New("no abi field")
+		}
+		data = artifact.ABI
+	}
+
+	var contract abi.ABI
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// addEvent registers an event by signature, once per layout of indexed arguments, as ERC-20 and ERC-721 Transfer differ
+func (r *Registry) addEvent(event abi.Event) {
+	if event.Anonymous {
+		return
+	}
+	for _, known := range r.events[event.ID] {
+		if indexedLayout(known) == indexedLayout(event) {
+			return
+		}
+	}
+	r.events[event.ID] = append(r.events[event.ID], event)
+}
+
+// addMethod registers a function by selector, once per signature, as distinct signatures may share a selector
+func (r *Registry) addMethod(method abi.Method) {
+	var selector [4]byte
+	copy(selector[:], method.ID)
+	for _, known := range r.methods[selector] {
+		if known.Sig == method.Sig {
+			return
+		}
+	}
+	r.methods[selector] = append(r.methods[selector], method)
+}
+
+// eventCandidates returns the events a log's first topic may stand for: the contract's own, if its ABI is known and
+// has the event, otherwise those registered by signature
+func (r *Registry) eventCandidates(address common.Address, id common.Hash) []abi.Event {
+	if contract, ok := r.contracts[address]; ok {
+		if event, err := contract.EventByID(id); err == nil {
+			return []abi.Event{*event}
+		}
+	}
+	return r.events[id]
+}
+
+// methodCandidates returns the functions a call's selector may stand for, as eventCandidates does for events
+func (r *Registry) methodCandidates(address common.Address, selector [4]byte) []abi.Method {
+	if contract, ok := r.contracts[address]; ok {
+		if method, err := contract.MethodById(selector[:]); err == nil {
+			return []abi.Method{*method}
+		}
+	}
+	return r.methods[selector]
+}
+
+// indexedLayout describes which of an event's arguments are indexed, e.g. "110"
+func indexedLayout(event abi.Event) string {
+	var layout strings.Builder
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			layout.WriteByte('1')
+		} else {
+			layout.WriteByte('0')
+		}
+	}
+	return layout.String()
+}
+
+// sortedKeys returns the names of an ABI's events or functions in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
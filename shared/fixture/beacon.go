@@ -0,0 +1,62 @@
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/coherentopensource/evm-etl/shared/beacon"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+)
+
+// BeaconTransport is a beacon.Transport that replays beacon API responses previously captured by a BeaconRecorder,
+// standing in for a beacon node
+type BeaconTransport struct {
+	dir string
+}
+
+// NewBeaconTransport constructs a BeaconTransport serving fixtures from a per-chain directory
+func NewBeaconTransport(dir string) *BeaconTransport {
+	return &BeaconTransport{dir: dir}
+}
+
+// Get replays the response recorded for a path
+func (t *BeaconTransport) Get(ctx context.Context, path string) ([]byte, error) {
+	file := beaconPath(t.dir, path)
+	body, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, errors.Wrapf(ErrNotRecorded, "%s", file)
+	}
+	return body, err
+}
+
+// BeaconRecorder is a beacon.Transport that forwards every request to a beacon node and saves each successful
+// response as a fixture, in the layout served by BeaconTransport
+type BeaconRecorder struct {
+	inner beacon.Transport
+	dir   string
+}
+
+// NewBeaconRecorder constructs a BeaconRecorder writing fixtures to a per-chain directory
+func NewBeaconRecorder(inner beacon.Transport, dir string) *BeaconRecorder {
+	return &BeaconRecorder{inner: inner, dir: dir}
+}
+
+// Get forwards to the beacon node and records the response, indented so that recorded fixtures diff cleanly
+func (r *BeaconRecorder) Get(ctx context.Context, path string) ([]byte, error) {
+	body, err := r.inner.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return nil, errors.Errorf("cannot encode fixture for %s: %v", path, err)
+	}
+	file := beaconPath(r.dir, path)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return nil, err
+	}
+	return body, os.WriteFile(file, indented.Bytes(), 0o644)
+}
@@ -0,0 +1,138 @@
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Client is a node.Client that replays JSON-RPC responses previously captured by a Recorder, so that drivers can be
+// exercised end-to-end without a live node
+type Client struct {
+	dir string
+}
+
+// NewClient constructs a Client serving fixtures from a per-chain directory
+func NewClient(dir string) *Client {
+	return &Client{dir: dir}
+}
+
+// GetLatestBlockNumber returns the recorded chaintip, or the highest recorded block if no chaintip was captured
+func (c *Client) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	var number uint64
+	err := readFixture(filepath.Join(c.dir, latestFile), &number)
+	if err == nil {
+		return number, nil
+	}
+
+	entries, dirErr := os.ReadDir(filepath.Join(c.dir, blocksDir))
+	if dirErr != nil {
+		return 0, err
+	}
+	found := false
+	for _, entry := range entries {
+		height, parseErr := strconv.ParseUint(strings.TrimSuffix(entry.Name(), fixtureFileSuffix), 10, 64)
+		if parseErr != nil {
+			continue
+		}
+		if !found || height > number {
+			number = height
+			found = true
+		}
+	}
+	if !found {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+// GetBlockByNumber replays eth_getBlockByNumber
+func (c *Client) GetBlockByNumber(ctx context.Context, blockNumber uint64) (*node.BlockResponse, error) {
+	var res node.BlockResponse
+	if err := readFixture(heightPath(c.dir, blocksDir, blockNumber), &res); err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("%v", res.Error)
+	}
+
+	return &res, nil
+}
+
+// GetTracesForBlock replays debug_traceBlockByNumber
+func (c *Client) GetTracesForBlock(ctx context.Context, blockNumber uint64) (*node.TraceResponse, error) {
+	// genesis block has no traces
+	if blockNumber == 0 {
+		return nil, nil
+	}
+
+	var res node.TraceResponse
+	if err := readFixture(heightPath(c.dir, tracesDir, blockNumber), &res); err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("%v", res.Error)
+	}
+
+	return &res, nil
+}
+
+// GetBlockReceipt replays eth_getBlockReceipts
+func (c *Client) GetBlockReceipt(ctx context.Context, blockNumber uint64) (*node.BlockReceiptResponse, error) {
+	var res node.BlockReceiptResponse
+	if err := readFixture(heightPath(c.dir, blockReceiptsDir, blockNumber), &res); err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("%v", res.Error)
+	}
+
+	return &res, nil
+}
+
+// GetTransactionReceipt replays eth_getTransactionReceipt
+func (c *Client) GetTransactionReceipt(ctx context.Context, txHash string) (*node.TxReceiptResponse, error) {
+	var res node.TxReceiptResponse
+	if err := readFixture(receiptPath(c.dir, txHash), &res); err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("%v", res.Error)
+	}
+
+	return &res, nil
+}
+
+// CodeAt replays eth_getCode
+func (c *Client) CodeAt(ctx context.Context, address string, blockNumber uint64) (*node.CodeAtResponse, error) {
+	var res node.CodeAtResponse
+	if err := readFixture(codePath(c.dir, address, blockNumber), &res); err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, fmt.Errorf("%v", res.Error)
+	}
+
+	return &res, nil
+}
+
+// CallContext replays the result of any other JSON-RPC method
+func (c *Client) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	var raw json.RawMessage
+	if err := readFixture(callPath(c.dir, method, args), &raw); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, result)
+}
+
+// GetEthClient returns nil; there is no live connection behind a fixture client
+func (c *Client) GetEthClient() *ethclient.Client {
+	return nil
+}
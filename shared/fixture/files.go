@@ -0,0 +1,88 @@
+package fixture
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Fixture files are laid out beneath a per-chain directory, one file per recorded RPC response:
+//
+//	latest.json                      latest block number
+//	blocks/<height>.json             eth_getBlockByNumber
+//	block_receipts/<height>.json     eth_getBlockReceipts
+//	receipts/<tx hash>.json          eth_getTransactionReceipt
+//	traces/<height>.json             debug_traceBlockByNumber
+//	code/<address>-<height>.json     eth_getCode
+//	calls/<method>/<args>.json       result of any other method, called through CallContext
+//	beacon/<path>.json               beacon API response body, e.g. beacon/eth/v1/beacon/genesis.json
+const (
+	latestFile        = "latest.json"
+	blocksDir         = "blocks"
+	blockReceiptsDir  = "block_receipts"
+	receiptsDir       = "receipts"
+	tracesDir         = "traces"
+	codeDir           = "code"
+	callsDir          = "calls"
+	beaconDir         = "beacon"
+	fixtureFileSuffix = ".json"
+)
+
+// ErrNotRecorded is returned when no fixture has been recorded for a request
+var ErrNotRecorded = errors.New("no fixture recorded")
+
+func heightPath(dir string, subDir string, height uint64) string {
+	return filepath.Join(dir, subDir, fmt.Sprintf("%d%s", height, fixtureFileSuffix))
+}
+
+func receiptPath(dir string, txHash string) string {
+	return filepath.Join(dir, receiptsDir, strings.ToLower(txHash)+fixtureFileSuffix)
+}
+
+func codePath(dir string, address string, height uint64) string {
+	return filepath.Join(dir, codeDir, fmt.Sprintf("%s-%d%s", strings.ToLower(address), height, fixtureFileSuffix))
+}
+
+func callPath(dir string, method string, args []interface{}) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = strings.ToLower(fmt.Sprint(arg))
+	}
+	return filepath.Join(dir, callsDir, method, strings.Join(parts, "-")+fixtureFileSuffix)
+}
+
+func beaconPath(dir string, path string) string {
+	return filepath.Join(dir, beaconDir, filepath.FromSlash(strings.TrimPrefix(path, "/"))+fixtureFileSuffix)
+}
+
+// readFixture decodes a recorded response into out
+func readFixture(path string, out interface{}) error {
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return errors.Wrapf(ErrNotRecorded, "%s", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return errors.Errorf("cannot decode fixture %s: %v", path, err)
+	}
+	return nil
+}
+
+// writeFixture encodes a response as indented JSON, so that recorded fixtures diff cleanly
+func writeFixture(path string, in interface{}) error {
+	raw, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return errors.Errorf("cannot encode fixture %s: %v", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
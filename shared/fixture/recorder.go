@@ -0,0 +1,160 @@
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/coherentopensource/chain-interactor/client/node"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"path/filepath"
+)
+
+// Recorder is a node.Client that forwards every call to a real node and saves each successful response as a fixture,
+// in the layout served by Client
+type Recorder struct {
+	inner  node.Client
+	caller Caller
+	dir    string
+}
+
+// Caller makes JSON-RPC calls outside of node.Client's fixed set of methods, as *rpc.Client does
+type Caller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// RecordOptions controls which RPCs are captured by RecordBlock, mirroring how each driver fetches its data
+type RecordOptions struct {
+	// PerTxReceipts fetches receipts one transaction at a time (eth_getTransactionReceipt) rather than per block
+	PerTxReceipts bool
+	// SkipTraces disables capture of debug_traceBlockByNumber
+	SkipTraces bool
+	// BlockCalls are further methods a driver calls with the block number, e.g. zks_getRawBlockTransactions
+	BlockCalls []string
+}
+
+// NewRecorder constructs a Recorder writing fixtures to a per-chain directory
+func NewRecorder(inner node.Client, dir string) *Recorder {
+	return &Recorder{inner: inner, dir: dir}
+}
+
+// WithCaller sets the connection CallContext forwards to; a Recorder without one cannot record other methods
+func (r *Recorder) WithCaller(caller Caller) *Recorder {
+	r.caller = caller
+	return r
+}
+
+// GetLatestBlockNumber forwards to the node and records the chaintip
+func (r *Recorder) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	number, err := r.inner.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return number, writeFixture(filepath.Join(r.dir, latestFile), number)
+}
+
+// GetBlockByNumber forwards to the node and records the block
+func (r *Recorder) GetBlockByNumber(ctx context.Context, blockNumber uint64) (*node.BlockResponse, error) {
+	res, err := r.inner.GetBlockByNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return res, writeFixture(heightPath(r.dir, blocksDir, blockNumber), res)
+}
+
+// GetTracesForBlock forwards to the node and records the block's traces
+func (r *Recorder) GetTracesForBlock(ctx context.Context, blockNumber uint64) (*node.TraceResponse, error) {
+	res, err := r.inner.GetTracesForBlock(ctx, blockNumber)
+	if err != nil || res == nil {
+		return res, err
+	}
+	return res, writeFixture(heightPath(r.dir, tracesDir, blockNumber), res)
+}
+
+// GetBlockReceipt forwards to the node and records the block's receipts
+func (r *Recorder) GetBlockReceipt(ctx context.Context, blockNumber uint64) (*node.BlockReceiptResponse, error) {
+	res, err := r.inner.GetBlockReceipt(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return res, writeFixture(heightPath(r.dir, blockReceiptsDir, blockNumber), res)
+}
+
+// GetTransactionReceipt forwards to the node and records the receipt
+func (r *Recorder) GetTransactionReceipt(ctx context.Context, txHash string) (*node.TxReceiptResponse, error) {
+	res, err := r.inner.GetTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	return res, writeFixture(receiptPath(r.dir, txHash), res)
+}
+
+// CodeAt forwards to the node and records the contract code
+func (r *Recorder) CodeAt(ctx context.Context, address string, blockNumber uint64) (*node.CodeAtResponse, error) {
+	res, err := r.inner.CodeAt(ctx, address, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return res, writeFixture(codePath(r.dir, address, blockNumber), res)
+}
+
+// CallContext forwards any other JSON-RPC method to the node and records its result
+func (r *Recorder) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if r.caller == nil {
+		return errors.Errorf("cannot record %s: recorder has no caller", method)
+	}
+
+	var raw json.RawMessage
+	if err := r.caller.CallContext(ctx, &raw, method, args...); err != nil {
+		return err
+	}
+	if err := writeFixture(callPath(r.dir, method, args), raw); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, result)
+}
+
+// GetEthClient returns the underlying node's ethclient
+func (r *Recorder) GetEthClient() *ethclient.Client {
+	return r.inner.GetEthClient()
+}
+
+// RecordBlock captures everything a driver fetches for a single height: the block, its receipts and its traces
+func (r *Recorder) RecordBlock(ctx context.Context, blockNumber uint64, opts RecordOptions) error {
+	block, err := r.GetBlockByNumber(ctx, blockNumber)
+	if err != nil {
+		return errors.Wrapf(err, "cannot record block %d", blockNumber)
+	}
+
+	if opts.PerTxReceipts {
+		var txs struct {
+			Transactions []struct {
+				Hash string `json:"hash"`
+			} `json:"transactions"`
+		}
+		if err := json.Unmarshal(block.Result, &txs); err != nil {
+			return errors.Errorf("cannot decode transactions for block %d: %v", blockNumber, err)
+		}
+		for _, tx := range txs.Transactions {
+			if _, err := r.GetTransactionReceipt(ctx, tx.Hash); err != nil {
+				return errors.Wrapf(err, "cannot record receipt %s", tx.Hash)
+			}
+		}
+	} else if _, err := r.GetBlockReceipt(ctx, blockNumber); err != nil {
+		return errors.Wrapf(err, "cannot record receipts for block %d", blockNumber)
+	}
+
+	for _, method := range opts.BlockCalls {
+		var result json.RawMessage
+		if err := r.CallContext(ctx, &result, method, blockNumber); err != nil {
+			return errors.Wrapf(err, "cannot record %s for block %d", method, blockNumber)
+		}
+	}
+
+	if !opts.SkipTraces {
+		if _, err := r.GetTracesForBlock(ctx, blockNumber); err != nil {
+			return errors.Wrapf(err, "cannot record traces for block %d", blockNumber)
+		}
+	}
+
+	return nil
+}
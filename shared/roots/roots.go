@@ -0,0 +1,232 @@
+// Package roots recomputes the transactions and receipts roots of a block from the hex fields a node returned for it.
+package roots
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+)
+
+// Transaction types with a consensus encoding here
+const (
+	TxTypeLegacy     = 0x0
+	TxTypeAccessList = 0x1
+	TxTypeDynamicFee = 0x2
+	TxTypeBlob       = 0x3
+)
+
+// Transaction holds the fields of a transaction that its consensus encoding covers; which are used depends on Type
+type Transaction struct {
+	Type                 string
+	ChainId              string
+	Nonce                string
+	GasPrice             string
+	MaxPriorityFeePerGas string
+	MaxFeePerGas         string
+	Gas                  string
+	To                   string
+	Value                string
+	Input                string
+	AccessList           []Access
+	MaxFeePerBlobGas     string
+	BlobVersionedHashes  []string
+	V                    string
+	R                    string
+	S                    string
+}
+
+// Access is an entry of a transaction's access list
+type Access struct {
+	Address     string
+	StorageKeys []string
+}
+
+// Receipt holds the fields of a receipt that its consensus encoding covers. Root is the post-transaction state root
+// of receipts from before Byzantium, which have it in place of Status
+type Receipt struct {
+	Type              string
+	Root              string
+	Status            string
+	CumulativeGasUsed string
+	LogsBloom         string
+	Logs              []Log
+}
+
+// Log holds the fields of a log that its receipt's consensus encoding covers
+type Log struct {
+	Address string
+	Topics  []string
+	Data    string
+}
+
+// TransactionsRoot returns the root of the trie of a block's transactions
+func TransactionsRoot(transactions []*Transaction) (string, error) {
+	encoded := make([][]byte, len(transactions))
+	for i, tx := range transactions {
+		var err error
+		if encoded[i], err = EncodeTransaction(tx); err != nil {
+			return "", errors.Wrapf(err, "cannot encode transaction %d", i)
+		}
+	}
+	return derive(encoded), nil
+}
+
+// ReceiptsRoot returns the root of the trie of a block's receipts
+func ReceiptsRoot(receipts []*Receipt) (string, error) {
+	encoded := make([][]byte, len(receipts))
+	for i, receipt := range receipts {
+		var err error
+		if encoded[i], err = EncodeReceipt(receipt); err != nil {
+			return "", errors.Wrapf(err, "cannot encode receipt %d", i)
+		}
+	}
+	return derive(encoded), nil
+}
+
+// EncodeTransaction returns the consensus encoding of a transaction: the RLP list of its fields, preceded by its type
+// for typed (EIP-2718) transactions
+func EncodeTransaction(tx *Transaction) ([]byte, error) {
+	var p parser
+	txType := p.uint(tx.Type)
+
+	var inner types.TxData
+	switch txType {
+	case TxTypeLegacy:
+		inner = &types.LegacyTx{Nonce: p.uint(tx.Nonce), GasPrice: p.big(tx.GasPrice), Gas: p.uint(tx.Gas), To: p.address(tx.To), Value: p.big(tx.Value), Data: p.bytes(tx.Input), V: p.big(tx.V), R: p.big(tx.R), S: p.big(tx.S)}
+	case TxTypeAccessList:
+		inner = &types.AccessListTx{ChainID: p.big(tx.ChainId), Nonce: p.uint(tx.Nonce), GasPrice: p.big(tx.GasPrice), Gas: p.uint(tx.Gas), To: p.address(tx.To), Value: p.big(tx.Value), Data: p.bytes(tx.Input), AccessList: p.accessList(tx.AccessList), V: p.big(tx.V), R: p.big(tx.R), S: p.big(tx.S)}
+	case TxTypeDynamicFee:
+		inner = &types.DynamicFeeTx{ChainID: p.big(tx.ChainId), Nonce: p.uint(tx.Nonce), GasTipCap: p.big(tx.MaxPriorityFeePerGas), GasFeeCap: p.big(tx.MaxFeePerGas), Gas: p.uint(tx.Gas), To: p.address(tx.To), Value: p.big(tx.Value), Data: p.bytes(tx.Input), AccessList: p.accessList(tx.AccessList), V: p.big(tx.V), R: p.big(tx.R), S: p.big(tx.S)}
+	case TxTypeBlob:
+		return encodeBlobTransaction(tx)
+	default:
+		return nil, errors.Errorf("unsupported transaction type %s", tx.Type)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return types.NewTx(inner).MarshalBinary()
+}
+
+// encodeBlobTransaction returns the consensus encoding of a blob (EIP-4844) transaction, which the go-ethereum
+// release this module builds with predates
+func encodeBlobTransaction(tx *Transaction) ([]byte, error) {
+	var p parser
+	hashes := make([]common.Hash, len(tx.BlobVersionedHashes))
+	for i, hash := range tx.BlobVersionedHashes {
+		hashes[i] = common.BytesToHash(p.bytes(hash))
+	}
+	//	Blob transactions cannot create contracts, so the recipient is always an address
+	fields := []interface{}{p.big(tx.ChainId), p.big(tx.Nonce), p.big(tx.MaxPriorityFeePerGas), p.big(tx.MaxFeePerGas), p.big(tx.Gas), p.bytes(tx.To), p.big(tx.Value), p.bytes(tx.Input), p.accessList(tx.AccessList), p.big(tx.MaxFeePerBlobGas), hashes, p.big(tx.V), p.big(tx.R), p.big(tx.S)}
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	encoded, err := rlp.EncodeToBytes(fields)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{TxTypeBlob}, encoded...), nil
+}
+
+// EncodeReceipt returns the consensus encoding of a receipt: the RLP list of its status (or state root), cumulative
+// gas used, bloom and logs, preceded by its type for receipts of typed transactions
+func EncodeReceipt(receipt *Receipt) ([]byte, error) {
+	var p parser
+	txType := p.uint(receipt.Type)
+	if txType > TxTypeBlob {
+		return nil, errors.Errorf("unsupported receipt type %s", receipt.Type)
+	}
+
+	bloom := p.bytes(receipt.LogsBloom)
+	if len(bloom) != types.BloomByteLength && p.err == nil {
+		return nil, errors.Errorf("logs bloom of %d bytes", len(bloom))
+	}
+	out := &types.Receipt{
+		Type: uint8(txType),
+		//	Some nodes return an empty root, "0x", alongside the status of receipts from after Byzantium
+		PostState:         p.bytes(receipt.Root),
+		Status:            p.uint(receipt.Status),
+		CumulativeGasUsed: p.uint(receipt.CumulativeGasUsed),
+		Bloom:             types.BytesToBloom(bloom),
+	}
+	for _, log := range receipt.Logs {
+		topics := make([]common.Hash, len(log.Topics))
+		for i, topic := range log.Topics {
+			topics[i] = common.BytesToHash(p.bytes(topic))
+		}
+		out.Logs = append(out.Logs, &types.Log{Address: common.BytesToAddress(p.bytes(log.Address)), Topics: topics, Data: p.bytes(log.Data)})
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return out.MarshalBinary()
+}
+
+// parser parses hex fields, keeping the first error so that a whole transaction or receipt can be parsed before it is
+// checked
+type parser struct {
+	err error
+}
+
+// big parses a hex quantity; an empty one is zero
+func (p *parser) big(s string) *big.Int {
+	n := new(big.Int)
+	digits := strings.TrimPrefix(s, "0x")
+	if digits == "" {
+		return n
+	}
+	if _, ok := n.SetString(digits, 16); !ok && p.err == nil {
+		p.err = errors.Errorf("invalid quantity %q", s)
+	}
+	return n
+}
+
+// uint parses a hex quantity that fits in 64 bits
+func (p *parser) uint(s string) uint64 {
+	n := p.big(s)
+	if !n.IsUint64() && p.err == nil {
+		p.err = errors.Errorf("quantity %q overflows 64 bits", s)
+	}
+	return n.Uint64()
+}
+
+// bytes parses hex data; an empty string, such as the recipient of a contract creation, is no data
+func (p *parser) bytes(s string) []byte {
+	if s == "" {
+		return []byte{}
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil && p.err == nil {
+		p.err = errors.Wrapf(err, "invalid data %q", s)
+	}
+	return b
+}
+
+// address parses an address; an empty one, the recipient of a contract creation, is none
+func (p *parser) address(s string) *common.Address {
+	if s == "" {
+		return nil
+	}
+	address := common.BytesToAddress(p.bytes(s))
+	return &address
+}
+
+// accessList converts an access list to go-ethereum's
+func (p *parser) accessList(list []Access) types.AccessList {
+	out := make(types.AccessList, len(list))
+	for i, access := range list {
+		keys := make([]common.Hash, len(access.StorageKeys))
+		for j, key := range access.StorageKeys {
+			keys[j] = common.BytesToHash(p.bytes(key))
+		}
+		out[i] = types.AccessTuple{Address: common.BytesToAddress(p.bytes(access.Address)), StorageKeys: keys}
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+package roots
+
+import (
+	"strings"
+	"testing"
+)
+
+// emptyRoot is the root of an empty trie, which every block without transactions commits to
+const emptyRoot = "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"
+
+var (
+	emptyBloom = "0x" + strings.Repeat("00", 256)
+
+	// legacyTx is the transaction of the legacy block in go-ethereum's block encoding tests
+	legacyTx = &Transaction{
+		Type:     "0x0",
+		Nonce:    "0x0",
+		GasPrice: "0xa",
+		Gas:      "0xc350",
+		To:       "0x095e7baea6a6c7c4c2dfeb977efac326af552d87",
+		Value:    "0xa",
+		Input:    "0x",
+		V:        "0x1b",
+		R:        "0x9bea4c4daac7c7c52e093e6a4c35dbbcf8856f1af7b059ba20253e70848d094f",
+		S:        "0x8a8fae537ce25ed8cb5af9adac3f141af69bd515bd2ba031522df09b97dd72b1",
+	}
+	accessList = []Access{{
+		Address:     "0x0000000000000000000000000000000000000001",
+		StorageKeys: []string{"0x0000000000000000000000000000000000000000000000000000000000000000"},
+	}}
+	testLog = Log{
+		Address: "0x095e7baea6a6c7c4c2dfeb977efac326af552d87",
+		Topics:  []string{"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"},
+		Data:    "0x2a",
+	}
+)
+
+// Roots other than the legacy block's transactions root are as go-ethereum computes them from the same transactions
+// decoded from JSON-RPC, and receipts; the release here predates blob transactions, so the blob transaction was encoded
+// field by field in the order EIP-4844 gives
+func TestRoots(t *testing.T) {
+	tests := []struct {
+		name             string
+		transactions     []*Transaction
+		receipts         []*Receipt
+		wantTransactions string
+		wantReceipts     string
+	}{
+		{
+			name:             "empty block",
+			wantTransactions: emptyRoot,
+			wantReceipts:     emptyRoot,
+		},
+		{
+			//	The transactions root is that of the header of go-ethereum's legacy test block; its receipt is from
+			//	before Byzantium, so has a state root in place of a status
+			name:         "legacy",
+			transactions: []*Transaction{legacyTx},
+			receipts: []*Receipt{
+				{Type: "0x0", Root: "0xef1552a40b7165c3cd773806b9e0c165b75356e0314bf0706f279c729f51e017", CumulativeGasUsed: "0x5208", LogsBloom: emptyBloom},
+			},
+			wantTransactions: "0x5fe50b260da6308036625b850b5d6ced6d0a9f814c0688bc91ffb7b7a3a54b67",
+			wantReceipts:     "0x8686006ac4050fd20f301b9e9cc61efa86ca8b940ec32da44c609684be76ce23",
+		},
+		{
+			name: "access list",
+			transactions: []*Transaction{legacyTx, {
+				Type:       "0x1",
+				ChainId:    "0x1",
+				Nonce:      "0x0",
+				GasPrice:   "0xa",
+				Gas:        "0x1e241",
+				To:         "0x095e7baea6a6c7c4c2dfeb977efac326af552d87",
+				Value:      "0x0",
+				Input:      "0x",
+				AccessList: accessList,
+				V:          "0x1",
+				R:          "0x3dbacc8d0259f2508625e97fdfc57cd85fdd16e5821bc2c10bdd1a52649e8335",
+				S:          "0x476e10695b183a87b0aa292a7f4b78ef0c3fbe62aa2c42c84e1d9c3da159ef14",
+			}},
+			receipts: []*Receipt{
+				{Type: "0x0", Status: "0x1", CumulativeGasUsed: "0x5208", LogsBloom: emptyBloom},
+				{Type: "0x1", Status: "0x0", CumulativeGasUsed: "0xa410", LogsBloom: emptyBloom},
+			},
+			wantTransactions: "0x3cb6deb616ff113b756d426828b841be06043f4ea00138e977fc3eb290c01443",
+			wantReceipts:     "0x66d1ca4dc89e44763d901009e258686d0382cc96b31cbb9ce60858d2ceb459d5",
+		},
+		{
+			//	A contract creation has no recipient, and some nodes return an empty root alongside a status
+			name: "dynamic fee",
+			transactions: []*Transaction{legacyTx, {
+				Type:                 "0x2",
+				ChainId:              "0x1",
+				Nonce:                "0x0",
+				MaxPriorityFeePerGas: "0x0",
+				MaxFeePerGas:         "0x3b9aca00",
+				Gas:                  "0x1e241",
+				To:                   "0x095e7baea6a6c7c4c2dfeb977efac326af552d87",
+				Value:                "0x0",
+				Input:                "0x",
+				AccessList:           accessList,
+				V:                    "0x0",
+				R:                    "0xfe38ca4e44a30002ac54af7cf922a6ac2ba11b7d22f548e8ecb3f51f41cb31b0",
+				S:                    "0x6de6a5cbae13c0c856e33acf021b51819636cfc009d39eafb9f606d546e305a8",
+			}, {
+				Type:                 "0x2",
+				ChainId:              "0x1",
+				Nonce:                "0x1",
+				MaxPriorityFeePerGas: "0x3b9aca00",
+				MaxFeePerGas:         "0x77359400",
+				Gas:                  "0x30d40",
+				Value:                "0x0",
+				Input:                "0x6080604052",
+				V:                    "0x1",
+				R:                    "0x1111111111111111111111111111111111111111111111111111111111111111",
+				S:                    "0x2222222222222222222222222222222222222222222222222222222222222222",
+			}},
+			receipts: []*Receipt{
+				{Type: "0x0", Status: "0x1", CumulativeGasUsed: "0x5208", LogsBloom: emptyBloom},
+				{Type: "0x2", Root: "0x", Status: "0x1", CumulativeGasUsed: "0xa410", LogsBloom: emptyBloom, Logs: []Log{testLog}},
+				{Type: "0x2", Status: "0x0", CumulativeGasUsed: "0x1a410", LogsBloom: emptyBloom},
+			},
+			wantTransactions: "0x666ec19b1cdb47318e891495c29ea044b4a41915728bdaa802d829908cf7b748",
+			wantReceipts:     "0x1c3c97043dbc4bda41eb360418d643cc35fbf029081b5d2db3dfb4ea8e4a2477",
+		},
+		{
+			name: "blob",
+			transactions: []*Transaction{legacyTx, {
+				Type:                 "0x3",
+				ChainId:              "0x1",
+				Nonce:                "0x2",
+				MaxPriorityFeePerGas: "0x3b9aca00",
+				MaxFeePerGas:         "0x77359400",
+				Gas:                  "0x5208",
+				To:                   "0xff00000000000000000000000000000000000000",
+				Value:                "0x0",
+				Input:                "0x",
+				AccessList:           accessList,
+				MaxFeePerBlobGas:     "0x3b9aca00",
+				BlobVersionedHashes: []string{
+					"0x01659f8a49133759d495ee5d15262cdc0050f9027e20c7bed3e0599e27adec4b",
+					"0x018fc9d98c32189fe8232b46db86446b16895b4e2a911803d4b9c1d229838914",
+				},
+				V: "0x1",
+				R: "0x3333333333333333333333333333333333333333333333333333333333333333",
+				S: "0x4444444444444444444444444444444444444444444444444444444444444444",
+			}},
+			receipts: []*Receipt{
+				{Type: "0x0", Status: "0x1", CumulativeGasUsed: "0x5208", LogsBloom: emptyBloom},
+				{Type: "0x3", Status: "0x1", CumulativeGasUsed: "0xa410", LogsBloom: emptyBloom, Logs: []Log{testLog, testLog}},
+			},
+			wantTransactions: "0x41c9be9ea7ff3be13f37e2d03760d83d1f7dfc86b972fc205a0f2597422f088d",
+			wantReceipts:     "0xf6de2387f9cbfde15318e2522a236f5ec076e5bbe9ab83bc1148f950c1a5431b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TransactionsRoot(tt.transactions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantTransactions {
+				t.Errorf("transactions root = %s, want %s", got, tt.wantTransactions)
+			}
+
+			got, err = ReceiptsRoot(tt.receipts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantReceipts {
+				t.Errorf("receipts root = %s, want %s", got, tt.wantReceipts)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package roots
+
+import (
+	"bytes"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// encodedList is a list of values already in their consensus encoding, as types.DeriveSha takes them
+type encodedList [][]byte
+
+func (l encodedList) Len() int {
+	return len(l)
+}
+
+func (l encodedList) EncodeIndex(i int, w *bytes.Buffer) {
+	w.Write(l[i])
+}
+
+// derive returns the root of the trie of encoded values keyed by their index
+func derive(encoded [][]byte) string {
+	return types.DeriveSha(encodedList(encoded), trie.NewStackTrie(nil)).Hex()
+}
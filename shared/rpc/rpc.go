@@ -0,0 +1,56 @@
+// Package rpc holds the JSON-RPC response types that every EVM node returns alike, for the drivers of chains whose
+// blocks are decoded into plain structs rather than chain-interactor protos. Fields follow the names of the protos, and
+// all quantities are hex strings, as returned by the node.
+package rpc
+
+// Access is an entry of a transaction's access list
+type Access struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storageKeys"`
+}
+
+// Log is a log of a transaction receipt
+type Log struct {
+	Removed          bool     `json:"removed"`
+	LogIndex         string   `json:"logIndex"`
+	TransactionIndex string   `json:"transactionIndex"`
+	TransactionHash  string   `json:"transactionHash"`
+	BlockNumber      string   `json:"blockNumber"`
+	BlockHash        string   `json:"blockHash"`
+	Address          string   `json:"address"`
+	Data             string   `json:"data"`
+	Topics           []string `json:"topics"`
+}
+
+func (x *Log) GetLogIndex() string         { return x.LogIndex }
+func (x *Log) GetTransactionIndex() string { return x.TransactionIndex }
+func (x *Log) GetTransactionHash() string  { return x.TransactionHash }
+func (x *Log) GetBlockNumber() string      { return x.BlockNumber }
+func (x *Log) GetBlockHash() string        { return x.BlockHash }
+func (x *Log) GetAddress() string          { return x.Address }
+func (x *Log) GetData() string             { return x.Data }
+func (x *Log) GetTopics() []string         { return x.Topics }
+
+// CallTrace is a call frame as returned by debug_traceBlockByNumber's callTracer
+type CallTrace struct {
+	Type         string       `json:"type"`
+	From         string       `json:"from"`
+	To           string       `json:"to"`
+	Value        string       `json:"value"`
+	Gas          string       `json:"gas"`
+	GasUsed      string       `json:"gasUsed"`
+	Input        string       `json:"input"`
+	Output       string       `json:"output"`
+	Error        string       `json:"error"`
+	RevertReason string       `json:"revertReason"`
+	Calls        []*CallTrace `json:"calls"`
+}
+
+func (x *CallTrace) GetType() string        { return x.Type }
+func (x *CallTrace) GetFrom() string        { return x.From }
+func (x *CallTrace) GetTo() string          { return x.To }
+func (x *CallTrace) GetValue() string       { return x.Value }
+func (x *CallTrace) GetInput() string       { return x.Input }
+func (x *CallTrace) GetOutput() string      { return x.Output }
+func (x *CallTrace) GetError() string       { return x.Error }
+func (x *CallTrace) GetCalls() []*CallTrace { return x.Calls }
@@ -0,0 +1,491 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/util"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Supported values for a driver's WRITE_MODE
+const (
+	// WriteModeBlock writes one file per height per entity, which keeps latency low when following the chaintip
+	WriteModeBlock = "block"
+	// WriteModeBatch buffers consecutive heights and writes one file per batch, which suits backfills
+	WriteModeBatch = "batch"
+)
+
+// ValidateWriteMode rejects a write mode other than WriteModeBlock or WriteModeBatch
+func ValidateWriteMode(mode string) error {
+	switch mode {
+	case WriteModeBlock, WriteModeBatch:
+		return nil
+	}
+	return errors.Errorf("unsupported write mode %q; expected %s or %s", mode, WriteModeBlock, WriteModeBatch)
+}
+
+const (
+	// manifestPrefix is the top-level directory holding batch manifests, kept apart from entity directories so that
+	// those only ever contain data files
+	manifestPrefix = "manifests"
+)
+
+// BatchConfig controls how a BatchWriter groups heights into files
+type BatchConfig struct {
+	// BatchSize is the number of consecutive heights per file; batches are aligned to multiples of BatchSize and
+	// must not straddle a range directory
+	BatchSize uint64
+	// MaxBytes flushes a batch early once its buffered rows exceed this approximate size; zero disables the budget
+	MaxBytes int
+	// DirectoryRange is the range directory size, as passed to util.RangeName
+	DirectoryRange uint64
+	// OnWrite, if set, is called with the manifest of every batch once the batch has been written
+	OnWrite func(ctx context.Context, manifest *BatchManifest) error
+}
+
+// BatchManifest records exactly which heights a batched file covers, including heights that contributed no rows;
+// RowCounts holds the number of rows each height contributed, in the same order as Heights
+type BatchManifest struct {
+	Entity      string  `parquet:"name=entity, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Filename    string  `parquet:"name=filename, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	StartHeight int64   `parquet:"name=start_height, type=INT64"`
+	EndHeight   int64   `parquet:"name=end_height, type=INT64"`
+	Heights     []int64 `parquet:"name=heights, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	RowCounts   []int64 `parquet:"name=row_counts, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	RowCount    int64   `parquet:"name=row_count, type=INT64"`
+}
+
+// BatchWriter buffers rows for consecutive heights per entity and writes them as a single parquet file per batch,
+// rather than one file per height; heights may arrive in any order, and a batch is written as soon as every height
+// in it has been added
+type BatchWriter struct {
+	store   Store
+	cfg     BatchConfig
+	mu      sync.Mutex
+	batches map[batchKey]*batch
+}
+
+type batchKey struct {
+	entity string
+	start  uint64
+}
+
+type batch struct {
+	mapToStruct interface{}
+	rows        map[uint64][]interface{}
+	bytes       int
+	// flushed holds heights of this batch already written, early because of the byte budget or before a restart
+	flushed map[uint64]bool
+}
+
+// done counts the heights of a batch that have been added, whether still buffered or already written
+func (c *batch) done() uint64 {
+	count := uint64(len(c.rows))
+	for height := range c.flushed {
+		if _, ok := c.rows[height]; !ok {
+			count++
+		}
+	}
+	return count
+}
+
+// NewBatchWriter constructs a BatchWriter on top of a Store
+func NewBatchWriter(store Store, cfg BatchConfig) (*BatchWriter, error) {
+	if cfg.BatchSize == 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+	if cfg.DirectoryRange%cfg.BatchSize != 0 {
+		return nil, errors.Errorf("batch size %d does not divide directory range %d", cfg.BatchSize, cfg.DirectoryRange)
+	}
+
+	return &BatchWriter{
+		store:   store,
+		cfg:     cfg,
+		batches: make(map[batchKey]*batch),
+	}, nil
+}
+
+// Add buffers the rows of an entity for a single height; rows may be empty, which still marks the height as done. A
+// height already written, as when a restart re-ingests it, has its rows replaced in the written batch
+func (b *BatchWriter) Add(ctx context.Context, entity string, height uint64, rows []interface{}, mapToStruct interface{}) error {
+	key := batchKey{entity: entity, start: (height / b.cfg.BatchSize) * b.cfg.BatchSize}
+
+	b.mu.Lock()
+	_, ok := b.batches[key]
+	b.mu.Unlock()
+
+	//	A batch seen for the first time may have been partly written before a restart, so its manifests are consulted
+	var flushed map[uint64]bool
+	if !ok {
+		var err error
+		if flushed, err = b.writtenHeights(ctx, entity, key.start); err != nil {
+			return errors.Wrapf(err, "cannot list written heights of %s batch %d", entity, key.start)
+		}
+	}
+
+	b.mu.Lock()
+	current, ok := b.batches[key]
+	if !ok {
+		current = &batch{mapToStruct: mapToStruct, rows: make(map[uint64][]interface{}), flushed: flushed}
+		b.batches[key] = current
+	}
+	if _, buffered := current.rows[height]; !buffered && current.flushed[height] {
+		b.mu.Unlock()
+		//	Buffering the height again would write its rows a second time, beside the copy already stored
+		return b.Replace(ctx, entity, height, rows, mapToStruct)
+	}
+	current.rows[height] = rows
+	for _, row := range rows {
+		current.bytes += approxSize(reflect.ValueOf(row))
+	}
+
+	full := current.done() == b.cfg.BatchSize
+	overBudget := b.cfg.MaxBytes > 0 && current.bytes >= b.cfg.MaxBytes
+	if full {
+		delete(b.batches, key)
+	} else if overBudget {
+		//	Carry the written heights forward, so the remainder of the batch still completes once its last height arrives
+		remainder := &batch{
+			mapToStruct: mapToStruct,
+			rows:        make(map[uint64][]interface{}),
+			flushed:     make(map[uint64]bool, len(current.flushed)+len(current.rows)),
+		}
+		for height := range current.flushed {
+			remainder.flushed[height] = true
+		}
+		for height := range current.rows {
+			remainder.flushed[height] = true
+		}
+		b.batches[key] = remainder
+	}
+	b.mu.Unlock()
+
+	if full || overBudget {
+		return b.flushBatch(ctx, key, current)
+	}
+	return nil
+}
+
+// Flush writes every partially-filled batch; it should be called on shutdown so that buffered rows are not lost
+func (b *BatchWriter) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	pending := b.batches
+	b.batches = make(map[batchKey]*batch)
+	b.mu.Unlock()
+
+	var errs []error
+	for key, current := range pending {
+		if len(current.rows) == 0 {
+			continue
+		}
+		if err := b.flushBatch(ctx, key, current); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to flush %d batches: %v", len(errs), errs)
+	}
+	return nil
+}
+
+// Read returns the rows of an entity for a single height, whether they are still buffered or already written as part
+// of a batch
+func (b *BatchWriter) Read(ctx context.Context, entity string, height uint64, mapToStruct interface{}) ([]interface{}, error) {
+	key := batchKey{entity: entity, start: (height / b.cfg.BatchSize) * b.cfg.BatchSize}
+	b.mu.Lock()
+	if current, ok := b.batches[key]; ok {
+		if rows, ok := current.rows[height]; ok {
+			b.mu.Unlock()
+			return rows, nil
+		}
+	}
+	b.mu.Unlock()
+
+	manifest, err := b.locate(ctx, entity, height)
+	if err != nil {
+		return nil, err
+	}
+	heights, err := ReadBatch(ctx, b.store, manifest, mapToStruct)
+	if err != nil {
+		return nil, err
+	}
+	return heights[height], nil
+}
+
+// Replace swaps the rows of an entity for a height that has already been added, as when a reorg orphans it: a height
+// still buffered is swapped in place, and a written batch is read back and written again in full
+func (b *BatchWriter) Replace(ctx context.Context, entity string, height uint64, rows []interface{}, mapToStruct interface{}) error {
+	key := batchKey{entity: entity, start: (height / b.cfg.BatchSize) * b.cfg.BatchSize}
+	b.mu.Lock()
+	if current, ok := b.batches[key]; ok {
+		if _, ok := current.rows[height]; ok {
+			current.rows[height] = rows
+			b.mu.Unlock()
+			return nil
+		}
+	}
+	b.mu.Unlock()
+
+	manifest, err := b.locate(ctx, entity, height)
+	if err != nil {
+		return err
+	}
+	heights, err := ReadBatch(ctx, b.store, manifest, mapToStruct)
+	if err != nil {
+		return err
+	}
+	heights[height] = rows
+
+	return b.write(ctx, entity, &batch{mapToStruct: mapToStruct, rows: heights})
+}
+
+// writtenHeights returns the heights of the batch starting at start that written batches of an entity already hold
+func (b *BatchWriter) writtenHeights(ctx context.Context, entity string, start uint64) (map[uint64]bool, error) {
+	end := start + b.cfg.BatchSize - 1
+	prefix := ManifestFilename(fmt.Sprintf("%s/%s/", entity, util.RangeName(start, b.cfg.DirectoryRange)))
+	manifests, err := b.store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	written := make(map[uint64]bool)
+	for _, file := range manifests {
+		first, last, err := util.ParseFileHeights(file.Name)
+		if err != nil || last < start || first > end {
+			continue
+		}
+
+		manifest, err := readManifest(ctx, b.store, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range manifest.Heights {
+			if uint64(h) >= start && uint64(h) <= end {
+				written[uint64(h)] = true
+			}
+		}
+	}
+	return written, nil
+}
+
+// Exists reports whether an entity's rows for a height have been added, whether they are still buffered or already
+// written as part of a batch
+func (b *BatchWriter) Exists(ctx context.Context, entity string, height uint64) (bool, error) {
+	key := batchKey{entity: entity, start: (height / b.cfg.BatchSize) * b.cfg.BatchSize}
+	b.mu.Lock()
+	if current, ok := b.batches[key]; ok {
+		if _, ok := current.rows[height]; ok {
+			b.mu.Unlock()
+			return true, nil
+		}
+	}
+	b.mu.Unlock()
+
+	manifest, err := FindManifest(ctx, b.store, entity, height, b.cfg.DirectoryRange)
+	return manifest != nil, err
+}
+
+// locate finds the manifest of the written batch that holds an entity's rows for a height
+func (b *BatchWriter) locate(ctx context.Context, entity string, height uint64) (*BatchManifest, error) {
+	manifest, err := FindManifest(ctx, b.store, entity, height, b.cfg.DirectoryRange)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, errors.Errorf("height %d of %s: %v", height, entity, ErrNotExist)
+	}
+	return manifest, nil
+}
+
+// FindManifest returns the manifest of the batched or compacted file that holds an entity's rows for a height, or nil
+// if there is none
+func FindManifest(ctx context.Context, store Store, entity string, height uint64, directoryRange uint64) (*BatchManifest, error) {
+	prefix := ManifestFilename(fmt.Sprintf("%s/%s/", entity, util.RangeName(height, directoryRange)))
+	manifests, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range manifests {
+		start, end, err := util.ParseFileHeights(file.Name)
+		if err != nil || height < start || height > end {
+			continue
+		}
+
+		manifest, err := readManifest(ctx, store, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range manifest.Heights {
+			if uint64(h) == height {
+				return manifest, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// ReadManifest reads the manifest describing a batched or compacted file, returning nil if it has none
+func ReadManifest(ctx context.Context, store Store, batchFilename string) (*BatchManifest, error) {
+	name := ManifestFilename(batchFilename)
+	exists, err := store.Exists(ctx, name)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return readManifest(ctx, store, name)
+}
+
+// readManifest reads a single batch manifest
+func readManifest(ctx context.Context, store Store, filename string) (*BatchManifest, error) {
+	var manifest []BatchManifest
+	if err := store.ReadMany(ctx, filename, new(BatchManifest), &manifest); err != nil {
+		return nil, err
+	}
+	if len(manifest) != 1 {
+		return nil, errors.Errorf("manifest %s holds %d rows", filename, len(manifest))
+	}
+	return &manifest[0], nil
+}
+
+// ReadBatch reads back the rows of a batched or compacted file, divided between the heights its manifest lists, which
+// are stored in height order; columns the file predates are left zero
+func ReadBatch(ctx context.Context, store Store, manifest *BatchManifest, mapToStruct interface{}) (map[uint64][]interface{}, error) {
+	rows, _, err := ReadCompatible(ctx, store, manifest.Filename, mapToStruct)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(rows)) != manifest.RowCount {
+		return nil, errors.Errorf("batch %s holds %d rows, but its manifest lists %d", manifest.Filename, len(rows), manifest.RowCount)
+	}
+
+	heights := make(map[uint64][]interface{}, len(manifest.Heights))
+	var offset int64
+	for i, h := range manifest.Heights {
+		heights[uint64(h)] = rows[offset : offset+manifest.RowCounts[i]]
+		offset += manifest.RowCounts[i]
+	}
+	return heights, nil
+}
+
+// write outputs a batch's rows along with its manifest
+func (b *BatchWriter) write(ctx context.Context, entity string, current *batch) error {
+	manifest, err := WriteBatch(ctx, b.store, entity, current.rows, current.mapToStruct, b.cfg.DirectoryRange)
+	if err != nil {
+		return err
+	}
+	return b.onWrite(ctx, manifest)
+}
+
+// flushBatch writes a buffered batch; a batch whose file cannot be written is put back, so that its rows are written
+// by a later attempt rather than lost
+func (b *BatchWriter) flushBatch(ctx context.Context, key batchKey, current *batch) error {
+	manifest, err := WriteBatch(ctx, b.store, key.entity, current.rows, current.mapToStruct, b.cfg.DirectoryRange)
+	if err != nil {
+		b.restore(key, current)
+		return err
+	}
+	return b.onWrite(ctx, manifest)
+}
+
+// restore puts back the rows of a batch that could not be written, merging them into whatever remains of the batch
+// since it was taken
+func (b *BatchWriter) restore(key batchKey, failed *batch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current, ok := b.batches[key]
+	if !ok {
+		b.batches[key] = failed
+		return
+	}
+	for height, rows := range failed.rows {
+		if _, ok := current.rows[height]; !ok {
+			current.rows[height] = rows
+		}
+		delete(current.flushed, height)
+	}
+	current.bytes += failed.bytes
+}
+
+// onWrite passes the manifest of a written batch to BatchConfig.OnWrite, if set
+func (b *BatchWriter) onWrite(ctx context.Context, manifest *BatchManifest) error {
+	if b.cfg.OnWrite != nil {
+		return b.cfg.OnWrite(ctx, manifest)
+	}
+	return nil
+}
+
+// WriteBatch writes the rows of several heights of an entity to a single file, in height order, followed by the
+// manifest describing it
+func WriteBatch(ctx context.Context, store Store, entity string, rows map[uint64][]interface{}, mapToStruct interface{}, directoryRange uint64) (*BatchManifest, error) {
+	heights := make([]uint64, 0, len(rows))
+	for height := range rows {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	manifest := BatchManifest{
+		Entity:      entity,
+		Filename:    BatchFilename(entity, heights[0], heights[len(heights)-1], directoryRange),
+		StartHeight: int64(heights[0]),
+		EndHeight:   int64(heights[len(heights)-1]),
+	}
+	var outputs []interface{}
+	for _, height := range heights {
+		manifest.Heights = append(manifest.Heights, int64(height))
+		manifest.RowCounts = append(manifest.RowCounts, int64(len(rows[height])))
+		outputs = append(outputs, rows[height]...)
+	}
+	manifest.RowCount = int64(len(outputs))
+
+	if err := store.WriteMany(ctx, outputs, mapToStruct, manifest.Filename); err != nil {
+		return nil, errors.Wrapf(err, "cannot write batch %s", manifest.Filename)
+	}
+	if err := store.WriteOne(ctx, &manifest, new(BatchManifest), ManifestFilename(manifest.Filename)); err != nil {
+		return nil, errors.Wrapf(err, "cannot write manifest for batch %s", manifest.Filename)
+	}
+	return &manifest, nil
+}
+
+// BatchFilename names the file holding an entity's rows for heights start..end
+func BatchFilename(entity string, start uint64, end uint64, directoryRange uint64) string {
+	return fmt.Sprintf("%s/%s/%d-%d.parquet", entity, util.RangeName(start, directoryRange), start, end)
+}
+
+// ManifestFilename names the manifest describing a batched file
+func ManifestFilename(batchFilename string) string {
+	return fmt.Sprintf("%s/%s", manifestPrefix, batchFilename)
+}
+
+// approxSize estimates the encoded size of a row by summing the lengths of its strings and the widths of its
+// scalar fields; it only needs to be good enough to enforce a soft byte budget
+func approxSize(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return approxSize(v.Elem())
+	case reflect.String:
+		return v.Len()
+	case reflect.Slice, reflect.Array:
+		size := 0
+		for i := 0; i < v.Len(); i++ {
+			size += approxSize(v.Index(i))
+		}
+		return size
+	case reflect.Struct:
+		size := 0
+		for i := 0; i < v.NumField(); i++ {
+			size += approxSize(v.Field(i))
+		}
+		return size
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,340 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"testing"
+)
+
+type batchRow struct {
+	Height int64  `parquet:"name=height, type=INT64"`
+	Value  string `parquet:"name=value, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// batchRows returns the rows added for a height: none, one or two, so that batches hold heights without rows
+func batchRows(height uint64, value string) []interface{} {
+	rows := make([]interface{}, height%3)
+	for i := range rows {
+		rows[i] = &batchRow{Height: int64(height), Value: value}
+	}
+	return rows
+}
+
+func values(rows []interface{}) []string {
+	out := make([]string, len(rows))
+	for i, row := range rows {
+		out[i] = fmt.Sprintf("%d:%s", row.(*batchRow).Height, row.(*batchRow).Value)
+	}
+	return out
+}
+
+func TestBatchWriterAdd(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize uint64
+		maxBytes  int
+		// heights are added in order; a restart flushes the writer and replaces it with a new one on the same store
+		heights   []uint64
+		restartAt int
+		wantFiles []string
+	}{
+		{
+			name:      "in order",
+			batchSize: 4,
+			heights:   []uint64{0, 1, 2, 3, 4, 5},
+			wantFiles: []string{"rows/blocks_0-7/0-3.parquet"},
+		},
+		{
+			name:      "out of order",
+			batchSize: 4,
+			heights:   []uint64{2, 0, 5, 3, 7, 1, 6, 4},
+			wantFiles: []string{"rows/blocks_0-7/0-3.parquet", "rows/blocks_0-7/4-7.parquet"},
+		},
+		{
+			//	Rows are 9 bytes each, so the budget is exceeded at every height with two rows
+			name:      "byte budget",
+			batchSize: 8,
+			maxBytes:  18,
+			heights:   []uint64{0, 1, 2, 3, 4, 5, 6, 7},
+			wantFiles: []string{"rows/blocks_0-7/0-2.parquet", "rows/blocks_0-7/3-5.parquet", "rows/blocks_0-7/6-7.parquet"},
+		},
+		{
+			name:      "flush then restart",
+			batchSize: 4,
+			heights:   []uint64{0, 1, 3, 2},
+			restartAt: 2,
+			wantFiles: []string{"rows/blocks_0-7/0-1.parquet", "rows/blocks_0-7/2-3.parquet"},
+		},
+		{
+			name:      "byte budget then restart",
+			batchSize: 8,
+			maxBytes:  18,
+			heights:   []uint64{0, 1, 2, 3, 7, 6, 5, 4},
+			restartAt: 4,
+			wantFiles: []string{"rows/blocks_0-7/0-2.parquet", "rows/blocks_0-7/3-3.parquet", "rows/blocks_0-7/4-4.parquet", "rows/blocks_0-7/5-7.parquet"},
+		},
+		{
+			//	Height 1 was written before the restart, so adding it again rewrites its batch
+			name:      "re-added after restart",
+			batchSize: 4,
+			heights:   []uint64{0, 1, 2, 1, 3},
+			restartAt: 2,
+			wantFiles: []string{"rows/blocks_0-7/0-1.parquet", "rows/blocks_0-7/2-3.parquet"},
+		},
+		{
+			name:      "re-added after a byte budget split",
+			batchSize: 8,
+			maxBytes:  18,
+			heights:   []uint64{0, 1, 2, 1, 3, 4, 5, 6, 7},
+			wantFiles: []string{"rows/blocks_0-7/0-2.parquet", "rows/blocks_0-7/3-5.parquet", "rows/blocks_0-7/6-7.parquet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryConnector(8)
+			cfg := BatchConfig{BatchSize: tt.batchSize, MaxBytes: tt.maxBytes, DirectoryRange: 8}
+			writer, err := NewBatchWriter(store, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, height := range tt.heights {
+				if tt.restartAt > 0 && i == tt.restartAt {
+					if err := writer.Flush(ctx); err != nil {
+						t.Fatal(err)
+					}
+					if writer, err = NewBatchWriter(store, cfg); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := writer.Add(ctx, "rows", height, batchRows(height, "v"), new(batchRow)); err != nil {
+					t.Fatalf("add %d: %v", height, err)
+				}
+			}
+
+			if got := store.FilesWithPrefix("rows/"); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Fatalf("files = %v, want %v", got, tt.wantFiles)
+			}
+			for _, file := range tt.wantFiles {
+				if ok, _ := store.Exists(ctx, ManifestFilename(file)); !ok {
+					t.Errorf("no manifest for %s", file)
+				}
+			}
+			for _, height := range tt.heights {
+				rows, err := writer.Read(ctx, "rows", height, new(batchRow))
+				if err != nil {
+					t.Fatalf("read %d: %v", height, err)
+				}
+				if got, want := values(rows), values(batchRows(height, "v")); !reflect.DeepEqual(got, want) {
+					t.Errorf("height %d = %v, want %v", height, got, want)
+				}
+			}
+		})
+	}
+}
+
+// failingStore fails every write while fail is set
+type failingStore struct {
+	*MemoryConnector
+	fail bool
+}
+
+func (f *failingStore) WriteMany(ctx context.Context, input []interface{}, mapToStruct interface{}, filename string) error {
+	if f.fail {
+		return errors.Errorf("cannot write %s", filename)
+	}
+	return f.MemoryConnector.WriteMany(ctx, input, mapToStruct, filename)
+}
+
+func TestBatchWriterKeepsFailedBatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxBytes int
+		// heights are added while writes fail, and retry once they succeed again
+		heights   []uint64
+		retry     []uint64
+		wantFiles []string
+	}{
+		{
+			name:      "full batch",
+			heights:   []uint64{0, 1, 2, 3},
+			wantFiles: []string{"rows/blocks_0-7/0-3.parquet"},
+		},
+		{
+			//	Heights 0-2 fail to be written early, so are written with the rest of the batch
+			name:      "byte budget",
+			maxBytes:  18,
+			heights:   []uint64{0, 1, 2},
+			retry:     []uint64{3},
+			wantFiles: []string{"rows/blocks_0-7/0-3.parquet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := &failingStore{MemoryConnector: NewMemoryConnector(8), fail: true}
+			writer, err := NewBatchWriter(store, BatchConfig{BatchSize: 4, MaxBytes: tt.maxBytes, DirectoryRange: 8})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var failed bool
+			for _, height := range tt.heights {
+				if err := writer.Add(ctx, "rows", height, batchRows(height, "v"), new(batchRow)); err != nil {
+					failed = true
+				}
+			}
+			if !failed {
+				t.Fatal("no write failed")
+			}
+
+			store.fail = false
+			for _, height := range tt.retry {
+				if err := writer.Add(ctx, "rows", height, batchRows(height, "v"), new(batchRow)); err != nil {
+					t.Fatalf("add %d: %v", height, err)
+				}
+			}
+			if err := writer.Flush(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := store.FilesWithPrefix("rows/"); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Fatalf("files = %v, want %v", got, tt.wantFiles)
+			}
+			for height := uint64(0); height < 4; height++ {
+				rows, err := writer.Read(ctx, "rows", height, new(batchRow))
+				if err != nil {
+					t.Fatalf("read %d: %v", height, err)
+				}
+				if got, want := values(rows), values(batchRows(height, "v")); !reflect.DeepEqual(got, want) {
+					t.Errorf("height %d = %v, want %v", height, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchWriterReplace(t *testing.T) {
+	tests := []struct {
+		name      string
+		heights   []uint64
+		replace   uint64
+		wantFiles []string
+	}{
+		{
+			name:    "buffered height",
+			heights: []uint64{0, 1, 2},
+			replace: 2,
+		},
+		{
+			name:      "written height",
+			heights:   []uint64{0, 1, 2, 3},
+			replace:   2,
+			wantFiles: []string{"rows/blocks_0-7/0-3.parquet"},
+		},
+		{
+			name:      "written height without rows",
+			heights:   []uint64{0, 1, 2, 3},
+			replace:   3,
+			wantFiles: []string{"rows/blocks_0-7/0-3.parquet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemoryConnector(8)
+			writer, err := NewBatchWriter(store, BatchConfig{BatchSize: 4, DirectoryRange: 8})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, height := range tt.heights {
+				if err := writer.Add(ctx, "rows", height, batchRows(height, "old"), new(batchRow)); err != nil {
+					t.Fatalf("add %d: %v", height, err)
+				}
+			}
+
+			replacement := []interface{}{&batchRow{Height: int64(tt.replace), Value: "new"}}
+			if err := writer.Replace(ctx, "rows", tt.replace, replacement, new(batchRow)); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := store.FilesWithPrefix("rows/"); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Fatalf("files = %v, want %v", got, tt.wantFiles)
+			}
+			for _, height := range tt.heights {
+				want := values(batchRows(height, "old"))
+				if height == tt.replace {
+					want = values(replacement)
+				}
+				rows, err := writer.Read(ctx, "rows", height, new(batchRow))
+				if err != nil {
+					t.Fatalf("read %d: %v", height, err)
+				}
+				if got := values(rows); !reflect.DeepEqual(got, want) {
+					t.Errorf("height %d = %v, want %v", height, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchWriterExists(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryConnector(8)
+	writer, err := NewBatchWriter(store, BatchConfig{BatchSize: 4, DirectoryRange: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//	Heights 0-3 are written as a batch, and 4 is left buffered
+	for _, height := range []uint64{0, 1, 2, 3, 4} {
+		if err := writer.Add(ctx, "rows", height, batchRows(height, "v"), new(batchRow)); err != nil {
+			t.Fatalf("add %d: %v", height, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		entity string
+		height uint64
+		want   bool
+	}{
+		{name: "written", entity: "rows", height: 1, want: true},
+		{name: "written without rows", entity: "rows", height: 3, want: true},
+		{name: "buffered", entity: "rows", height: 4, want: true},
+		{name: "not added", entity: "rows", height: 5},
+		{name: "other entity", entity: "others", height: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := writer.Exists(ctx, tt.entity, tt.height)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Exists(%s, %d) = %v, want %v", tt.entity, tt.height, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWriteMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{mode: WriteModeBlock},
+		{mode: WriteModeBatch},
+		{mode: "", wantErr: true},
+		{mode: "batched", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := ValidateWriteMode(tt.mode); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateWriteMode(%q) = %v, want error %v", tt.mode, err, tt.wantErr)
+		}
+	}
+}
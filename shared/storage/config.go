@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"github.com/caarlos0/env/v7"
+	framework "github.com/coherentopensource/go-service-framework/util"
+	"github.com/pkg/errors"
+)
+
+// Supported values for STORAGE_BACKEND
+const (
+	BackendGCS   = "gcs"
+	BackendS3    = "s3"
+	BackendLocal = "local"
+)
+
+// Config selects which Store implementation backs the pipeline; backend-specific settings are parsed from their own
+// config structs once the backend is known
+type Config struct {
+	Backend string `env:"STORAGE_BACKEND" envDefault:"gcs"`
+}
+
+// NewStore parses the storage config from environment variables and constructs the selected Store
+func NewStore(ctx context.Context) (Store, error) {
+	var cfg Config
+	if err := env.Parse(&cfg); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Backend {
+	case BackendGCS:
+		var gcsCfg GCSConfig
+		if err := env.Parse(&gcsCfg); err != nil {
+			return nil, err
+		}
+		return NewGCSConnector(ctx, &gcsCfg)
+	case BackendS3:
+		var s3Cfg S3Config
+		if err := env.Parse(&s3Cfg); err != nil {
+			return nil, err
+		}
+		return NewS3Connector(ctx, &s3Cfg)
+	case BackendLocal:
+		var localCfg LocalFSConfig
+		if err := env.Parse(&localCfg); err != nil {
+			return nil, err
+		}
+		return NewLocalFSConnector(ctx, &localCfg)
+	default:
+		return nil, errors.Errorf("unsupported storage backend: %s", cfg.Backend)
+	}
+}
+
+// MustNewStore constructs the configured Store, with fatal exit on error
+func MustNewStore(ctx context.Context, logger framework.Logger) Store {
+	store, err := NewStore(ctx)
+	if err != nil {
+		logger.Fatalf("Could not instantiate storage backend: %v", err)
+	}
+
+	return store
+}
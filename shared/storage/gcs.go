@@ -6,8 +6,8 @@ import (
 	framework "github.com/coherentopensource/go-service-framework/util"
 	"github.com/pkg/errors"
 	"github.com/xitongsys/parquet-go-source/gcs"
-	"github.com/xitongsys/parquet-go/parquet"
-	"github.com/xitongsys/parquet-go/writer"
+	"github.com/xitongsys/parquet-go/source"
+	"google.golang.org/api/iterator"
 )
 
 type GCSConnector struct {
@@ -24,10 +24,16 @@ type GCSConfig struct {
 }
 
 func NewGCSConnector(ctx context.Context, cfg *GCSConfig) (*GCSConnector, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, errors.Errorf("cannot create GCS client: %v", err)
+	}
+
 	return &GCSConnector{
 		bucketName: cfg.BucketName,
 		projectID:  cfg.ProjectID,
 		rangeSize:  cfg.RangeSize,
+		bucket:     client.Bucket(cfg.BucketName),
 	}, nil
 }
 
@@ -63,20 +69,8 @@ func (g *GCSConnector) WriteOne(ctx context.Context, input interface{}, mapToStr
 		}
 	}()
 
-	pw, err := writer.NewParquetWriter(gw, mapToStruct, 4)
-	if err != nil {
-		return errors.Errorf("cannot create parquet writer: %v", err)
-	}
-
-	pw.CompressionType = parquet.CompressionCodec_SNAPPY
-	if err = pw.Write(input); err != nil {
-		return errors.Errorf("write error: %v", err)
-	}
-
-	if err = pw.WriteStop(); err != nil {
-		return errors.Errorf("WriteStop error: %v", err)
-	}
-	return nil
+	err = writeRows(gw, []interface{}{input}, mapToStruct)
+	return err
 }
 
 // Write writes a mutliple parquets to GCS storage
@@ -102,20 +96,75 @@ func (g *GCSConnector) WriteMany(ctx context.Context, input []interface{}, mapTo
 		}
 	}()
 
-	pw, err := writer.NewParquetWriter(gw, mapToStruct, 4)
+	err = writeRows(gw, input, mapToStruct)
+	return err
+}
+
+// NewFileReader opens a parquet object in the bucket for reading
+func (g *GCSConnector) NewFileReader(ctx context.Context, filename string) (source.ParquetFile, error) {
+	return gcs.NewGcsFileReader(ctx, g.projectID, g.bucketName, filename)
+}
+
+// ReadMany reads every row of a parquet object into output, a pointer to a slice of the model type
+func (g *GCSConnector) ReadMany(ctx context.Context, filename string, mapToStruct interface{}, output interface{}) error {
+	fr, err := g.NewFileReader(ctx, filename)
+	if err != nil {
+		return errors.Errorf("cannot open file: %v", err)
+	}
+	defer fr.Close()
+
+	return readRows(fr, mapToStruct, output)
+}
+
+// Stat returns metadata for an object, or ErrNotExist if there is none
+func (g *GCSConnector) Stat(ctx context.Context, filename string) (*FileInfo, error) {
+	attrs, err := g.bucket.Object(filename).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrNotExist
+	}
 	if err != nil {
-		return errors.Errorf("cannot create parquet writer: %v", err)
+		return nil, err
 	}
 
-	pw.CompressionType = parquet.CompressionCodec_SNAPPY
-	for _, row := range input {
-		if err = pw.Write(row); err != nil {
-			return errors.Errorf("write error: %v", err)
+	return &FileInfo{Name: attrs.Name, Size: attrs.Size, Updated: attrs.Updated}, nil
+}
+
+// Exists checks whether an object is present in the bucket
+func (g *GCSConnector) Exists(ctx context.Context, filename string) (bool, error) {
+	return exists(g.Stat(ctx, filename))
+}
+
+// List returns every object whose name starts with prefix, in lexical order
+func (g *GCSConnector) List(ctx context.Context, prefix string) ([]FileInfo, error) {
+	var files []FileInfo
+	it := g.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, errors.Errorf("cannot list %s: %v", prefix, err)
 		}
+		files = append(files, FileInfo{Name: attrs.Name, Size: attrs.Size, Updated: attrs.Updated})
+	}
+
+	return files, nil
+}
+
+// Copy duplicates an object server-side
+func (g *GCSConnector) Copy(ctx context.Context, src string, dst string) error {
+	if _, err := g.bucket.Object(dst).CopierFrom(g.bucket.Object(src)).Run(ctx); err != nil {
+		return errors.Errorf("cannot copy %s to %s: %v", src, dst, err)
 	}
+	return nil
+}
 
-	if err = pw.WriteStop(); err != nil {
-		return errors.Errorf("WriteStop error: %v", err)
+// Delete removes an object; deleting an object that does not exist is not an error
+func (g *GCSConnector) Delete(ctx context.Context, filename string) error {
+	err := g.bucket.Object(filename).Delete(ctx)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return errors.Errorf("cannot delete %s: %v", filename, err)
 	}
 	return nil
 }
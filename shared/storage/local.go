@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	framework "github.com/coherentopensource/go-service-framework/util"
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/source"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// tmpSuffix is appended to files while they are being written, so that readers never see a partial parquet
+	tmpSuffix = ".tmp"
+)
+
+// LocalFSConnector is a Store that writes parquet files to a directory on local disk, using the same layout as the
+// object storage connectors
+type LocalFSConnector struct {
+	rootDir   string
+	rangeSize uint64
+}
+
+type LocalFSConfig struct {
+	RootDir   string `env:"LOCAL_STORAGE_ROOT,required"`
+	RangeSize uint64 `env:"LOCAL_DIR_RANGE_SIZE" envDefault:"10000"`
+}
+
+func NewLocalFSConnector(ctx context.Context, cfg *LocalFSConfig) (*LocalFSConnector, error) {
+	rootDir, err := filepath.Abs(cfg.RootDir)
+	if err != nil {
+		return nil, errors.Errorf("cannot resolve storage root %s: %v", cfg.RootDir, err)
+	}
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		return nil, errors.Errorf("cannot create storage root %s: %v", rootDir, err)
+	}
+
+	return &LocalFSConnector{
+		rootDir:   rootDir,
+		rangeSize: cfg.RangeSize,
+	}, nil
+}
+
+func MustNewLocalFSConnector(ctx context.Context, cfg *LocalFSConfig, logger framework.Logger) *LocalFSConnector {
+	client, err := NewLocalFSConnector(ctx, cfg)
+	if err != nil {
+		logger.Fatalf("Could not instantiate local filesystem store: %v", err)
+	}
+
+	return client
+}
+
+// WriteOne writes a single parquet to local disk
+func (l *LocalFSConnector) WriteOne(ctx context.Context, input interface{}, mapToStruct interface{}, filename string) error {
+	return l.write([]interface{}{input}, mapToStruct, filename)
+}
+
+// WriteMany writes multiple parquets to local disk
+func (l *LocalFSConnector) WriteMany(ctx context.Context, input []interface{}, mapToStruct interface{}, filename string) error {
+	return l.write(input, mapToStruct, filename)
+}
+
+// write encodes rows into a temporary file next to the destination and renames it into place once complete
+func (l *LocalFSConnector) write(input []interface{}, mapToStruct interface{}, filename string) error {
+	dstPath := l.path(filename)
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return errors.Errorf("cannot create directory for %s: %v", filename, err)
+	}
+
+	tmpPath := dstPath + tmpSuffix
+	fw, err := local.NewLocalFileWriter(tmpPath)
+	if err != nil {
+		return errors.Errorf("cannot open file: %v", err)
+	}
+
+	if err := writeRows(fw, input, mapToStruct); err != nil {
+		fw.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := fw.Close(); err != nil {
+		os.Remove(tmpPath)
+		return errors.Errorf("LocalFile Close error: %v", err)
+	}
+
+	if err := os.Rename(tmpPath, dstPath); err != nil {
+		return errors.Errorf("cannot move %s into place: %v", filename, err)
+	}
+	return nil
+}
+
+// NewFileReader opens a parquet file beneath the root directory for reading
+func (l *LocalFSConnector) NewFileReader(ctx context.Context, filename string) (source.ParquetFile, error) {
+	return local.NewLocalFileReader(l.path(filename))
+}
+
+// ReadMany reads every row of a parquet file into output, a pointer to a slice of the model type
+func (l *LocalFSConnector) ReadMany(ctx context.Context, filename string, mapToStruct interface{}, output interface{}) error {
+	fr, err := l.NewFileReader(ctx, filename)
+	if err != nil {
+		return errors.Errorf("cannot open file: %v", err)
+	}
+	defer fr.Close()
+
+	return readRows(fr, mapToStruct, output)
+}
+
+// Stat returns metadata for a file, or ErrNotExist if there is none
+func (l *LocalFSConnector) Stat(ctx context.Context, filename string) (*FileInfo, error) {
+	info, err := os.Stat(l.path(filename))
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileInfo{Name: filename, Size: info.Size(), Updated: info.ModTime()}, nil
+}
+
+// Exists checks whether a file is present beneath the root directory
+func (l *LocalFSConnector) Exists(ctx context.Context, filename string) (bool, error) {
+	return exists(l.Stat(ctx, filename))
+}
+
+// List returns every file whose slash-separated name starts with prefix, in lexical order; files still being written
+// are not included
+func (l *LocalFSConnector) List(ctx context.Context, prefix string) ([]FileInfo, error) {
+	//	Walk from the deepest directory the prefix names, then filter on the full prefix
+	walkRoot := l.rootDir
+	if dir := path.Dir(prefix); dir != "." {
+		walkRoot = l.path(dir)
+	}
+
+	var files []FileInfo
+	err := filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(p, tmpSuffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.rootDir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, FileInfo{Name: name, Size: info.Size(), Updated: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Errorf("cannot list %s: %v", prefix, err)
+	}
+
+	return files, nil
+}
+
+// Copy duplicates a file, going through a temporary file in the same way as writes
+func (l *LocalFSConnector) Copy(ctx context.Context, src string, dst string) error {
+	data, err := os.ReadFile(l.path(src))
+	if err != nil {
+		return errors.Errorf("cannot read %s: %v", src, err)
+	}
+
+	dstPath := l.path(dst)
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return errors.Errorf("cannot create directory for %s: %v", dst, err)
+	}
+	if err := os.WriteFile(dstPath+tmpSuffix, data, 0o644); err != nil {
+		os.Remove(dstPath + tmpSuffix)
+		return errors.Errorf("cannot copy %s to %s: %v", src, dst, err)
+	}
+	if err := os.Rename(dstPath+tmpSuffix, dstPath); err != nil {
+		return errors.Errorf("cannot move %s into place: %v", dst, err)
+	}
+	return nil
+}
+
+// Delete removes a file; deleting a file that does not exist is not an error
+func (l *LocalFSConnector) Delete(ctx context.Context, filename string) error {
+	if err := os.Remove(l.path(filename)); err != nil && !os.IsNotExist(err) {
+		return errors.Errorf("cannot delete %s: %v", filename, err)
+	}
+	return nil
+}
+
+// path maps a slash-separated object name onto a path beneath the root directory
+func (l *LocalFSConnector) path(filename string) string {
+	return filepath.Join(l.rootDir, filepath.FromSlash(filename))
+}
+
+func (l *LocalFSConnector) ProjectID() string {
+	return ""
+}
+
+// Bucket returns the root directory, which plays the role of the bucket for local storage
+func (l *LocalFSConnector) Bucket() string {
+	return l.rootDir
+}
+
+func (l *LocalFSConnector) RangeSize() uint64 {
+	return l.rangeSize
+}
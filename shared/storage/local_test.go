@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// badRow has a column type parquet-go cannot write
+type badRow struct {
+	Height int64 `parquet:"name=height, type=NOT_A_TYPE"`
+}
+
+func newTestLocalFS(t *testing.T) *LocalFSConnector {
+	t.Helper()
+	store, err := NewLocalFSConnector(context.Background(), &LocalFSConfig{RootDir: t.TempDir(), RangeSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestLocalFSWriteReplacesWhole(t *testing.T) {
+	ctx := context.Background()
+	store := newTestLocalFS(t)
+	filename := "blocks/0-9/1.parquet"
+
+	if err := store.WriteMany(ctx, batchRows(2, "a"), new(batchRow), filename); err != nil {
+		t.Fatal(err)
+	}
+	//	A write that fails leaves the file as it was, and nothing of its own behind
+	if err := store.WriteMany(ctx, batchRows(2, "b"), new(badRow), filename); err == nil {
+		t.Fatal("wrote rows with an invalid model")
+	}
+
+	entries, err := os.ReadDir(filepath.Join(store.Bucket(), "blocks", "0-9"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"1.parquet"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("directory holds %v, want %v", names, want)
+	}
+	rows, err := ReadAll(ctx, store, filename, new(batchRow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := values(rows), []string{"2:a", "2:a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+
+	if err := store.WriteMany(ctx, batchRows(4, "c"), new(batchRow), filename); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = ReadAll(ctx, store, filename, new(batchRow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := values(rows), []string{"4:c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rows after rewrite = %v, want %v", got, want)
+	}
+}
+
+func TestLocalFSList(t *testing.T) {
+	ctx := context.Background()
+	store := newTestLocalFS(t)
+	for _, filename := range []string{
+		"blocks/10-19/12.parquet",
+		"blocks/0-9/1.parquet",
+		"blocks/0-9/2.parquet",
+		"blocks_v2/0-9/1.parquet",
+		"logs/0-9/1.parquet",
+	} {
+		if err := store.WriteOne(ctx, &batchRow{Height: 1}, new(batchRow), filename); err != nil {
+			t.Fatal(err)
+		}
+	}
+	//	A write interrupted by a crash leaves its temporary file behind
+	if err := os.WriteFile(filepath.Join(store.Bucket(), "blocks", "0-9", "3.parquet"+tmpSuffix), []byte("PAR1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{
+			name:   "entity",
+			prefix: "blocks/",
+			want:   []string{"blocks/0-9/1.parquet", "blocks/0-9/2.parquet", "blocks/10-19/12.parquet"},
+		},
+		{
+			name:   "range directory",
+			prefix: "blocks/0-9/",
+			want:   []string{"blocks/0-9/1.parquet", "blocks/0-9/2.parquet"},
+		},
+		{
+			//	A prefix ending part way through a name matches on the name, not the directory
+			name:   "part of a name",
+			prefix: "blocks",
+			want:   []string{"blocks/0-9/1.parquet", "blocks/0-9/2.parquet", "blocks/10-19/12.parquet", "blocks_v2/0-9/1.parquet"},
+		},
+		{
+			name:   "part of a file name",
+			prefix: "blocks/0-9/2",
+			want:   []string{"blocks/0-9/2.parquet"},
+		},
+		{
+			name:   "missing directory",
+			prefix: "traces/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := store.List(ctx, tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, file := range files {
+				names = append(names, file.Name)
+				if file.Size == 0 {
+					t.Errorf("%s has no size", file.Name)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("files = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
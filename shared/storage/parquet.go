@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+	"reflect"
+	"strings"
+)
+
+const (
+	// parallelism is the number of goroutines used by parquet readers and writers
+	parallelism = 4
+)
+
+// writeRows encodes a set of rows as a single parquet file onto the given file handle; every Store implementation
+// goes through here, so that output is byte-for-byte comparable regardless of backend
+func writeRows(fw source.ParquetFile, input []interface{}, mapToStruct interface{}) error {
+	pw, err := writer.NewParquetWriter(fw, mapToStruct, parallelism)
+	if err != nil {
+		return errors.Errorf("cannot create parquet writer: %v", err)
+	}
+
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	for _, row := range input {
+		if err = pw.Write(row); err != nil {
+			return errors.Errorf("write error: %v", err)
+		}
+	}
+
+	if err = pw.WriteStop(); err != nil {
+		return errors.Errorf("WriteStop error: %v", err)
+	}
+	return nil
+}
+
+// readRows decodes every row of a parquet file into output, which must be a pointer to a slice of the model type
+// described by mapToStruct
+func readRows(fr source.ParquetFile, mapToStruct interface{}, output interface{}) (err error) {
+	//	parquet-go panics rather than failing when the file's schema does not match the model, e.g. when reading a file
+	//	written before a column was added
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("cannot read file as %T: %v", mapToStruct, r)
+		}
+	}()
+
+	dst := reflect.ValueOf(output)
+	if dst.Kind() != reflect.Ptr || dst.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("output must be a pointer to a slice, got %T", output)
+	}
+
+	pr, err := reader.NewParquetReader(fr, mapToStruct, parallelism)
+	if err != nil {
+		return errors.Errorf("cannot create parquet reader: %v", err)
+	}
+	defer pr.ReadStop()
+
+	//	The reader fills as many rows as the destination can hold, so size it to the file first
+	numRows := int(pr.GetNumRows())
+	dst.Elem().Set(reflect.MakeSlice(dst.Elem().Type(), numRows, numRows))
+	if err = pr.Read(output); err != nil {
+		return errors.Errorf("read error: %v", err)
+	}
+	return nil
+}
+
+// CountRows returns the number of rows recorded in a parquet file's footer, without decoding any of them
+func CountRows(ctx context.Context, store Store, filename string) (int64, error) {
+	fr, err := store.NewFileReader(ctx, filename)
+	if err != nil {
+		return 0, errors.Errorf("cannot open file: %v", err)
+	}
+	defer fr.Close()
+
+	pr, err := reader.NewParquetReader(fr, nil, 1)
+	if err != nil {
+		return 0, errors.Errorf("cannot create parquet reader: %v", err)
+	}
+	defer pr.ReadStop()
+
+	return pr.GetNumRows(), nil
+}
+
+// ReadAll reads every row of a parquet file as pointers to the model type described by mapToStruct
+func ReadAll(ctx context.Context, store Store, filename string, mapToStruct interface{}) ([]interface{}, error) {
+	rows := reflect.New(reflect.SliceOf(reflect.TypeOf(mapToStruct).Elem()))
+	if err := store.ReadMany(ctx, filename, mapToStruct, rows.Interface()); err != nil {
+		return nil, err
+	}
+
+	output := make([]interface{}, rows.Elem().Len())
+	for i := range output {
+		output[i] = rows.Elem().Index(i).Addr().Interface()
+	}
+	return output, nil
+}
+
+// MissingFields returns the fields of a model whose columns a parquet file lacks, as a file written before they were
+// added does
+func MissingFields(ctx context.Context, store Store, filename string, mapToStruct interface{}) ([]string, error) {
+	fr, err := store.NewFileReader(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fr.Close()
+
+	pr, err := reader.NewParquetReader(fr, nil, 1)
+	if err != nil {
+		return nil, errors.Errorf("cannot create parquet reader: %v", err)
+	}
+	defer pr.ReadStop()
+
+	//	The schema also names the elements of list columns, which no model field is written to
+	columns := make(map[string]bool)
+	for _, element := range pr.Footer.Schema {
+		columns[strings.ToLower(element.GetName())] = true
+	}
+
+	var missing []string
+	modelType := reflect.TypeOf(mapToStruct).Elem()
+	for i := 0; i < modelType.NumField(); i++ {
+		if !columns[strings.ToLower(columnName(modelType.Field(i)))] {
+			missing = append(missing, modelType.Field(i).Name)
+		}
+	}
+	return missing, nil
+}
+
+// columnName returns the name of the column a model field is written to
+func columnName(field reflect.StructField) string {
+	for _, option := range strings.Split(field.Tag.Get("parquet"), ",") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(option), "name="); ok {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Project returns a model holding only the given fields of mapToStruct, for reading some of a file's columns
+func Project(mapToStruct interface{}, fields ...string) interface{} {
+	modelType := reflect.TypeOf(mapToStruct).Elem()
+	var projected []reflect.StructField
+	for _, name := range fields {
+		if field, ok := modelType.FieldByName(name); ok {
+			projected = append(projected, field)
+		}
+	}
+	return reflect.New(reflect.StructOf(projected)).Interface()
+}
+
+// ReadCompatible reads every row of a parquet file like ReadAll, including a file written before some of the model's
+// columns were added, whose fields are left zero; it also returns the fields the file lacked
+func ReadCompatible(ctx context.Context, store Store, filename string, mapToStruct interface{}) ([]interface{}, []string, error) {
+	missing, err := MissingFields(ctx, store, filename, mapToStruct)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(missing) == 0 {
+		rows, err := ReadAll(ctx, store, filename, mapToStruct)
+		return rows, nil, err
+	}
+
+	absent := make(map[string]bool, len(missing))
+	for _, name := range missing {
+		absent[name] = true
+	}
+	modelType := reflect.TypeOf(mapToStruct).Elem()
+	var present []string
+	for i := 0; i < modelType.NumField(); i++ {
+		if !absent[modelType.Field(i).Name] {
+			present = append(present, modelType.Field(i).Name)
+		}
+	}
+
+	legacyRows, err := ReadAll(ctx, store, filename, Project(mapToStruct, present...))
+	if err != nil {
+		return nil, nil, err
+	}
+	rows := make([]interface{}, len(legacyRows))
+	for i, legacyRow := range legacyRows {
+		src := reflect.ValueOf(legacyRow).Elem()
+		dst := reflect.New(modelType)
+		for j, name := range present {
+			dst.Elem().FieldByName(name).Set(src.Field(j))
+		}
+		rows[i] = dst.Interface()
+	}
+	return rows, missing, nil
+}
@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	framework "github.com/coherentopensource/go-service-framework/util"
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	s3source "github.com/xitongsys/parquet-go-source/s3"
+	"github.com/xitongsys/parquet-go/source"
+	"net/url"
+)
+
+// S3Connector is a Store backed by S3 or any S3-compatible object store (e.g. MinIO)
+type S3Connector struct {
+	bucketName string
+	acl        string
+	rangeSize  uint64
+	client     *s3.S3
+}
+
+// S3Config holds connection settings for S3; leaving the static credentials empty falls back to the default AWS
+// credential chain (env, shared config, instance role)
+type S3Config struct {
+	BucketName      string `env:"S3_BUCKET_NAME,required"`
+	Region          string `env:"S3_REGION" envDefault:"us-east-1"`
+	Endpoint        string `env:"S3_ENDPOINT"`
+	ForcePathStyle  bool   `env:"S3_FORCE_PATH_STYLE" envDefault:"false"`
+	DisableSSL      bool   `env:"S3_DISABLE_SSL" envDefault:"false"`
+	AccessKeyID     string `env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY"`
+	SessionToken    string `env:"S3_SESSION_TOKEN"`
+	ACL             string `env:"S3_OBJECT_ACL" envDefault:"bucket-owner-full-control"`
+	RangeSize       uint64 `env:"S3_DIR_RANGE_SIZE" envDefault:"10000"`
+}
+
+func NewS3Connector(ctx context.Context, cfg *S3Config) (*S3Connector, error) {
+	awsCfg := aws.NewConfig().
+		WithRegion(cfg.Region).
+		WithS3ForcePathStyle(cfg.ForcePathStyle).
+		WithDisableSSL(cfg.DisableSSL)
+	if cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint)
+	}
+	if cfg.AccessKeyID != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken))
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, errors.Errorf("cannot create S3 session: %v", err)
+	}
+
+	return &S3Connector{
+		bucketName: cfg.BucketName,
+		acl:        cfg.ACL,
+		rangeSize:  cfg.RangeSize,
+		client:     s3.New(sess),
+	}, nil
+}
+
+func MustNewS3Connector(ctx context.Context, cfg *S3Config, logger framework.Logger) *S3Connector {
+	client, err := NewS3Connector(ctx, cfg)
+	if err != nil {
+		logger.Fatalf("Could not instantiate S3 client: %v", err)
+	}
+
+	return client
+}
+
+// WriteOne writes a single parquet to S3 storage
+func (s *S3Connector) WriteOne(ctx context.Context, input interface{}, mapToStruct interface{}, filename string) error {
+	return s.write(ctx, []interface{}{input}, mapToStruct, filename)
+}
+
+// WriteMany writes multiple parquets to S3 storage
+func (s *S3Connector) WriteMany(ctx context.Context, input []interface{}, mapToStruct interface{}, filename string) error {
+	return s.write(ctx, input, mapToStruct, filename)
+}
+
+// write encodes rows in memory and uploads them in a single PutObject, so a failed encode never leaves a partial
+// object behind
+func (s *S3Connector) write(ctx context.Context, input []interface{}, mapToStruct interface{}, filename string) error {
+	buf := buffer.NewBufferFile()
+	if err := writeRows(buf, input, mapToStruct); err != nil {
+		return err
+	}
+
+	if _, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(filename),
+		ACL:    aws.String(s.acl),
+		Body:   bytes.NewReader(buf.Bytes()),
+	}); err != nil {
+		return errors.Errorf("cannot upload %s: %v", filename, err)
+	}
+	return nil
+}
+
+// NewFileReader opens a parquet object in the bucket for reading
+func (s *S3Connector) NewFileReader(ctx context.Context, filename string) (source.ParquetFile, error) {
+	return s3source.NewS3FileReaderWithClient(ctx, s.client, s.bucketName, filename)
+}
+
+// ReadMany reads every row of a parquet object into output, a pointer to a slice of the model type
+func (s *S3Connector) ReadMany(ctx context.Context, filename string, mapToStruct interface{}, output interface{}) error {
+	fr, err := s.NewFileReader(ctx, filename)
+	if err != nil {
+		return errors.Errorf("cannot open file: %v", err)
+	}
+	defer fr.Close()
+
+	return readRows(fr, mapToStruct, output)
+}
+
+// Stat returns metadata for an object, or ErrNotExist if there is none
+func (s *S3Connector) Stat(ctx context.Context, filename string) (*FileInfo, error) {
+	out, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(filename),
+	})
+	if isS3NotFound(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileInfo{
+		Name:    filename,
+		Size:    aws.Int64Value(out.ContentLength),
+		Updated: aws.TimeValue(out.LastModified),
+	}, nil
+}
+
+// Exists checks whether an object is present in the bucket
+func (s *S3Connector) Exists(ctx context.Context, filename string) (bool, error) {
+	return exists(s.Stat(ctx, filename))
+}
+
+// List returns every object whose key starts with prefix, in lexical order
+func (s *S3Connector) List(ctx context.Context, prefix string) ([]FileInfo, error) {
+	var files []FileInfo
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			files = append(files, FileInfo{
+				Name:    aws.StringValue(object.Key),
+				Size:    aws.Int64Value(object.Size),
+				Updated: aws.TimeValue(object.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Errorf("cannot list %s: %v", prefix, err)
+	}
+
+	return files, nil
+}
+
+// Copy duplicates an object server-side
+func (s *S3Connector) Copy(ctx context.Context, src string, dst string) error {
+	if _, err := s.client.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(s.bucketName),
+		Key:        aws.String(dst),
+		CopySource: aws.String((&url.URL{Path: s.bucketName + "/" + src}).EscapedPath()),
+		ACL:        aws.String(s.acl),
+	}); err != nil {
+		return errors.Errorf("cannot copy %s to %s: %v", src, dst, err)
+	}
+	return nil
+}
+
+// Delete removes an object; deleting an object that does not exist is not an error
+func (s *S3Connector) Delete(ctx context.Context, filename string) error {
+	if _, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(filename),
+	}); err != nil && !isS3NotFound(err) {
+		return errors.Errorf("cannot delete %s: %v", filename, err)
+	}
+	return nil
+}
+
+func (s *S3Connector) ProjectID() string {
+	return ""
+}
+
+func (s *S3Connector) Bucket() string {
+	return s.bucketName
+}
+
+func (s *S3Connector) RangeSize() uint64 {
+	return s.rangeSize
+}
+
+// isS3NotFound reports whether err is S3's missing-object error; HEAD responses carry no body, so the code comes
+// back as a bare "NotFound" rather than NoSuchKey
+func isS3NotFound(err error) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	return aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == "NotFound"
+}
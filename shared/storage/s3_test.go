@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"net/http"
+	"testing"
+)
+
+func TestIsS3NotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "no such key",
+			err:  awserr.NewRequestFailure(awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil), http.StatusNotFound, "req"),
+			want: true,
+		},
+		{
+			//	HEAD responses have no body to carry NoSuchKey
+			name: "head not found",
+			err:  awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), http.StatusNotFound, "req"),
+			want: true,
+		},
+		{
+			name: "wrapped",
+			err:  errors.Wrap(awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil), "cannot read blocks/0-9999/1.parquet"),
+			want: true,
+		},
+		{
+			name: "access denied",
+			err:  awserr.NewRequestFailure(awserr.New("AccessDenied", "Access Denied", nil), http.StatusForbidden, "req"),
+		},
+		{
+			name: "no such bucket",
+			err:  awserr.New(s3.ErrCodeNoSuchBucket, "The specified bucket does not exist", nil),
+		},
+		{
+			name: "not an s3 error",
+			err:  errors.New("connection reset by peer"),
+		},
+		{
+			name: "no error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isS3NotFound(tt.err); got != tt.want {
+				t.Errorf("isS3NotFound(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
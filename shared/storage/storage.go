@@ -2,12 +2,43 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"github.com/xitongsys/parquet-go/source"
+	"time"
 )
 
+// ErrNotExist is returned by Stat when the requested file is not present in the store
+var ErrNotExist = errors.New("file does not exist")
+
 type Store interface {
 	WriteOne(ctx context.Context, input interface{}, mapToStruct interface{}, filename string) error
 	WriteMany(ctx context.Context, input []interface{}, mapToStruct interface{}, filename string) error
+	NewFileReader(ctx context.Context, filename string) (source.ParquetFile, error)
+	ReadMany(ctx context.Context, filename string, mapToStruct interface{}, output interface{}) error
+	Stat(ctx context.Context, filename string) (*FileInfo, error)
+	Exists(ctx context.Context, filename string) (bool, error)
+	List(ctx context.Context, prefix string) ([]FileInfo, error)
+	Copy(ctx context.Context, src string, dst string) error
+	Delete(ctx context.Context, filename string) error
 	ProjectID() string
 	Bucket() string
 	RangeSize() uint64
 }
+
+// FileInfo describes a file held in a Store
+type FileInfo struct {
+	Name    string
+	Size    int64
+	Updated time.Time
+}
+
+// exists adapts the result of a Stat call into a presence check
+func exists(_ *FileInfo, err error) (bool, error) {
+	if errors.Is(err, ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
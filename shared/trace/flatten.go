@@ -0,0 +1,78 @@
+// Package trace flattens the nested call trees returned by debug_traceBlockByNumber's callTracer into one row per
+// call frame, recording where each frame sits in its tree.
+package trace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Node is a call frame with nested calls; the CallTrace proto of every chain satisfies it
+type Node[T any] interface {
+	GetCalls() []T
+}
+
+// Frame is a single call frame of a flattened tree, along with its position in the tree
+type Frame[T Node[T]] struct {
+	// Call is the frame's own call, whose data belongs on this frame's row only
+	Call T
+	// Hash identifies the frame, as derived by ID; ParentHash is the hash of the frame that made the call, or empty
+	// for the root
+	Hash       string
+	ParentHash string
+	// Index is the frame's position among its siblings, or the transaction's position for a root frame
+	Index int64
+	// TraceAddress is the parity-style path of sibling indexes from the root, which is empty for the root itself
+	TraceAddress []int64
+	// Depth is the number of calls between the frame and the root
+	Depth int64
+	// Subtraces is the number of calls the frame makes directly
+	Subtraces int64
+}
+
+// Flatten walks the call tree of a single transaction and returns every frame in depth-first pre-order, which is
+// the order parity's trace_ API returns them in
+func Flatten[T Node[T]](root T, rootIndex int64, blockHash string, txHash string) []Frame[T] {
+	var frames []Frame[T]
+
+	var visit func(call T, index int64, parentHash string, address []int64)
+	visit = func(call T, index int64, parentHash string, address []int64) {
+		calls := call.GetCalls()
+		frame := Frame[T]{
+			Call:         call,
+			Hash:         ID(blockHash, txHash, address),
+			ParentHash:   parentHash,
+			Index:        index,
+			TraceAddress: address,
+			Depth:        int64(len(address)),
+			Subtraces:    int64(len(calls)),
+		}
+		frames = append(frames, frame)
+
+		for i, child := range calls {
+			//	Copy the path so that siblings never share a backing array
+			childAddress := make([]int64, len(address)+1)
+			copy(childAddress, address)
+			childAddress[len(address)] = int64(i)
+			visit(child, int64(i), frame.Hash, childAddress)
+		}
+	}
+	visit(root, rootIndex, "", []int64{})
+
+	return frames
+}
+
+// ID derives a frame's identifier from its position alone, so that it is stable across re-ingests and distinct for
+// identical sibling calls: the hex-encoded SHA-256 of "<block hash>:<tx hash>:<trace address>", with both hashes
+// lower-cased and the trace address written as dot-separated indexes (empty for the root), e.g. "0xab..:0xcd..:0.2"
+func ID(blockHash string, txHash string, traceAddress []int64) string {
+	indexes := make([]string, len(traceAddress))
+	for i, index := range traceAddress {
+		indexes[i] = fmt.Sprint(index)
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s", strings.ToLower(blockHash), strings.ToLower(txHash), strings.Join(indexes, "."))))
+	return hex.EncodeToString(sum[:])
+}
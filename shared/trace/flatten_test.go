@@ -0,0 +1,164 @@
+package trace
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	testBlockHash = "0xB10C"
+	testTxHash    = "0x7A"
+)
+
+// call is a hand-built call frame; like the CallTrace protos, its getter is safe on a nil call
+type call struct {
+	name  string
+	error string
+	calls []*call
+}
+
+func (c *call) GetCalls() []*call {
+	if c == nil {
+		return nil
+	}
+	return c.calls
+}
+
+// wantFrame is where a frame should sit in its tree; parent is the position of its parent frame in the output, or -1
+// for the root
+type wantFrame struct {
+	name      string
+	index     int64
+	address   []int64
+	subtraces int64
+	parent    int
+}
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name  string
+		root  *call
+		index int64
+		want  []wantFrame
+	}{
+		{
+			name:  "no calls",
+			root:  &call{name: "root"},
+			index: 3,
+			want: []wantFrame{
+				{name: "root", index: 3, address: []int64{}, parent: -1},
+			},
+		},
+		{
+			name:  "nil root",
+			root:  nil,
+			index: 0,
+			want: []wantFrame{
+				{name: "", index: 0, address: []int64{}, parent: -1},
+			},
+		},
+		{
+			name: "nested",
+			root: &call{name: "root", calls: []*call{
+				{name: "a", calls: []*call{
+					{name: "a0"},
+					{name: "a1", calls: []*call{{name: "a1x"}}},
+				}},
+				{name: "b"},
+			}},
+			index: 1,
+			want: []wantFrame{
+				{name: "root", index: 1, address: []int64{}, subtraces: 2, parent: -1},
+				{name: "a", index: 0, address: []int64{0}, subtraces: 2, parent: 0},
+				{name: "a0", index: 0, address: []int64{0, 0}, parent: 1},
+				{name: "a1", index: 1, address: []int64{0, 1}, subtraces: 1, parent: 1},
+				{name: "a1x", index: 0, address: []int64{0, 1, 0}, parent: 3},
+				{name: "b", index: 1, address: []int64{1}, parent: 0},
+			},
+		},
+		{
+			//	A reverted call keeps its subcalls, which are positioned like any others
+			name: "reverted",
+			root: &call{name: "root", error: "execution reverted", calls: []*call{
+				{name: "x", error: "out of gas", calls: []*call{{name: "x0"}}},
+				{name: "x"},
+			}},
+			index: 0,
+			want: []wantFrame{
+				{name: "root", index: 0, address: []int64{}, subtraces: 2, parent: -1},
+				{name: "x", index: 0, address: []int64{0}, subtraces: 1, parent: 0},
+				{name: "x0", index: 0, address: []int64{0, 0}, parent: 1},
+				{name: "x", index: 1, address: []int64{1}, parent: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := Flatten(tt.root, tt.index, testBlockHash, testTxHash)
+			if len(frames) != len(tt.want) {
+				t.Fatalf("got %d frames, want %d", len(frames), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				frame := frames[i]
+				name := ""
+				if frame.Call != nil {
+					name = frame.Call.name
+				}
+				if name != want.name {
+					t.Errorf("frame %d is %q, want %q", i, name, want.name)
+				}
+				if frame.Index != want.index {
+					t.Errorf("frame %d index = %d, want %d", i, frame.Index, want.index)
+				}
+				if !reflect.DeepEqual(frame.TraceAddress, want.address) {
+					t.Errorf("frame %d trace address = %v, want %v", i, frame.TraceAddress, want.address)
+				}
+				if frame.Depth != int64(len(want.address)) {
+					t.Errorf("frame %d depth = %d, want %d", i, frame.Depth, len(want.address))
+				}
+				if frame.Subtraces != want.subtraces {
+					t.Errorf("frame %d subtraces = %d, want %d", i, frame.Subtraces, want.subtraces)
+				}
+				if frame.Hash != ID(testBlockHash, testTxHash, want.address) {
+					t.Errorf("frame %d hash = %s, want the ID of %v", i, frame.Hash, want.address)
+				}
+
+				wantParent := ""
+				if want.parent >= 0 {
+					wantParent = frames[want.parent].Hash
+				}
+				if frame.ParentHash != wantParent {
+					t.Errorf("frame %d parent hash = %q, want %q", i, frame.ParentHash, wantParent)
+				}
+			}
+		})
+	}
+}
+
+func TestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        []int64
+		b        []int64
+		aBlock   string
+		bBlock   string
+		wantSame bool
+	}{
+		{name: "same position", a: []int64{0, 1}, b: []int64{0, 1}, aBlock: "0xab", bBlock: "0xab", wantSame: true},
+		{name: "hash case", a: []int64{}, b: []int64{}, aBlock: "0xAB", bBlock: "0xab", wantSame: true},
+		{name: "siblings", a: []int64{0}, b: []int64{1}, aBlock: "0xab", bBlock: "0xab"},
+		{name: "ambiguous joins", a: []int64{1, 1}, b: []int64{11}, aBlock: "0xab", bBlock: "0xab"},
+		{name: "other block", a: []int64{0}, b: []int64{0}, aBlock: "0xab", bBlock: "0xcd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same := ID(tt.aBlock, testTxHash, tt.a) == ID(tt.bBlock, testTxHash, tt.b)
+			if same != tt.wantSame {
+				t.Errorf("IDs equal = %v, want %v", same, tt.wantSame)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package trace
+
+import (
+	"context"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// addedFields are the trace model fields introduced alongside trace_address; files written before then lack their
+// columns and must be read with a model that leaves them out
+var addedFields = map[string]bool{
+	"TraceAddress": true,
+	"Depth":        true,
+	"Subtraces":    true,
+}
+
+// ErrUnmigrated is returned for a trace file written before trace_address, which the migrator must rewrite before
+// anything else reads it with the current model
+var ErrUnmigrated = errors.New("trace file predates trace_address; run migrate-trace-ids over it first")
+
+// Unmigrated reports whether a file lacking the given model fields, as returned by storage.MissingFields, is a trace
+// file written before trace_address
+func Unmigrated(missing []string) bool {
+	for _, name := range missing {
+		if addedFields[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrator rewrites existing trace files so that trace_hash and parent_hash hold IDs as derived by ID; it works with
+// the trace model of any chain and schema version, which all share the field names used here
+type Migrator struct {
+	store       storage.Store
+	mapToStruct interface{}
+	legacy      reflect.Type
+}
+
+// NewMigrator constructs a Migrator for trace files written with the given model
+func NewMigrator(store storage.Store, mapToStruct interface{}) *Migrator {
+	modelType := reflect.TypeOf(mapToStruct).Elem()
+	var fields []reflect.StructField
+	for i := 0; i < modelType.NumField(); i++ {
+		if !addedFields[modelType.Field(i).Name] {
+			fields = append(fields, modelType.Field(i))
+		}
+	}
+
+	return &Migrator{
+		store:       store,
+		mapToStruct: mapToStruct,
+		legacy:      reflect.StructOf(fields),
+	}
+}
+
+// MigrateFile re-derives the IDs in a single trace file and rewrites it in place if anything changed; files written
+// before trace_address existed have their tree positions rebuilt from the old hash linkage first. It reports whether
+// the file was rewritten, so that running it twice is harmless
+func (m *Migrator) MigrateFile(ctx context.Context, filename string) (bool, error) {
+	rows, legacy, err := m.read(ctx, filename)
+	if err != nil {
+		return false, err
+	}
+
+	changed, err := Rederive(rows, legacy)
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot re-derive trace IDs in %s", filename)
+	}
+	if !changed {
+		return false, nil
+	}
+
+	if err := m.store.WriteMany(ctx, rows, m.mapToStruct, filename); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// read decodes a trace file with the current model, falling back to the legacy layout for files that predate
+// trace_address
+func (m *Migrator) read(ctx context.Context, filename string) ([]interface{}, bool, error) {
+	rows, err := storage.ReadAll(ctx, m.store, filename, m.mapToStruct)
+	if err == nil {
+		return rows, false, nil
+	}
+
+	legacyRows, legacyErr := storage.ReadAll(ctx, m.store, filename, reflect.New(m.legacy).Interface())
+	if legacyErr != nil {
+		return nil, false, errors.Errorf("cannot read %s: %v; as a legacy trace file: %v", filename, err, legacyErr)
+	}
+
+	modelType := reflect.TypeOf(m.mapToStruct).Elem()
+	rows = make([]interface{}, len(legacyRows))
+	for i, legacyRow := range legacyRows {
+		src := reflect.ValueOf(legacyRow).Elem()
+		dst := reflect.New(modelType)
+		for j := 0; j < src.NumField(); j++ {
+			dst.Elem().FieldByName(m.legacy.Field(j).Name).Set(src.Field(j))
+		}
+		rows[i] = dst.Interface()
+	}
+	return rows, true, nil
+}
+
+// Rederive recomputes trace_hash and parent_hash for rows of any trace model from their block hash, transaction hash
+// and trace_address, reporting whether any row changed. With legacy set, trace_address, depth and subtraces are first
+// rebuilt from the old content-hash linkage, which relies on the rows of each transaction still being in the
+// breadth-first order they were written in
+func Rederive(rows []interface{}, legacy bool) (bool, error) {
+	var order []string
+	groups := make(map[string][]reflect.Value)
+	for _, row := range rows {
+		v := reflect.ValueOf(row).Elem()
+		txHash := v.FieldByName("TransactionHash").String()
+		if _, ok := groups[txHash]; !ok {
+			order = append(order, txHash)
+		}
+		groups[txHash] = append(groups[txHash], v)
+	}
+
+	changed := false
+	for _, txHash := range order {
+		group := groups[txHash]
+		if legacy {
+			if err := rebuildTree(group); err != nil {
+				return false, errors.Wrapf(err, "transaction %s", txHash)
+			}
+			changed = true
+		}
+
+		for _, v := range group {
+			blockHash := v.FieldByName("BlockHash").String()
+			address := v.FieldByName("TraceAddress").Interface().([]int64)
+
+			hash := ID(blockHash, txHash, address)
+			parentHash := ""
+			if len(address) > 0 {
+				parentHash = ID(blockHash, txHash, address[:len(address)-1])
+			}
+
+			if v.FieldByName("Hash").String() != hash || v.FieldByName("ParentHash").String() != parentHash {
+				v.FieldByName("Hash").SetString(hash)
+				v.FieldByName("ParentHash").SetString(parentHash)
+				changed = true
+			}
+		}
+	}
+
+	return changed, nil
+}
+
+// rebuildTree recovers the tree positions of one transaction's legacy rows; identical sibling calls shared a content
+// hash, so children are matched to parents in the order both were written, with each sibling group starting at index 0
+func rebuildTree(group []reflect.Value) error {
+	addresses := make([][]int64, len(group))
+	subtraces := make([]int64, len(group))
+	//	pending holds, per hash, the rows with that hash whose children have not been reached yet
+	pending := make(map[string][]int)
+	current := make(map[string]int)
+
+	for i, v := range group {
+		parentHash := v.FieldByName("ParentHash").String()
+		index := v.FieldByName("Index").Int()
+
+		if parentHash == "" {
+			if i != 0 {
+				return errors.Errorf("found a second root trace at row %d", i)
+			}
+			addresses[i] = []int64{}
+		} else {
+			parent, ok := current[parentHash]
+			if !ok || index == 0 {
+				if len(pending[parentHash]) == 0 {
+					return errors.Errorf("trace at row %d has no parent", i)
+				}
+				parent = pending[parentHash][0]
+				pending[parentHash] = pending[parentHash][1:]
+				current[parentHash] = parent
+			}
+
+			address := make([]int64, len(addresses[parent])+1)
+			copy(address, addresses[parent])
+			address[len(address)-1] = index
+			addresses[i] = address
+			subtraces[parent]++
+		}
+
+		hash := v.FieldByName("Hash").String()
+		pending[hash] = append(pending[hash], i)
+	}
+
+	for i, v := range group {
+		v.FieldByName("TraceAddress").Set(reflect.ValueOf(addresses[i]))
+		v.FieldByName("Depth").SetInt(int64(len(addresses[i])))
+		v.FieldByName("Subtraces").SetInt(subtraces[i])
+	}
+	return nil
+}
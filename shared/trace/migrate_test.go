@@ -0,0 +1,173 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"github.com/coherentopensource/evm-etl/shared/storage"
+	"reflect"
+	"testing"
+)
+
+// testTrace has the fields of the trace models that migration reads and writes
+type testTrace struct {
+	BlockHash       string  `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	TransactionHash string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Hash            string  `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ParentHash      string  `parquet:"name=parent_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Index           int64   `parquet:"name=trace_index, type=INT64"`
+	TraceAddress    []int64 `parquet:"name=trace_address, type=MAP, convertedtype=LIST, valuetype=INT64"`
+	Depth           int64   `parquet:"name=depth, type=INT64"`
+	Subtraces       int64   `parquet:"name=subtraces, type=INT64"`
+	Name            string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// legacyTrace is testTrace as written before trace_address existed
+type legacyTrace struct {
+	BlockHash       string `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	TransactionHash string `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Hash            string `parquet:"name=trace_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ParentHash      string `parquet:"name=parent_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Index           int64  `parquet:"name=trace_index, type=INT64"`
+	Name            string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// legacyRows writes a call tree as the old writer did: breadth first, linked by a hash of each call's content, which
+// identical calls share
+func legacyRows(root *call, index int64, txHash string) []interface{} {
+	type node struct {
+		call       *call
+		index      int64
+		parentHash string
+	}
+
+	var rows []interface{}
+	queue := []node{{call: root, index: index}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		hash := "content:" + current.call.name
+		rows = append(rows, &legacyTrace{
+			BlockHash:       testBlockHash,
+			TransactionHash: txHash,
+			Hash:            hash,
+			ParentHash:      current.parentHash,
+			Index:           current.index,
+			Name:            current.call.name,
+		})
+		for i, child := range current.call.calls {
+			queue = append(queue, node{call: child, index: int64(i), parentHash: hash})
+		}
+	}
+	return rows
+}
+
+func TestMigrateFile(t *testing.T) {
+	trees := []*call{
+		{name: "root", calls: []*call{
+			{name: "a", calls: []*call{
+				{name: "a0"},
+				{name: "a1", calls: []*call{{name: "a1x"}}},
+			}},
+			{name: "b"},
+		}},
+		//	Identical siblings share a content hash, so their children are matched to them in order
+		{name: "root", calls: []*call{
+			{name: "x", calls: []*call{{name: "x0"}, {name: "x1"}}},
+			{name: "x", calls: []*call{{name: "x2"}}},
+		}},
+		{name: "root"},
+	}
+
+	tests := []struct {
+		name   string
+		legacy bool
+	}{
+		{name: "file without trace_address", legacy: true},
+		{name: "file with content hashes", legacy: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := storage.NewMemoryConnector(10000)
+			filename := "traces/blocks_0-9999/1.parquet"
+
+			//	want maps each frame's transaction and trace address to the row it should migrate to
+			want := make(map[string]testTrace)
+			var rows []interface{}
+			for i, tree := range trees {
+				txHash := fmt.Sprintf("0x%02x", i)
+				for _, frame := range Flatten(tree, int64(i), testBlockHash, txHash) {
+					want[fmt.Sprint(txHash, frame.TraceAddress)] = testTrace{
+						BlockHash:       testBlockHash,
+						TransactionHash: txHash,
+						Hash:            frame.Hash,
+						ParentHash:      frame.ParentHash,
+						Index:           frame.Index,
+						TraceAddress:    frame.TraceAddress,
+						Depth:           frame.Depth,
+						Subtraces:       frame.Subtraces,
+						Name:            frame.Call.name,
+					}
+				}
+
+				if tt.legacy {
+					rows = append(rows, legacyRows(tree, int64(i), txHash)...)
+					continue
+				}
+				//	Rows with trace_address but hashes of the call's content, as written before IDs were derived from
+				//	positions
+				for _, frame := range Flatten(tree, int64(i), testBlockHash, txHash) {
+					rows = append(rows, &testTrace{
+						BlockHash:       testBlockHash,
+						TransactionHash: txHash,
+						Hash:            "content:" + frame.Call.name,
+						Index:           frame.Index,
+						TraceAddress:    frame.TraceAddress,
+						Depth:           frame.Depth,
+						Subtraces:       frame.Subtraces,
+						Name:            frame.Call.name,
+					})
+				}
+			}
+			model := interface{}(new(testTrace))
+			if tt.legacy {
+				model = new(legacyTrace)
+			}
+			if err := store.WriteMany(ctx, rows, model, filename); err != nil {
+				t.Fatal(err)
+			}
+
+			migrator := NewMigrator(store, new(testTrace))
+			rewritten, err := migrator.MigrateFile(ctx, filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !rewritten {
+				t.Fatal("file was not rewritten")
+			}
+
+			var migrated []testTrace
+			if err := store.Decode(filename, new(testTrace), &migrated); err != nil {
+				t.Fatal(err)
+			}
+			if len(migrated) != len(want) {
+				t.Fatalf("got %d rows, want %d", len(migrated), len(want))
+			}
+			for _, row := range migrated {
+				if row.TraceAddress == nil {
+					row.TraceAddress = []int64{}
+				}
+				key := fmt.Sprint(row.TransactionHash, row.TraceAddress)
+				if !reflect.DeepEqual(row, want[key]) {
+					t.Errorf("row %s = %+v, want %+v", key, row, want[key])
+				}
+			}
+
+			//	A second run finds nothing to change
+			if rewritten, err := migrator.MigrateFile(ctx, filename); err != nil || rewritten {
+				t.Errorf("second run rewrote the file: %v, %v", rewritten, err)
+			}
+		})
+	}
+}
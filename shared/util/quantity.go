@@ -0,0 +1,183 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Parquet schema versions, selected per driver by PARQUET_SCHEMA_VERSION
+const (
+	// SchemaVersionHex writes every numeric column as the hex string returned by the node
+	SchemaVersionHex = 1
+	// SchemaVersionTyped writes heights, gas, nonces and indexes as INT64, block time as TIMESTAMP_MILLIS and wei
+	// amounts as DECIMAL
+	SchemaVersionTyped = 2
+)
+
+// Widths of the fixed-length byte arrays backing DECIMAL columns; a DECIMAL(38, 0) fits in 16 bytes and a
+// DECIMAL(76, 0) in 32
+const (
+	Decimal38Length = 16
+	Decimal76Length = 32
+)
+
+var (
+	maxDecimal38 = new(big.Int).Sub(new(big.Int).Exp(big.NewInt(10), big.NewInt(38), nil), big.NewInt(1))
+	maxDecimal76 = new(big.Int).Sub(new(big.Int).Exp(big.NewInt(10), big.NewInt(76), nil), big.NewInt(1))
+)
+
+// ParseQuantity parses a non-negative quantity as returned by a node, which is normally 0x-prefixed hex but is
+// occasionally plain decimal
+func ParseQuantity(s string) (*big.Int, error) {
+	n := new(big.Int)
+	digits, base := s, 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		digits, base = s[2:], 16
+	}
+	//	Nodes return "0x" for some zero-valued fields
+	if digits == "" && base == 16 {
+		return n, nil
+	}
+	if _, ok := n.SetString(digits, base); !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+	return n, nil
+}
+
+// HexToInt64 converts a quantity to an int64, for heights, gas, nonces and indexes
+func HexToInt64(s string) (int64, error) {
+	n, err := ParseQuantity(s)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("quantity %q overflows int64", s)
+	}
+	return n.Int64(), nil
+}
+
+// HexToOptionalInt64 converts a quantity that the node may omit, returning nil for an empty string
+func HexToOptionalInt64(s string) (*int64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	n, err := HexToInt64(s)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// HexToTimestampMillis converts a block time in seconds to milliseconds since the epoch
+func HexToTimestampMillis(s string) (int64, error) {
+	seconds, err := HexToInt64(s)
+	if err != nil {
+		return 0, err
+	}
+	if seconds > math.MaxInt64/1000 {
+		return 0, fmt.Errorf("timestamp %q out of range", s)
+	}
+	return seconds * 1000, nil
+}
+
+// HexToDecimal38 converts a wei amount to the big-endian bytes of a DECIMAL(38, 0)
+func HexToDecimal38(s string) (string, error) {
+	return hexToDecimal(s, Decimal38Length, maxDecimal38)
+}
+
+// HexToOptionalDecimal38 converts a wei amount that the node may omit, returning nil for an empty string
+func HexToOptionalDecimal38(s string) (*string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	d, err := HexToDecimal38(s)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// HexToDecimal76 converts an arbitrary uint256 amount (e.g. a token balance) to the big-endian bytes of a
+// DECIMAL(76, 0); values above 10^76-1 do not fit and are rejected
+func HexToDecimal76(s string) (string, error) {
+	return hexToDecimal(s, Decimal76Length, maxDecimal76)
+}
+
+func hexToDecimal(s string, length int, max *big.Int) (string, error) {
+	n, err := ParseQuantity(s)
+	if err != nil {
+		return "", err
+	}
+	if n.Cmp(max) > 0 {
+		return "", fmt.Errorf("quantity %q overflows DECIMAL(%d, 0)", s, len(max.String()))
+	}
+	return string(n.FillBytes(make([]byte, length))), nil
+}
+
+// DecimalToBig converts the bytes of a DECIMAL column written by HexToDecimal38 or HexToDecimal76 back to an integer
+func DecimalToBig(d string) *big.Int {
+	return new(big.Int).SetBytes([]byte(d))
+}
+
+// QuantityConverter converts the quantities of a single row, remembering the first failure so that a row's fields
+// can be converted in one struct literal and checked once with Err
+type QuantityConverter struct {
+	err error
+}
+
+// Int64 converts a required integer column
+func (c *QuantityConverter) Int64(column string, s string) int64 {
+	n, err := HexToInt64(s)
+	c.fail(column, err)
+	return n
+}
+
+// OptionalInt64 converts an integer column the node may omit
+func (c *QuantityConverter) OptionalInt64(column string, s string) *int64 {
+	n, err := HexToOptionalInt64(s)
+	c.fail(column, err)
+	return n
+}
+
+// TimestampMillis converts a required time column given in seconds
+func (c *QuantityConverter) TimestampMillis(column string, s string) int64 {
+	n, err := HexToTimestampMillis(s)
+	c.fail(column, err)
+	return n
+}
+
+// OptionalTimestampMillis converts a time column the node may omit
+func (c *QuantityConverter) OptionalTimestampMillis(column string, s string) *int64 {
+	if s == "" {
+		return nil
+	}
+	n := c.TimestampMillis(column, s)
+	return &n
+}
+
+// Decimal38 converts a required wei column
+func (c *QuantityConverter) Decimal38(column string, s string) string {
+	d, err := HexToDecimal38(s)
+	c.fail(column, err)
+	return d
+}
+
+// OptionalDecimal38 converts a wei column the node may omit
+func (c *QuantityConverter) OptionalDecimal38(column string, s string) *string {
+	d, err := HexToOptionalDecimal38(s)
+	c.fail(column, err)
+	return d
+}
+
+// Err returns the first conversion failure, naming the column it occurred in
+func (c *QuantityConverter) Err() error {
+	return c.err
+}
+
+func (c *QuantityConverter) fail(column string, err error) {
+	if err != nil && c.err == nil {
+		c.err = fmt.Errorf("%s: %v", column, err)
+	}
+}
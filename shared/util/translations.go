@@ -1,9 +1,15 @@
 package util
 
-import "fmt"
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
 
 const (
-	rangePrefix = "blocks_"
+	rangePrefix   = "blocks_"
+	parquetSuffix = ".parquet"
 )
 
 // RangeName generates a well-formed name for a blocks directory, given a height and a range
@@ -14,6 +20,45 @@ func RangeName(height uint64, directoryRange uint64) string {
 	return fmt.Sprintf("%s%d-%d", rangePrefix, bottom, top)
 }
 
+// ParseRangeName recovers the first and last height of a blocks directory named by RangeName
+func ParseRangeName(name string) (uint64, uint64, error) {
+	if !strings.HasPrefix(name, rangePrefix) {
+		return 0, 0, fmt.Errorf("not a range directory: %s", name)
+	}
+	return parseSpan(strings.TrimPrefix(name, rangePrefix))
+}
+
+// ParseFileHeights recovers the heights covered by a data file, which is named either <height>.parquet for a single
+// block or <first>-<last>.parquet for a range of blocks
+func ParseFileHeights(filename string) (uint64, uint64, error) {
+	base := path.Base(filename)
+	if !strings.HasSuffix(base, parquetSuffix) {
+		return 0, 0, fmt.Errorf("not a parquet file: %s", filename)
+	}
+	return parseSpan(strings.TrimSuffix(base, parquetSuffix))
+}
+
+// parseSpan parses either "<n>" or "<first>-<last>"
+func parseSpan(span string) (uint64, uint64, error) {
+	first, last, isRange := strings.Cut(span, "-")
+	start, err := strconv.ParseUint(first, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid height in %s: %v", span, err)
+	}
+	if !isRange {
+		return start, start, nil
+	}
+
+	end, err := strconv.ParseUint(last, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid height in %s: %v", span, err)
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid span %s", span)
+	}
+	return start, end, nil
+}
+
 func BlockNumberToHex(blockNumber uint64) string {
 	return "0x" + fmt.Sprintf("%x", blockNumber)
 }